@@ -29,6 +29,7 @@ const (
 	ScreenProjectCommandsList
 	ScreenProjectCommandActions
 	ScreenChoicePrompt
+	ScreenWorkspacePicker
 )
 
 // Model is the primary application state shared by all screens.
@@ -64,6 +65,9 @@ type Model struct {
 	// With the advanced recognizer these are grouped (e.g. React frameworks are deduplicated
 	// and multiple CSS frameworks are summarized) before display.
 	RecognizedPkgs []string
+	// Monorepo root when the TUI was started in one; its packages can be picked as the project.
+	WorkspaceRoot  string
+	WorkspaceIndex int
 	TempFilename   string // Used for single-variable input.
 	PendingCommand string // Stores the command that triggered the prompt.
 
@@ -111,6 +115,7 @@ type Model struct {
 	// NEW: State for Native/Clipboard List Previews
 	NativeListPreview    string
 	ClipboardListPreview string
+	NativeShowHidden     bool // Built-in list also shows commands hidden in this project
 
 	// Paginator state
 	ClipboardPaginator       paginator.Model
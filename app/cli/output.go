@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [OUTPUT] Machine-readable output (--output json / --json)
+// -----------------------------------------------------------------------------
+
+// Output formats selected with --output <format>; --json is short for --output json.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+var outputFormat = OutputText
+
+// SetOutputFormat selects how results are reported.
+func SetOutputFormat(format string) { outputFormat = format }
+
+// IsJSONOutput reports whether results are reported as a single JSON document.
+func IsJSONOutput() bool { return outputFormat == OutputJSON }
+
+// OutputFormatFromArgs finds the global output flags in raw arguments and returns the
+// format ("" when none was given) and the arguments without them. --output is only global
+// when its value is a known format; other values are left for the command's own flags.
+func OutputFormatFromArgs(args []string) (string, []string) {
+	isFormat := func(v string) bool { return v == OutputText || v == OutputJSON }
+	format := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			// Everything after `--` is passed through untouched
+			return format, append(rest, args[i:]...)
+		case arg == "--json":
+			format = OutputJSON
+		case arg == "--output" && i+1 < len(args) && isFormat(args[i+1]):
+			format = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--output=") && isFormat(strings.TrimPrefix(arg, "--output=")):
+			format = strings.TrimPrefix(arg, "--output=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return format, rest
+}
+
+var warnings []string
+
+// Warn reports a non-fatal problem. It is printed to stderr, or collected for the JSON
+// result in JSON mode.
+func Warn(format string, a ...any) {
+	msg := fmt.Sprintf(format, a...)
+	if IsJSONOutput() {
+		warnings = append(warnings, msg)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+}
+
+// Warnings returns the warnings collected in JSON mode.
+func Warnings() []string { return append([]string{}, warnings...) }
+
+var resultData any
+
+// SetResultData records command-specific data for the JSON result (e.g. the list printed
+// by list-all). Commands that call it in JSON mode should print nothing themselves.
+func SetResultData(v any) { resultData = v }
+
+// ResultData returns the data recorded with SetResultData.
+func ResultData() any { return resultData }
+
+// Error codes reported in JSON results.
+const (
+	ErrCodeInvalidArguments = "invalid_arguments"
+	ErrCodeUnknownCommand   = "unknown_command"
+	ErrCodeExecutionFailed  = "execution_failed"
+)
+
+// ResolvedInfo describes the command a name resolved to.
+type ResolvedInfo struct {
+	Source      string `json:"source"`
+	Kind        string `json:"kind"`
+	Location    string `json:"location,omitempty"`
+	Description string `json:"description"`
+}
+
+// FileChanges lists the files a command wrote, relative to the project.
+type FileChanges struct {
+	Created []string `json:"created"`
+	Merged  []string `json:"merged"`
+}
+
+// ErrorInfo describes why a command failed.
+type ErrorInfo struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CommandResult is the JSON document printed for an invocation in JSON mode.
+type CommandResult struct {
+	OK         bool              `json:"ok"`
+	Command    string            `json:"command,omitempty"`
+	Resolved   *ResolvedInfo     `json:"resolved,omitempty"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	Files      *FileChanges      `json:"files,omitempty"`
+	Output     string            `json:"output,omitempty"` // text the command printed
+	Data       any               `json:"data,omitempty"`
+	Warnings   []string          `json:"warnings"`
+	DurationMs int64             `json:"durationMs"`
+	Error      *ErrorInfo        `json:"error,omitempty"`
+	ExitCode   int               `json:"exitCode"`
+}
+
+// Fail marks the result as failed with the given code.
+func (r *CommandResult) Fail(code string, err error) {
+	r.OK = false
+	r.Error = &ErrorInfo{Code: code, Message: err.Error()}
+	r.ExitCode = 1
+}
+
+// WriteJSON prints the result, with collected warnings, as one JSON document.
+func (r *CommandResult) WriteJSON(w io.Writer) error {
+	r.Warnings = Warnings()
+	if r.Data == nil {
+		r.Data = ResultData()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// CaptureStdout runs fn and returns what it printed to stdout.
+func CaptureStdout(fn func() error) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", fn()
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		done <- buf.String()
+	}()
+	runErr := fn()
+	os.Stdout = saved
+	w.Close()
+	output := <-done
+	r.Close()
+	return output, runErr
+}
+
+// StdinIsTerminal reports whether standard input is an interactive terminal, so commands
+// may prompt for confirmation.
+func StdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestOutputFormatFromArgs tests that only output format flags are taken from the arguments.
+func TestOutputFormatFromArgs(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format string
+		rest   []string
+	}{
+		{[]string{"list-all", "--json"}, OutputJSON, []string{"list-all"}},
+		{[]string{"--output", "json", "which", "x"}, OutputJSON, []string{"which", "x"}},
+		{[]string{"which", "--output=text"}, OutputText, []string{"which"}},
+		{[]string{"config", "get", "--output", "file.txt"}, "", []string{"config", "get", "--output", "file.txt"}},
+		{[]string{"run", "--output=out.json"}, "", []string{"run", "--output=out.json"}},
+	}
+	for _, tt := range tests {
+		format, rest := OutputFormatFromArgs(tt.args)
+		if format != tt.format || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("OutputFormatFromArgs(%q) = %q, %q; want %q, %q", tt.args, format, rest, tt.format, tt.rest)
+		}
+	}
+}
+
+// TestCommandResultJSON tests that warnings are collected and errors reported in JSON mode.
+func TestCommandResultJSON(t *testing.T) {
+	SetOutputFormat(OutputJSON)
+	defer func() {
+		SetOutputFormat(OutputText)
+		warnings = nil
+		resultData = nil
+	}()
+
+	Warn("skipped %s", "pack")
+	SetResultData(map[string]int{"count": 2})
+	result := &CommandResult{OK: true, Command: "make-note"}
+	result.Fail(ErrCodeInvalidArguments, errors.New("missing Name"))
+
+	var buf bytes.Buffer
+	if err := result.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["ok"] != false || got["exitCode"] != float64(1) {
+		t.Errorf("expected a failed result: %s", buf.String())
+	}
+	if code := got["error"].(map[string]any)["code"]; code != ErrCodeInvalidArguments {
+		t.Errorf("unexpected error code %v", code)
+	}
+	if w := got["warnings"].([]any); len(w) != 1 || w[0] != "skipped pack" {
+		t.Errorf("unexpected warnings %v", w)
+	}
+	if data := got["data"].(map[string]any); data["count"] != float64(2) {
+		t.Errorf("unexpected data %v", data)
+	}
+}
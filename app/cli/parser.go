@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -11,32 +12,64 @@ type CommandRegistryChecker interface {
 	CommandExists(name string) bool
 }
 
+// CommandFlagProvider is optionally implemented by a CommandRegistryChecker to describe the
+// flags a command declares, so the parser knows which flags take a value.
+type CommandFlagProvider interface {
+	CommandFlags(name string) ([]FlagDef, bool)
+}
+
 // ArgDef defines the structure for an expected positional argument.
 type ArgDef struct {
-	Name        string // e.g., "filename", "count"
-	Description string // Help text for the argument
-	Required    bool   // Whether the argument is mandatory
+	Name        string `json:"name"`                  // e.g., "filename", "count"
+	Description string `json:"description,omitempty"` // Help text for the argument
+	Required    bool   `json:"required,omitempty"`    // Whether the argument is mandatory
 }
 
 // FlagDef defines the structure for an expected flag.
 type FlagDef struct {
-	Name        string // Long name (e.g., "output")
-	ShortName   string // Short name (e.g., "o"), empty if none
-	Description string // Help text for the flag
-	HasValue    bool   // Whether the flag expects a value (true for --flag=v, false for --flag)
-	Required    bool   // Whether the flag is mandatory
+	Name        string `json:"name"`                  // Long name (e.g., "output")
+	ShortName   string `json:"short,omitempty"`       // Short name (e.g., "o"), empty if none
+	Description string `json:"description,omitempty"` // Help text for the flag
+	HasValue    bool   `json:"hasValue,omitempty"`    // Whether the flag expects a value (true for --flag=v, false for --flag)
+	Required    bool   `json:"required,omitempty"`    // Whether the flag is mandatory
+	Type        string `json:"type,omitempty"`        // Value type checked by the parser: FlagTypeString (default), FlagTypeInt or FlagTypeFloat
+	Repeatable  bool   `json:"repeatable,omitempty"`  // Whether the flag may be given more than once (values collect in ListFlags)
+}
+
+// Value types of flags.
+const (
+	FlagTypeString = "string"
+	FlagTypeInt    = "int"
+	FlagTypeFloat  = "float"
+)
+
+// GlobalFlags are the flags every command accepts. --output, --json and --workspace are
+// taken out before parsing (see OutputFormatFromArgs and WorkspaceFromArgs).
+var GlobalFlags = []FlagDef{
+	{Name: "help", ShortName: "h", Description: "Show help"},
+	{Name: "version", Description: "Show the CLI version"},
+	{Name: "verbose", Description: "Print informational output"},
+	{Name: "debug", Description: "Print debug output"},
+	{Name: "allow-unresolved", Description: "Render templates with unresolved placeholders"},
+	{Name: "var", Description: "Template variable as Key=Value", HasValue: true, Repeatable: true},
+	{Name: "vars-file", Description: "JSON or YAML file of template variables", HasValue: true},
+	{Name: "install", Description: "Install packages a template needs with the project's package manager"},
 }
 
 // CommandArgs holds structured information parsed from command-line arguments.
 type CommandArgs struct {
-	RawArgs          []string          // Keep the original args for potential re-parsing or complex scenarios
-	CommandName      string            // The command specified (e.g., "add-page", "config set")
-	Variables        []string          // Positional arguments provided after the command name
-	Flags            map[string]string // Flags provided (e.g., --output=./path -> map["output"]="./path")
-	BoolFlags        map[string]bool   // Boolean flags (e.g., --force -> map["force"]=true)
-	HelpRequested    bool              // If a help flag (--help, -h) was detected
-	VersionRequested bool              // If a version flag (--version) was detected
-	Errors           []error           // Any parsing errors encountered
+	RawArgs          []string            // Keep the original args for potential re-parsing or complex scenarios
+	CommandName      string              // The command specified (e.g., "add-page", "config set")
+	Variables        []string            // Positional arguments provided after the command name
+	Flags            map[string]string   // Flags provided (e.g., --output=./path -> map["output"]="./path")
+	BoolFlags        map[string]bool     // Boolean flags (e.g., --force -> map["force"]=true)
+	ListFlags        map[string][]string // Every value of repeatable flags, in order (Flags holds the last)
+	NamedVars        map[string]string   // Template variables from --var Key=Value (repeatable)
+	HelpRequested    bool                // If a help flag (--help, -h) was detected
+	VersionRequested bool                // If a version flag (--version) was detected
+	Output           string              // Output format from --output/--json ("" when not given)
+	Workspace        string              // Monorepo package from --workspace ("" when not given)
+	Errors           []error             // Any parsing errors encountered
 }
 
 // Debug toggle controlled by --debug. Other packages can query this.
@@ -57,22 +90,60 @@ func SetVerboseEnabled(on bool) { verboseEnabled = on }
 // IsVerboseEnabled reports whether verbose mode is currently enabled.
 func IsVerboseEnabled() bool { return verboseEnabled }
 
+// Allow-unresolved toggle controlled by --allow-unresolved. When on, templates with
+// placeholders that cannot be resolved render with a warning instead of failing.
+var allowUnresolvedEnabled bool
+
+// SetAllowUnresolvedEnabled enables or disables rendering with unresolved placeholders.
+func SetAllowUnresolvedEnabled(on bool) { allowUnresolvedEnabled = on }
+
+// IsAllowUnresolvedEnabled reports whether unresolved placeholders are tolerated.
+func IsAllowUnresolvedEnabled() bool { return allowUnresolvedEnabled }
+
+// CLI version, set by main at startup so other packages can check compatibility.
+var cliVersion string
+
+// SetVersion records the running CLI version (e.g. "v1.0.131").
+func SetVersion(v string) { cliVersion = v }
+
+// Version returns the running CLI version, or an empty string if unknown.
+func Version() string { return cliVersion }
+
 // ParseCommandLineArgs processes the raw command-line arguments using a command registry checker.
+//
+// Flags the command declares (when the registry is a CommandFlagProvider) and GlobalFlags
+// are parsed by their definition: boolean flags never take the next argument, value flags
+// always do (so `--offset -1` works), typed values are checked and repeatable flags collect
+// every value. Undeclared flags take the next argument as their value unless it looks like
+// a flag. A `--` argument ends flag parsing; everything after it is a variable.
 func ParseCommandLineArgs(rawArgs []string, registry CommandRegistryChecker) CommandArgs {
 	parsed := CommandArgs{
 		RawArgs:   rawArgs,
 		Variables: make([]string, 0),
 		Flags:     make(map[string]string),
 		BoolFlags: make(map[string]bool),
+		ListFlags: make(map[string][]string),
+		NamedVars: make(map[string]string),
 		Errors:    make([]error, 0),
 	}
 
-	args := make([]string, len(rawArgs)) // Work on a copy
-	copy(args, rawArgs)
+	// Global output flags may appear anywhere and never count as command flags
+	var args []string // Work on a copy
+	parsed.Output, args = OutputFormatFromArgs(rawArgs)
+	parsed.Workspace, args = WorkspaceFromArgs(args)
+
+	// Arguments after `--` are never flags or command words
+	flagArgs, trailing := args, []string(nil)
+	for i, arg := range args {
+		if arg == "--" {
+			flagArgs, trailing = args[:i], args[i+1:]
+			break
+		}
+	}
 
-	// --- Stage 0: Scan *raw* args for global flags first ---
+	// --- Stage 0: Scan args for global flags first ---
 	// This ensures intent is captured regardless of position
-	for _, arg := range rawArgs {
+	for _, arg := range flagArgs {
 		if arg == "--help" || arg == "-h" {
 			parsed.HelpRequested = true
 		} else if arg == "--version" {
@@ -80,63 +151,65 @@ func ParseCommandLineArgs(rawArgs []string, registry CommandRegistryChecker) Com
 		}
 	}
 
-	// --- Stage 1: Find Potential Command Name(s) ---
-	firstPotentialCommandIndex := -1
-	secondPotentialCommandIndex := -1
-	for i, arg := range args { // Use the copy `args` here
-		if !strings.HasPrefix(arg, "-") {
-			if firstPotentialCommandIndex == -1 {
-				firstPotentialCommandIndex = i
-			} else if secondPotentialCommandIndex == -1 {
-				secondPotentialCommandIndex = i
-				break
-			}
+	// --- Stage 1: Find the Command Name ---
+	// Command names may have any number of words ("config set", "Add Pagebuilder Block"),
+	// so the longest run of leading non-flag words that names a command wins.
+	globalDefs := flagDefsByName(nil)
+	var wordIndexes []int
+	for i := 0; i < len(flagArgs); i++ {
+		arg := flagArgs[i]
+		if !isFlagArg(arg) {
+			wordIndexes = append(wordIndexes, i)
+			continue
+		}
+		// Skip the separate value of a global value flag (`--vars-file vars.json`)
+		if def, ok := globalDefs[strings.TrimLeft(arg, "-")]; ok && def.HasValue {
+			i++
 		}
 	}
-
-	// Determine Command Name and remaining args for flag parsing
-	argsToParseFlagsFrom := args
-	if firstPotentialCommandIndex != -1 {
-		// Check if first two non-flag args form a valid multi-word command
-		if secondPotentialCommandIndex != -1 {
-			potentialCommandName := args[firstPotentialCommandIndex] + " " + args[secondPotentialCommandIndex]
-			if registry.CommandExists(potentialCommandName) {
-				parsed.CommandName = potentialCommandName
-				// Rebuild the list of args for flag/variable parsing, excluding the command parts
-				tempArgs := []string{}
-				for i, arg := range args {
-					if i != firstPotentialCommandIndex && i != secondPotentialCommandIndex {
-						tempArgs = append(tempArgs, arg)
-					}
-				}
-				argsToParseFlagsFrom = tempArgs
-			} else {
-				// Check if the first non-flag arg is a valid single-word command
-				if registry.CommandExists(args[firstPotentialCommandIndex]) {
-					parsed.CommandName = args[firstPotentialCommandIndex]
-					argsToParseFlagsFrom = append(args[:firstPotentialCommandIndex], args[firstPotentialCommandIndex+1:]...)
-				} else {
-					// Treat first non-flag arg as a variable if not a command
-					// Keep all args for flag/var parsing, CommandName remains empty
-					argsToParseFlagsFrom = args
-				}
-			}
-		} else {
-			// Only one potential command word found
-			if registry.CommandExists(args[firstPotentialCommandIndex]) {
-				parsed.CommandName = args[firstPotentialCommandIndex]
-				argsToParseFlagsFrom = append(args[:firstPotentialCommandIndex], args[firstPotentialCommandIndex+1:]...)
-			} else {
-				// Treat single non-flag arg as a variable
-				argsToParseFlagsFrom = args
+	argsToParseFlagsFrom := flagArgs
+	for n := len(wordIndexes); n > 0; n-- {
+		words := make([]string, n)
+		for j, idx := range wordIndexes[:n] {
+			words[j] = flagArgs[idx]
+		}
+		if !registry.CommandExists(strings.Join(words, " ")) {
+			continue
+		}
+		parsed.CommandName = strings.Join(words, " ")
+		// Rebuild the list of args for flag/variable parsing, excluding the command words
+		commandWord := make(map[int]bool, n)
+		for _, idx := range wordIndexes[:n] {
+			commandWord[idx] = true
+		}
+		argsToParseFlagsFrom = []string{}
+		for i, arg := range flagArgs {
+			if !commandWord[i] {
+				argsToParseFlagsFrom = append(argsToParseFlagsFrom, arg)
 			}
 		}
-	} else {
-		// No command name found (e.g., only flags like `ng --help`)
-		argsToParseFlagsFrom = args
+		break
 	}
+	// Without a command name every word is treated as a variable
 
 	// --- Stage 2: Parse Flags and Variables from the determined args list ---
+	var commandDefs []FlagDef
+	if provider, ok := registry.(CommandFlagProvider); ok && parsed.CommandName != "" {
+		commandDefs, _ = provider.CommandFlags(parsed.CommandName)
+	}
+	defs := flagDefsByName(commandDefs)
+
+	// takesNext reports whether a flag without an inline value takes the argument at i
+	takesNext := func(def FlagDef, known bool, i int) bool {
+		if i >= len(argsToParseFlagsFrom) {
+			return false
+		}
+		if known {
+			return def.HasValue
+		}
+		return !isFlagArg(argsToParseFlagsFrom[i])
+	}
+
 	for i := 0; i < len(argsToParseFlagsFrom); i++ {
 		arg := argsToParseFlagsFrom[i]
 
@@ -147,34 +220,15 @@ func ParseCommandLineArgs(rawArgs []string, registry CommandRegistryChecker) Com
 
 		// Parse --help / -h like any other flag in this stage
 		if strings.HasPrefix(arg, "--") {
-			flagPart := strings.TrimPrefix(arg, "--")
-			flagName := flagPart
-			flagValue := ""
-			hasExplicitValue := false
-
-			if strings.Contains(flagPart, "=") {
-				parts := strings.SplitN(flagPart, "=", 2)
-				flagName = parts[0]
-				flagValue = parts[1]
-				hasExplicitValue = true
-			} else if i+1 < len(argsToParseFlagsFrom) && !strings.HasPrefix(argsToParseFlagsFrom[i+1], "-") {
+			flagName, flagValue, hasExplicitValue := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+			def, known := defs[flagName]
+			if !hasExplicitValue && takesNext(def, known, i+1) {
 				flagValue = argsToParseFlagsFrom[i+1]
 				hasExplicitValue = true
 				i++ // Consume the value argument
 			}
-
-			if hasExplicitValue {
-				if _, exists := parsed.Flags[flagName]; exists {
-					parsed.Errors = append(parsed.Errors, fmt.Errorf("flag provided more than once: --%s", flagName))
-				}
-				parsed.Flags[flagName] = flagValue
-			} else {
-				if _, exists := parsed.BoolFlags[flagName]; exists {
-					parsed.Errors = append(parsed.Errors, fmt.Errorf("boolean flag provided more than once: --%s", flagName))
-				}
-				parsed.BoolFlags[flagName] = true
-			}
-		} else if strings.HasPrefix(arg, "-") {
+			parsed.setFlag("--"+flagName, flagName, def, known, flagValue, hasExplicitValue)
+		} else if isFlagArg(arg) {
 			flagChars := strings.TrimPrefix(arg, "-")
 
 			if len(flagChars) == 0 {
@@ -182,36 +236,157 @@ func ParseCommandLineArgs(rawArgs []string, registry CommandRegistryChecker) Com
 				continue
 			}
 
-			potentialValue := ""
-			valueConsumed := false
-			if i+1 < len(argsToParseFlagsFrom) && !strings.HasPrefix(argsToParseFlagsFrom[i+1], "-") {
-				potentialValue = argsToParseFlagsFrom[i+1]
-			}
-
 			for j, flagChar := range flagChars {
 				flagName := string(flagChar)
-
-				if j == len(flagChars)-1 && potentialValue != "" {
-					if _, exists := parsed.Flags[flagName]; exists {
-						parsed.Errors = append(parsed.Errors, fmt.Errorf("flag provided more than once: -%s", flagName))
-					}
-					parsed.Flags[flagName] = potentialValue
-					valueConsumed = true
-				} else {
-					if _, exists := parsed.BoolFlags[flagName]; exists {
-						parsed.Errors = append(parsed.Errors, fmt.Errorf("boolean flag provided more than once: -%s", flagName))
-					}
-					parsed.BoolFlags[flagName] = true
+				def, known := defs[flagName]
+				switch {
+				case known && def.HasValue && j < len(flagChars)-1:
+					// The rest of the group is the value (-n5)
+					parsed.setFlag("-"+flagName, flagName, def, known, flagChars[j+1:], true)
+				case j == len(flagChars)-1 && takesNext(def, known, i+1):
+					parsed.setFlag("-"+flagName, flagName, def, known, argsToParseFlagsFrom[i+1], true)
+					i++ // Consume the value argument
+				default:
+					parsed.setFlag("-"+flagName, flagName, def, known, "", false)
+					continue
 				}
-			}
-
-			if valueConsumed {
-				i++
+				break
 			}
 		} else {
 			parsed.Variables = append(parsed.Variables, arg)
 		}
 	}
+	parsed.Variables = append(parsed.Variables, trailing...)
 
 	return parsed
 }
+
+// setFlag records one flag occurrence. Declared flags are stored under their long name.
+func (parsed *CommandArgs) setFlag(display, name string, def FlagDef, known bool, value string, hasValue bool) {
+	if known {
+		name = def.Name
+	}
+	switch {
+	case name == "var":
+		// --var Key=Value may be repeated, once per variable
+		key, v, ok := strings.Cut(value, "=")
+		if !hasValue || !ok || strings.TrimSpace(key) == "" {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("--var expects Key=Value, got %q", value))
+		} else if _, exists := parsed.NamedVars[key]; exists {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("variable provided more than once: --var %s", key))
+		} else {
+			parsed.NamedVars[key] = v
+		}
+
+	case known && !def.HasValue:
+		on := true
+		if hasValue {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				parsed.Errors = append(parsed.Errors, fmt.Errorf("boolean flag %s expects true or false, got %q", display, value))
+				return
+			}
+			on = b
+		}
+		if _, exists := parsed.BoolFlags[name]; exists && !def.Repeatable {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("boolean flag provided more than once: %s", display))
+		}
+		parsed.BoolFlags[name] = on
+
+	case known:
+		if !hasValue {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("flag %s requires a value", display))
+			return
+		}
+		if err := checkFlagType(def, value); err != nil {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("flag %s %w", display, err))
+			return
+		}
+		if _, exists := parsed.Flags[name]; exists && !def.Repeatable {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("flag provided more than once: %s", display))
+		}
+		parsed.Flags[name] = value
+		if def.Repeatable {
+			parsed.ListFlags[name] = append(parsed.ListFlags[name], value)
+		}
+
+	case hasValue:
+		if _, exists := parsed.Flags[name]; exists {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("flag provided more than once: %s", display))
+		}
+		parsed.Flags[name] = value
+
+	default:
+		if _, exists := parsed.BoolFlags[name]; exists {
+			parsed.Errors = append(parsed.Errors, fmt.Errorf("boolean flag provided more than once: %s", display))
+		}
+		parsed.BoolFlags[name] = true
+	}
+}
+
+// flagDefsByName indexes GlobalFlags and a command's flags by long and short name. The
+// command's definitions win.
+func flagDefsByName(commandDefs []FlagDef) map[string]FlagDef {
+	defs := make(map[string]FlagDef)
+	for _, list := range [][]FlagDef{GlobalFlags, commandDefs} {
+		for _, def := range list {
+			defs[def.Name] = def
+			if def.ShortName != "" {
+				defs[def.ShortName] = def
+			}
+		}
+	}
+	return defs
+}
+
+// isFlagArg reports whether arg is a flag. Negative numbers (-1, -0.5) are values.
+func isFlagArg(arg string) bool {
+	if !strings.HasPrefix(arg, "-") || arg == "--" {
+		return false
+	}
+	if len(arg) > 1 && (arg[1] == '.' || (arg[1] >= '0' && arg[1] <= '9')) {
+		if _, err := strconv.ParseFloat(arg, 64); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// checkFlagType checks a flag value against the flag's type.
+func checkFlagType(def FlagDef, value string) error {
+	switch def.Type {
+	case FlagTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expects an integer, got %q", value)
+		}
+	case FlagTypeFloat:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("expects a number, got %q", value)
+		}
+	}
+	return nil
+}
+
+// FlagValues returns every value given for a flag: all values of a repeatable flag, or the
+// single value of any other flag.
+func (a CommandArgs) FlagValues(name string) []string {
+	if values, ok := a.ListFlags[name]; ok {
+		return values
+	}
+	if value, ok := a.Flags[name]; ok {
+		return []string{value}
+	}
+	return nil
+}
+
+// IntFlag returns the value of an integer flag, and whether it was given.
+func (a CommandArgs) IntFlag(name string) (int, bool) {
+	n, err := strconv.Atoi(a.Flags[name])
+	return n, err == nil
+}
+
+// FloatFlag returns the value of a number flag, and whether it was given.
+func (a CommandArgs) FloatFlag(name string) (float64, bool) {
+	f, err := strconv.ParseFloat(a.Flags[name], 64)
+	return f, err == nil
+}
@@ -7,7 +7,8 @@ import (
 
 // MockRegistryChecker provides a mock implementation for testing.
 type MockRegistryChecker struct {
-	KnownCommands map[string]bool
+	KnownCommands   map[string]bool
+	CommandFlagDefs map[string][]FlagDef
 }
 
 // CommandExists checks if a command name exists in the mock registry.
@@ -16,22 +17,39 @@ func (m MockRegistryChecker) CommandExists(name string) bool {
 	return exists
 }
 
+// CommandFlags returns the flags declared for a command in the mock registry.
+func (m MockRegistryChecker) CommandFlags(name string) ([]FlagDef, bool) {
+	defs, ok := m.CommandFlagDefs[name]
+	return defs, ok
+}
+
 // TestParseCommandLineArgs tests the argument parser.
 func TestParseCommandLineArgs(t *testing.T) {
 	// Setup a mock registry for testing command existence
 	mockRegistry := MockRegistryChecker{
 		KnownCommands: map[string]bool{
-			"hello":           true,
-			"commands":        true,
-			"list-all":        true,
-			"stats":           true,
-			"config set":      true, // Multi-word
-			"config get":      true,
-			"config list":     true,
-			"clipboard-paste": true,
-			"native-cmd add":  true,
+			"hello":                             true,
+			"commands":                          true,
+			"list-all":                          true,
+			"stats":                             true,
+			"config set":                        true, // Multi-word
+			"config get":                        true,
+			"config list":                       true,
+			"clipboard-paste":                   true,
+			"native-cmd add":                    true,
+			"add pagebuilder block":             true, // Three words
+			"add page type with index and slug": true,
+			"apply":                             true,
 			// Add other multi-word commands as needed
 		},
+		CommandFlagDefs: map[string][]FlagDef{
+			"apply": {{Name: "dry-run"}},
+			"hello": {
+				{Name: "offset", ShortName: "n", HasValue: true, Type: FlagTypeInt},
+				{Name: "tag", ShortName: "t", HasValue: true, Repeatable: true},
+				{Name: "loud", ShortName: "l"},
+			},
+		},
 	}
 
 	testCases := []struct {
@@ -174,6 +192,165 @@ func TestParseCommandLineArgs(t *testing.T) {
 				Errors:      []error{},
 			},
 		},
+		{
+			name: "Named Variables",
+			args: []string{"hello", "--var", "Name=Foo", "--var=Title=A=B", "Bar"},
+			expected: CommandArgs{
+				RawArgs:     []string{"hello", "--var", "Name=Foo", "--var=Title=A=B", "Bar"},
+				CommandName: "hello",
+				Variables:   []string{"Bar"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{},
+				NamedVars:   map[string]string{"Name": "Foo", "Title": "A=B"},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Named Variable Without Value",
+			args: []string{"hello", "--var", "Name"},
+			expected: CommandArgs{
+				RawArgs:     []string{"hello", "--var", "Name"},
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{nil},
+			},
+		},
+		{
+			name: "Three Word Command",
+			args: []string{"add", "pagebuilder", "block", "Hero", "--force"},
+			expected: CommandArgs{
+				RawArgs:     []string{"add", "pagebuilder", "block", "Hero", "--force"},
+				CommandName: "add pagebuilder block",
+				Variables:   []string{"Hero"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{"force": true},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Six Word Command",
+			args: []string{"add", "page", "type", "with", "index", "and", "slug", "Article"},
+			expected: CommandArgs{
+				CommandName: "add page type with index and slug",
+				Variables:   []string{"Article"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Double Dash Ends Flags",
+			args: []string{"hello", "--loud", "--", "--not-a-flag", "-x", "--help"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{"--not-a-flag", "-x", "--help"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{"loud": true},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Negative Numbers Are Variables",
+			args: []string{"hello", "-1", "-0.5", "-l"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{"-1", "-0.5"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{"loud": true},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Declared Boolean Flag Takes No Value",
+			args: []string{"apply", "--dry-run", "manifest.yaml"},
+			expected: CommandArgs{
+				CommandName: "apply",
+				Variables:   []string{"manifest.yaml"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{"dry-run": true},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Explicit Boolean Value",
+			args: []string{"apply", "manifest.yaml", "--dry-run=false"},
+			expected: CommandArgs{
+				CommandName: "apply",
+				Variables:   []string{"manifest.yaml"},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{"dry-run": false},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Declared Value Flag Takes Negative Value",
+			args: []string{"hello", "--offset", "-5", "-n3"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{"offset": "3"},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{nil}, // given twice
+			},
+		},
+		{
+			name: "Short Declared Value Flag",
+			args: []string{"hello", "-ln", "-5", "World"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{"World"},
+				Flags:       map[string]string{"offset": "-5"},
+				BoolFlags:   map[string]bool{"loud": true},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Typed Flag Rejects Bad Value",
+			args: []string{"hello", "--offset", "many"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{nil},
+			},
+		},
+		{
+			name: "Missing Flag Value",
+			args: []string{"hello", "--offset"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{nil},
+			},
+		},
+		{
+			name: "Repeatable Flag",
+			args: []string{"hello", "--tag", "a", "-t", "b", "--tag=c"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{"tag": "c"},
+				BoolFlags:   map[string]bool{},
+				ListFlags:   map[string][]string{"tag": {"a", "b", "c"}},
+				Errors:      []error{},
+			},
+		},
+		{
+			name: "Global Value Flag Before Command",
+			args: []string{"--vars-file", "vars.json", "hello"},
+			expected: CommandArgs{
+				CommandName: "hello",
+				Variables:   []string{},
+				Flags:       map[string]string{"vars-file": "vars.json"},
+				BoolFlags:   map[string]bool{},
+				Errors:      []error{},
+			},
+		},
 		// Add more cases: invalid flags, duplicate flags, edge cases
 	}
 
@@ -195,6 +372,12 @@ func TestParseCommandLineArgs(t *testing.T) {
 			if !reflect.DeepEqual(actual.BoolFlags, tc.expected.BoolFlags) {
 				t.Errorf("BoolFlags mismatch: expected %v, got %v", tc.expected.BoolFlags, actual.BoolFlags)
 			}
+			if tc.expected.ListFlags != nil && !reflect.DeepEqual(actual.ListFlags, tc.expected.ListFlags) {
+				t.Errorf("ListFlags mismatch: expected %v, got %v", tc.expected.ListFlags, actual.ListFlags)
+			}
+			if tc.expected.NamedVars != nil && !reflect.DeepEqual(actual.NamedVars, tc.expected.NamedVars) {
+				t.Errorf("NamedVars mismatch: expected %v, got %v", tc.expected.NamedVars, actual.NamedVars)
+			}
 			if actual.HelpRequested != tc.expected.HelpRequested {
 				t.Errorf("HelpRequested mismatch: expected %t, got %t", tc.expected.HelpRequested, actual.HelpRequested)
 			}
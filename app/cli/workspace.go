@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [WORKSPACE] Monorepo package selection (--workspace <name>)
+// -----------------------------------------------------------------------------
+
+// Workspace selected with --workspace, set by main before commands run in its directory.
+var workspace string
+
+// SetWorkspace records the selected workspace.
+func SetWorkspace(name string) { workspace = name }
+
+// Workspace returns the workspace selected with --workspace, or "".
+func Workspace() string { return workspace }
+
+// startDir is the directory ng was started in, recorded before --workspace changes into
+// the package.
+var startDir string
+
+// SetStartDir records the directory ng was started in.
+func SetStartDir(dir string) { startDir = dir }
+
+// UserPath resolves a path given on the command line. Relative paths are relative to the
+// directory ng was started in, not the package --workspace changed into.
+func UserPath(path string) string {
+	if path == "" || startDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(startDir, path)
+}
+
+// WorkspaceFromArgs finds the global --workspace flag (--workspace <name> or
+// --workspace=<name>) in raw arguments and returns its value ("" when not given) and the
+// arguments without it. Arguments after `--` are left untouched.
+func WorkspaceFromArgs(args []string) (string, []string) {
+	name := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return name, append(rest, args[i:]...)
+		case arg == "--workspace" && i+1 < len(args):
+			name = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--workspace="):
+			name = strings.TrimPrefix(arg, "--workspace=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return name, rest
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestWorkspaceFromArgs tests that --workspace is taken from the arguments before `--`.
+func TestWorkspaceFromArgs(t *testing.T) {
+	tests := []struct {
+		args      []string
+		workspace string
+		rest      []string
+	}{
+		{[]string{"--workspace", "web", "add-page", "About"}, "web", []string{"add-page", "About"}},
+		{[]string{"add-page", "--workspace=apps/studio"}, "apps/studio", []string{"add-page"}},
+		{[]string{"add-page", "--", "--workspace", "web"}, "", []string{"add-page", "--", "--workspace", "web"}},
+		{[]string{"list-all", "--workspace"}, "", []string{"list-all", "--workspace"}},
+	}
+	for _, tt := range tests {
+		workspace, rest := WorkspaceFromArgs(tt.args)
+		if workspace != tt.workspace || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("WorkspaceFromArgs(%q) = %q, %q; want %q, %q", tt.args, workspace, rest, tt.workspace, tt.rest)
+		}
+	}
+}
+
+// TestUserPath tests that relative command-line paths resolve against the starting
+// directory once --workspace has changed into a package.
+func TestUserPath(t *testing.T) {
+	defer SetStartDir("")
+	if got := UserPath("blueprint.yaml"); got != "blueprint.yaml" {
+		t.Errorf("without a workspace the path is used as given, got %q", got)
+	}
+	start := filepath.Join(string(filepath.Separator), "repo")
+	SetStartDir(start)
+	abs := filepath.Join(start, "other", "vars.json")
+	for in, want := range map[string]string{
+		"./blueprint.yaml": filepath.Join(start, "blueprint.yaml"),
+		"../vars.json":     filepath.Join(string(filepath.Separator), "vars.json"),
+		abs:                abs,
+		"":                 "",
+	} {
+		if got := UserPath(in); got != want {
+			t.Errorf("UserPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
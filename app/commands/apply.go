@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [APPLY] Batch template runs from a manifest (`ng apply`)
+// -----------------------------------------------------------------------------
+
+// Manifest lists template commands to run together, e.g.
+//
+//	vars:
+//	  Author: Web team
+//	commands:
+//	  - command: add-page-type
+//	    vars: {Name: Article}
+//	  - command: add-block
+//	    args: [Hero]
+//
+// Manifest vars are offered to every command; a command's own vars and args win.
+type Manifest struct {
+	Vars     map[string]any `json:"vars" yaml:"vars"`
+	Commands []ManifestStep `json:"commands" yaml:"commands"`
+}
+
+// ManifestStep is one command of a manifest.
+type ManifestStep struct {
+	Command string         `json:"command" yaml:"command"`
+	Vars    map[string]any `json:"vars" yaml:"vars"`
+	Args    []string       `json:"args" yaml:"args"` // positional variables
+}
+
+// ApplyStepResult describes one command of an applied manifest.
+type ApplyStepResult struct {
+	Command   string            `json:"command"`
+	Source    string            `json:"source"`
+	Variables map[string]string `json:"variables"`
+	Files     []string          `json:"files"` // files the template writes, relative to the project
+}
+
+// ApplyResult is the outcome of applying a manifest.
+type ApplyResult struct {
+	DryRun  bool              `json:"dryRun"`
+	Steps   []ApplyStepResult `json:"steps"`
+	Created []string          `json:"created"`
+	Updated []string          `json:"updated"`
+	Diff    string            `json:"diff"`
+	Output  string            `json:"output,omitempty"` // text printed by the templates
+	// Packages the templates declare that package.json lacks, and whether they were installed
+	MissingDependencies []TemplateDependency `json:"missingDependencies,omitempty"`
+	Installed           bool                 `json:"installed,omitempty"`
+}
+
+// LoadManifest reads a manifest from a YAML or JSON file (chosen by extension; other
+// extensions are tried as YAML, which also accepts JSON).
+func LoadManifest(path string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, &m)
+	} else {
+		err = yaml.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return m, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if len(m.Commands) == 0 {
+		return m, fmt.Errorf("manifest %s lists no commands", path)
+	}
+	return m, nil
+}
+
+// preparedStep is a manifest step with its template and placeholders resolved.
+type preparedStep struct {
+	resolved     ResolvedCommand
+	template     []byte // with includes and extends resolved against the project
+	placeholders map[string]string
+	result       ApplyStepResult
+}
+
+// ApplyManifest runs the manifest's commands in order as one transaction: every command and
+// its variables are resolved before anything is written, and if a command fails all files
+// written so far are restored. A dry run writes nothing to the project: the commands run
+// against a scratch copy of the files they plan to write, so its plan and diff match what
+// a real run would do. Real runs are recorded as one history entry named historyName.
+func ApplyManifest(projectPath string, registry *project.ProjectRegistry, m Manifest, historyName string, dryRun bool) (ApplyResult, error) {
+	result := ApplyResult{DryRun: dryRun, Created: []string{}, Updated: []string{}}
+	shared, err := stringifyVars(m.Vars, "manifest vars")
+	if err != nil {
+		return result, err
+	}
+
+	// 1. Resolve every command and its variables first
+	resolver := NewResolver(projectPath, registry)
+	var steps []preparedStep
+	for i, step := range m.Commands {
+		label := fmt.Sprintf("command %d (%s)", i+1, step.Command)
+		resolved, err := resolver.Resolve(step.Command)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", label, err)
+		}
+		if resolved.Kind != KindTemplate || !IsFileTemplate(resolved.Template) {
+			return result, fmt.Errorf("%s: only file templates can be applied, not %s commands", label, resolved.Kind)
+		}
+		own, err := stringifyVars(step.Vars, label)
+		if err != nil {
+			return result, err
+		}
+		values, err := ResolveTemplateVariables(TemplateVariables(resolved.Template, projectPath), VariableInput{
+			Positional: step.Args,
+			Named:      own,
+			Shared:     shared,
+			Environ:    os.Environ(),
+		})
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", label, err)
+		}
+		placeholders := BuildPlaceholders(values)
+		tmpl, err := ResolveTemplateIncludes(resolved.Template, projectPath)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", label, err)
+		}
+		files, err := PlannedTemplateFiles(resolved.Template, placeholders, projectPath)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", label, err)
+		}
+		for j := range files {
+			files[j] = filepath.ToSlash(files[j])
+		}
+		result.MissingDependencies = MergeDependencies(result.MissingDependencies, MissingDependencies(resolved.Template, projectPath))
+		steps = append(steps, preparedStep{
+			resolved:     resolved,
+			template:     tmpl,
+			placeholders: placeholders,
+			result:       ApplyStepResult{Command: step.Command, Source: resolved.Describe(), Variables: values, Files: files},
+		})
+	}
+
+	// 2. Run them, tracking every file before it is written. A dry run writes to a scratch
+	// directory holding copies of the planned files instead.
+	target := projectPath
+	if dryRun {
+		scratch, err := os.MkdirTemp("", "ng-apply-")
+		if err != nil {
+			return result, fmt.Errorf("failed to create a scratch directory for the dry run: %w", err)
+		}
+		defer os.RemoveAll(scratch)
+		target = scratch
+	}
+	tx := NewFileTransaction(target)
+	CreatedFiles = []string{}
+	EditedIndexers = make(map[string]bool)
+	output, runErr := cli.CaptureStdout(func() error {
+		for i, step := range steps {
+			if dryRun {
+				if err := copyPlannedFiles(projectPath, target, step.result.Files); err != nil {
+					return err
+				}
+			}
+			if err := tx.Track(step.result.Files); err != nil {
+				return err
+			}
+			if err := ExecuteJSONTemplateFromMemory(step.template, target, step.placeholders); err != nil {
+				return fmt.Errorf("command %d (%s) failed: %w", i+1, step.result.Command, err)
+			}
+		}
+		return nil
+	})
+	result.Output = strings.ReplaceAll(output, target, projectPath)
+	if runErr != nil && dryRun {
+		return result, fmt.Errorf("%w; nothing was written", runErr)
+	}
+	if runErr != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return result, errors.Join(runErr, fmt.Errorf("rolling back failed: %w", rbErr))
+		}
+		return result, fmt.Errorf("%w; no changes were kept", runErr)
+	}
+	for _, step := range steps {
+		result.Steps = append(result.Steps, step.result)
+	}
+	created, updated := tx.Changed()
+	result.Created = append(result.Created, created...)
+	result.Updated = append(result.Updated, updated...)
+	result.Diff = tx.Diff()
+	if dryRun {
+		return result, nil
+	}
+
+	// 3. One history entry for the whole manifest
+	if registry != nil {
+		variables := map[string]string{}
+		for i, step := range result.Steps {
+			for k, v := range step.Variables {
+				variables[fmt.Sprintf("%d.%s", i+1, k)] = v
+			}
+		}
+		entry := project.HistoricCommand{
+			Name:           historyName,
+			Variables:      variables,
+			Timestamp:      time.Now().Unix(),
+			GeneratedFiles: append([]string{}, CreatedFiles...),
+		}
+		if err := registry.RecordCommandHistory(projectPath, entry); err != nil {
+			cli.Warn("Failed to record command history for '%s': %v", historyName, err)
+		}
+	}
+	return result, nil
+}
+
+// copyPlannedFiles copies the files of relPaths that exist in projectPath into scratch,
+// unless an earlier command already put them there. Files standing where a planned file
+// needs a directory are copied too, so the dry run fails where a real run would.
+func copyPlannedFiles(projectPath, scratch string, relPaths []string) error {
+	var copies []string
+	for _, rel := range relPaths {
+		for dir := filepath.Dir(rel); dir != "." && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			if info, err := os.Stat(filepath.Join(projectPath, dir)); err == nil && !info.IsDir() {
+				copies = append(copies, dir)
+			}
+		}
+		copies = append(copies, rel)
+	}
+	for _, rel := range copies {
+		dest := filepath.Join(scratch, rel)
+		if _, err := os.Lstat(dest); err == nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectPath, rel))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
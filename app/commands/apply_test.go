@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestApplyManifest tests dry runs, real runs and rollback when a command fails.
+func TestApplyManifest(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(LocalCommandsDir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	note := `{"title": "make note", "filePaths": [{"path": "notes", "nodes": [{"name": "{{.KebabName}}.md", "code": "# {{.Name}} by {{.Author}}\n"}]}]}`
+	if err := os.WriteFile(filepath.Join(LocalCommandsDir(projectPath), "make-note.json"), []byte(note), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(projectPath, "blueprint.yaml")
+	manifest := "vars:\n  Author: Team\ncommands:\n  - command: make-note\n    vars: {Name: Shopping List}\n  - command: make-note\n    args: [Ideas]\n"
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dry, err := ApplyManifest(projectPath, nil, m, "apply blueprint.yaml", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Created) != 2 || !strings.Contains(dry.Diff, "+# Ideas by Team") {
+		t.Errorf("unexpected dry run: %+v", dry)
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "notes")); !os.IsNotExist(err) {
+		t.Fatal("dry run left files behind")
+	}
+
+	if _, err := ApplyManifest(projectPath, nil, m, "apply blueprint.yaml", false); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(projectPath, "notes", "shopping-list.md")); err != nil || string(data) != "# Shopping List by Team\n" {
+		t.Errorf("unexpected note: %q %v", data, err)
+	}
+
+	// The second command needs notes/ideas.md to be a directory, so it fails after the
+	// first one wrote a file; nothing may be kept
+	clash := `{"title": "clash", "filePaths": [{"path": "notes/ideas.md", "nodes": [{"name": "x.md", "code": "x"}]}]}`
+	if err := os.WriteFile(filepath.Join(LocalCommandsDir(projectPath), "clash.json"), []byte(clash), 0644); err != nil {
+		t.Fatal(err)
+	}
+	failing := Manifest{Vars: map[string]any{"Author": "Team"}, Commands: []ManifestStep{
+		{Command: "make-note", Args: []string{"Later"}},
+		{Command: "clash"},
+	}}
+	if _, err := ApplyManifest(projectPath, nil, failing, "apply failing", false); err == nil {
+		t.Fatal("expected the clashing command to fail")
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "notes", "later.md")); !os.IsNotExist(err) {
+		t.Error("files of earlier commands were kept after a failure")
+	}
+	if _, err := os.Stat(filepath.Join(projectPath, "notes", "ideas.md")); err != nil {
+		t.Error("rollback removed a file that existed before the manifest ran")
+	}
+}
+
+// TestApplyDryRunWritesNothing tests that a dry run leaves existing files untouched, down to
+// their modification time, while its diff shows the change and its failures match real runs.
+func TestApplyDryRunWritesNothing(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(LocalCommandsDir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	note := `{"title": "make note", "filePaths": [{"path": "notes", "nodes": [{"name": "{{.KebabName}}.md", "code": "# {{.Name}}\n"}]}]}`
+	if err := os.WriteFile(filepath.Join(LocalCommandsDir(projectPath), "make-note.json"), []byte(note), 0644); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(projectPath, "notes", "ideas.md")
+	if err := os.MkdirAll(filepath.Dir(existing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(existing, []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(existing, past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	m := Manifest{Commands: []ManifestStep{{Command: "make-note", Args: []string{"Ideas"}}}}
+	dry, err := ApplyManifest(projectPath, nil, m, "apply", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Updated) != 1 || !strings.Contains(dry.Diff, "-old") || !strings.Contains(dry.Diff, "+# Ideas") {
+		t.Errorf("unexpected dry run: %+v", dry)
+	}
+	info, err := os.Stat(existing)
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("dry run touched %s: %v %v", existing, info.ModTime(), err)
+	}
+	if data, _ := os.ReadFile(existing); string(data) != "old\n" {
+		t.Errorf("dry run changed %s: %q", existing, data)
+	}
+
+	// notes/ideas.md is a file, so a note inside it fails in a dry run as in a real one
+	nested := `{"title": "nested", "filePaths": [{"path": "notes/ideas.md", "nodes": [{"name": "x.md", "code": "x"}]}]}`
+	if err := os.WriteFile(filepath.Join(LocalCommandsDir(projectPath), "nested.json"), []byte(nested), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ApplyManifest(projectPath, nil, Manifest{Commands: []ManifestStep{{Command: "nested"}}}, "apply", true); err == nil {
+		t.Error("expected the dry run to fail like a real run")
+	}
+}
@@ -0,0 +1,121 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+)
+
+// ApplyCommand runs the template commands listed in a manifest as one transaction.
+type ApplyCommand struct{}
+
+func init() {
+	RegisterCommand(&ApplyCommand{})
+}
+
+func (c *ApplyCommand) Name() string { return "apply" }
+
+func (c *ApplyCommand) Description() string {
+	return "Runs the templates listed in a manifest (YAML or JSON) in order, keeping all changes or none."
+}
+
+func (c *ApplyCommand) Usage() string { return "<manifest> [--dry-run] [--install]" }
+
+func (c *ApplyCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "manifest", Description: "Manifest file listing commands and their variables", Required: true}}
+}
+
+func (c *ApplyCommand) ExpectedFlags() []FlagDef {
+	return []FlagDef{
+		{Name: "dry-run", Description: "Show the combined plan and diff without keeping any changes"},
+		{Name: "install", Description: "After applying, install packages the templates need that package.json lacks"},
+	}
+}
+
+func (c *ApplyCommand) Execute(args cli.CommandArgs) error {
+	manifestPath := args.Variables[0]
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+	manifest, err := commands_pkg.LoadManifest(cli.UserPath(manifestPath))
+	if err != nil {
+		return err
+	}
+	dryRun := args.BoolFlags["dry-run"]
+	result, err := commands_pkg.ApplyManifest(projectPath, registry, manifest, "apply "+filepath.ToSlash(manifestPath), dryRun)
+	if err != nil {
+		return err
+	}
+	if !dryRun {
+		// Installing is the post-step of a kept run
+		result.Installed, err = commands_pkg.DependencyPostStep(projectPath, result.MissingDependencies, args.BoolFlags["install"])
+		if err != nil {
+			return err
+		}
+	}
+	if cli.IsJSONOutput() {
+		cli.SetResultData(result)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("Dry run of %s (%d commands); nothing was written.\n", manifestPath, len(result.Steps))
+	} else {
+		fmt.Printf("Applied %s (%d commands).\n", manifestPath, len(result.Steps))
+	}
+	for i, step := range result.Steps {
+		fmt.Printf("\n%d. %s\n", i+1, step.Command)
+		keys := make([]string, 0, len(step.Variables))
+		for k := range step.Variables {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("   %s = %s\n", k, step.Variables[k])
+		}
+		for _, f := range step.Files {
+			fmt.Printf("   → %s\n", f)
+		}
+	}
+	fmt.Printf("\nCreated: %s\n", listOrNone(result.Created))
+	fmt.Printf("Updated: %s\n", listOrNone(result.Updated))
+	if len(result.MissingDependencies) > 0 {
+		specs := make([]string, len(result.MissingDependencies))
+		for i, d := range result.MissingDependencies {
+			specs[i] = d.Spec()
+		}
+		status := "missing"
+		if result.Installed {
+			status = "installed"
+		}
+		fmt.Printf("Dependencies (%s): %s\n", status, strings.Join(specs, ", "))
+		if dryRun {
+			fmt.Printf("  install with: %s (or rerun with --install)\n", commands_pkg.InstallHint(projectPath, result.MissingDependencies))
+		}
+	}
+	if dryRun && result.Diff != "" {
+		fmt.Println()
+		fmt.Print(result.Diff)
+	}
+	return nil
+}
+
+// listOrNone joins paths for display.
+func listOrNone(paths []string) string {
+	if len(paths) == 0 {
+		return "(none)"
+	}
+	return strings.Join(paths, ", ")
+}
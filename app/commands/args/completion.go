@@ -0,0 +1,313 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+)
+
+// -----------------------------------------------------------------------------
+// [COMPLETION] Shell completion scripts and the hidden __complete entry point
+// -----------------------------------------------------------------------------
+
+// CompleteCommandName is the hidden entry point the completion scripts call:
+//
+//	ng __complete --cur=<word being completed> <words before it...>
+//
+// It prints one candidate per line. The current word is passed as a flag so that an empty
+// word survives shells that drop empty arguments.
+const CompleteCommandName = "__complete"
+
+const completeCurrentPrefix = "--cur="
+
+// Shells with a completion script.
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// globalCompletionFlags are offered for every command.
+var globalCompletionFlags = []string{"--help", "--json", "--output", "--allow-unresolved", "--verbose", "--debug", "--workspace"}
+
+// templateCompletionFlags are offered for template commands.
+var templateCompletionFlags = []string{"--var", "--vars-file", "--install"}
+
+// Complete returns the completion candidates for the arguments given to __complete.
+func Complete(args []string, projectPath string, registry *project.ProjectRegistry) []string {
+	cur := ""
+	if len(args) > 0 && strings.HasPrefix(args[0], completeCurrentPrefix) {
+		cur = strings.TrimPrefix(args[0], completeCurrentPrefix)
+		args = args[1:]
+	}
+	var out []string
+	for _, c := range completionCandidates(args, cur, projectPath, registry) {
+		if strings.HasPrefix(c, cur) && !strings.ContainsAny(c, " \t\n") {
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return slices.Compact(out)
+}
+
+// completionCandidates returns the unfiltered candidates for the word after words.
+func completionCandidates(words []string, cur, projectPath string, registry *project.ProjectRegistry) []string {
+	// Positional words, skipping the values of global flags that take one
+	var positional []string
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if strings.HasPrefix(w, "-") {
+			if w == "--workspace" && i+1 < len(words) {
+				// Complete against the chosen package, as running the command would
+				if ws, err := project.ResolveWorkspace(projectPath, words[i+1]); err == nil {
+					projectPath = ws.Path
+				}
+			}
+			if (w == "--output" || w == "--var" || w == "--vars-file" || w == "--workspace") && i+1 < len(words) {
+				i++
+			}
+			continue
+		}
+		positional = append(positional, w)
+	}
+
+	argsCmd, cmdWords := matchArgsCommand(positional)
+	var template []byte
+	if argsCmd == nil && len(positional) > 0 {
+		if resolved, err := commands_pkg.NewResolver(projectPath, registry).Resolve(positional[0]); err == nil {
+			template, cmdWords = resolved.Template, 1
+		}
+	}
+
+	prev := ""
+	if len(words) > 0 {
+		prev = words[len(words)-1]
+	}
+	switch prev {
+	case "--output":
+		return []string{cli.OutputText, cli.OutputJSON}
+	case "--vars-file":
+		return nil // file names
+	case "--workspace":
+		var names []string
+		if mono, ok := project.DetectMonorepo(projectPath); ok {
+			for _, ws := range mono.Workspaces {
+				names = append(names, ws.Name)
+			}
+		}
+		return names
+	case "--var":
+		return variableAssignments(template, cur, projectPath)
+	}
+	if argsCmd != nil && strings.HasPrefix(prev, "--") {
+		for _, f := range argsCmd.ExpectedFlags() {
+			if prev == "--"+f.Name && f.HasValue {
+				return nil
+			}
+		}
+	}
+
+	if strings.HasPrefix(cur, "-") {
+		flags := append([]string{}, globalCompletionFlags...)
+		if argsCmd != nil {
+			for _, f := range argsCmd.ExpectedFlags() {
+				flags = append(flags, "--"+f.Name)
+			}
+		}
+		if template != nil {
+			flags = append(flags, templateCompletionFlags...)
+		}
+		return flags
+	}
+
+	// Words that continue multi-word args commands (e.g. "docs" → "generate")
+	candidates := nextCommandWords(positional)
+	switch {
+	case len(positional) == 0:
+		candidates = append(candidates, completionCommandNames(projectPath, registry)...)
+	case argsCmd != nil:
+		index := len(positional) - cmdWords
+		expected := argsCmd.ExpectedArgs()
+		if len(expected) > 0 && strings.HasPrefix(expected[min(index, len(expected)-1)].Name, "command") {
+			candidates = append(candidates, completionCommandNames(projectPath, registry)...)
+		}
+	case template != nil:
+		vars := commands_pkg.TemplateVariables(template, projectPath)
+		if index := len(positional) - cmdWords; index < len(vars) {
+			candidates = append(candidates, vars[index].Choices...)
+		}
+	}
+	return candidates
+}
+
+// matchArgsCommand returns the args command named by the longest run of leading words.
+func matchArgsCommand(positional []string) (Command, int) {
+	for n := len(positional); n > 0; n-- {
+		if cmd, ok := GetCommand(strings.Join(positional[:n], " ")); ok {
+			return cmd, n
+		}
+	}
+	return nil, 0
+}
+
+// nextCommandWords returns the words that extend positional to a longer args command name.
+func nextCommandWords(positional []string) []string {
+	var out []string
+	for _, cmd := range GetAllCommands() {
+		parts := strings.Fields(cmd.Name())
+		if len(parts) > len(positional) && slices.Equal(parts[:len(positional)], positional) {
+			out = append(out, parts[len(positional)])
+		}
+	}
+	return out
+}
+
+// completionCommandNames returns the single-word names a command can be run by: slugs of
+// visible built-in templates, project and saved commands, and the first word of args
+// commands.
+func completionCommandNames(projectPath string, registry *project.ProjectRegistry) []string {
+	var names []string
+	for _, spec := range commands_pkg.RegisteredCommands() {
+		if spec.Slug != "" && commands_pkg.IsCommandVisible(spec, projectPath) {
+			names = append(names, spec.Slug)
+		}
+	}
+	for _, cmd := range GetAllCommands() {
+		names = append(names, strings.Fields(cmd.Name())[0])
+	}
+	for _, name := range BuildAllAvailableCommandNames(projectPath, registry) {
+		if !strings.Contains(name, " ") {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// variableAssignments completes the Key=Value argument of --var for a template: variable
+// names, then the choices of a select variable.
+func variableAssignments(template []byte, cur, projectPath string) []string {
+	if template == nil {
+		return nil
+	}
+	var out []string
+	for _, v := range commands_pkg.TemplateVariables(template, projectPath) {
+		if key, _, typed := strings.Cut(cur, "="); typed && strings.EqualFold(key, v.Name) {
+			for _, c := range v.Choices {
+				out = append(out, key+"="+c)
+			}
+			continue
+		}
+		out = append(out, v.Name+"=")
+	}
+	return out
+}
+
+// CompletionScript returns the completion script for a shell, registered for both CLI names.
+func CompletionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion, nil
+	case "zsh":
+		return zshCompletion, nil
+	case "fish":
+		return fishCompletion, nil
+	case "powershell", "pwsh":
+		return powershellCompletion, nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (supported: %s)", shell, strings.Join(completionShells, ", "))
+}
+
+const bashCompletion = `# bash completion for ng and nextgen. Load with:
+#   source <(ng completion bash)
+_ng_complete() {
+    local IFS=$'\n'
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local candidates
+    candidates=$("${COMP_WORDS[0]}" __complete "--cur=$cur" "${COMP_WORDS[@]:1:COMP_CWORD-1}" 2>/dev/null)
+    if [ -z "$candidates" ]; then
+        COMPREPLY=($(compgen -f -- "$cur"))
+    else
+        COMPREPLY=($(compgen -W "$candidates" -- "$cur"))
+        # Keep the cursor after "Key=" so a value can follow
+        if [[ ${#COMPREPLY[@]} -eq 1 && ${COMPREPLY[0]} == *= ]]; then
+            compopt -o nospace
+        fi
+    fi
+}
+complete -F _ng_complete ng nextgen
+`
+
+const zshCompletion = `#compdef ng nextgen
+# zsh completion for ng and nextgen. Load with:
+#   source <(ng completion zsh)
+_ng() {
+    local -a candidates assignments
+    candidates=("${(@f)$(${words[1]} __complete "--cur=${words[CURRENT]}" "${(@)words[2,CURRENT-1]}" 2>/dev/null)}")
+    if [[ -z "${candidates[*]}" ]]; then
+        _files
+        return
+    fi
+    # "Key=" candidates keep the cursor after the "=" so a value can follow
+    assignments=(${(M)candidates:#*=})
+    candidates=(${candidates:#*=})
+    (( ${#assignments} )) && compadd -S '' -- "${assignments[@]}"
+    (( ${#candidates} )) && compadd -- "${candidates[@]}"
+}
+compdef _ng ng nextgen
+`
+
+const fishCompletion = `# fish completion for ng and nextgen. Load with:
+#   ng completion fish | source
+function __ng_complete
+    set -l tokens (commandline -opc)
+    $tokens[1] __complete "--cur="(commandline -ct) $tokens[2..-1] 2>/dev/null
+end
+complete -c ng -f -a '(__ng_complete)'
+complete -c nextgen -f -a '(__ng_complete)'
+`
+
+const powershellCompletion = `# PowerShell completion for ng and nextgen. Load with:
+#   ng completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName ng, nextgen -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $exe = $commandAst.CommandElements[0].ToString()
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '' -and $words.Count -gt 0) { $words = @($words | Select-Object -SkipLast 1) }
+    & $exe __complete "--cur=$wordToComplete" @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`
+
+// CompletionCommand prints the completion script for a shell.
+type CompletionCommand struct{}
+
+func init() {
+	RegisterCommand(&CompletionCommand{})
+}
+
+func (c *CompletionCommand) Name() string { return "completion" }
+
+func (c *CompletionCommand) Description() string {
+	return "Prints the shell completion script for bash, zsh, fish or powershell."
+}
+
+func (c *CompletionCommand) Usage() string { return "<bash|zsh|fish|powershell>" }
+
+func (c *CompletionCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "shell", Description: "Shell to print the script for: " + strings.Join(completionShells, ", "), Required: true}}
+}
+
+func (c *CompletionCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *CompletionCommand) Execute(args cli.CommandArgs) error {
+	script, err := CompletionScript(strings.ToLower(args.Variables[0]))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.WriteString(script)
+	return err
+}
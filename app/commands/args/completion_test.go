@@ -0,0 +1,50 @@
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestComplete tests completion of command words, flags, --var assignments and choices.
+func TestComplete(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".nextgen", "local-commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := `{"title": "pick kind", "args": [{"name": "Kind", "type": "select", "choices": [{"name": "Page", "value": "page"}, {"name": "Block", "value": "block"}]}],
+		"filePaths": [{"path": "out", "nodes": [{"name": "{{.Kind}}.md", "code": "{{.Name}}"}]}]}`
+	if err := os.WriteFile(filepath.Join(dir, ".nextgen", "local-commands", "pick-kind.json"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--cur=pick"}, []string{"pick-kind"}},
+		{[]string{"--cur=", "docs"}, []string{"disable", "enable", "generate"}},
+		{[]string{"--cur=", "pick-kind"}, []string{"block", "page"}},
+		{[]string{"--cur=--va", "pick-kind"}, []string{"--var", "--vars-file"}},
+		{[]string{"--cur=", "pick-kind", "--var"}, []string{"Kind=", "Name="}},
+		{[]string{"--cur=kind=p", "pick-kind", "--var"}, []string{"kind=page"}},
+		{[]string{"--cur=Kind=p", "pick-kind", "--var"}, []string{"Kind=page"}},
+		{[]string{"--cur=", "--output"}, []string{"json", "text"}},
+		{[]string{"--cur=--fo", "docs", "generate"}, []string{"--force", "--format"}},
+		{[]string{"--cur=--in", "apply"}, []string{"--install"}},
+	}
+	for _, tt := range tests {
+		if got := Complete(tt.args, dir, nil); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	for _, shell := range completionShells {
+		script, err := CompletionScript(shell)
+		if err != nil || !strings.Contains(script, CompleteCommandName+" \"--cur=") {
+			t.Errorf("%s script does not call the completion entry point: %v", shell, err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package args
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// -----------------------------------------------------------------------------
+// [DOCS FORMATS] Renderers for the generated command docs
+// -----------------------------------------------------------------------------
+
+var ansiRegexp = regexp.MustCompile("\u001B\\[[0-9;?]*[ -/]*[@-~]")
+
+func stripANSI(s string) string { return ansiRegexp.ReplaceAllString(s, "") }
+
+// Markers delimiting the generated section of AGENTS.md. Text outside them is kept.
+const (
+	agentsSectionStart = "<!-- nextgen-cli:commands:start -->"
+	agentsSectionEnd   = "<!-- nextgen-cli:commands:end -->"
+)
+
+// commandSummary returns a one-line description of a command for lists.
+func commandSummary(d commandDoc) string {
+	switch {
+	case d.Description != "":
+		return d.Description
+	case d.Overrides != "":
+		return fmt.Sprintf("project override of %s", d.Overrides)
+	case d.Kind == "composite":
+		return "runs a sequence of other commands"
+	case len(d.Variables) > 0:
+		names := make([]string, len(d.Variables))
+		for i, v := range d.Variables {
+			names[i] = v.Name
+		}
+		return "variables: " + strings.Join(names, ", ")
+	}
+	return d.Kind + " command"
+}
+
+// renderDocsMDC renders the Cursor rule listing every command with usage and a preview,
+// followed by the built-in commands hidden in the project and why.
+func renderDocsMDC(docs []commandDoc, hidden []hiddenCommandDoc) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString("description: Auto-generated list of NextGen CLI commands\n")
+	b.WriteString("globs:\n")
+	b.WriteString("alwaysApply: false\n")
+	b.WriteString("---\n\n")
+	b.WriteString("### All Commands\n")
+	b.WriteString(fmt.Sprintf("Updated: %s UTC\n\n", time.Now().UTC().Format(time.RFC3339)))
+	for _, d := range docs {
+		b.WriteString("- " + d.Name + "\n")
+	}
+
+	b.WriteString("\n### File Tree Previews\n\n")
+	for _, d := range docs {
+		b.WriteString("#### " + d.Name + "\n\n")
+		if d.Overrides != "" {
+			b.WriteString("- Overridden by project template `" + d.Location + "` (built-in: `" + d.Overrides + "`)\n")
+		}
+		if d.Description != "" {
+			b.WriteString("- " + d.Description + "\n")
+		}
+		labels := []string{"Usage (name)", "Usage (slug)"}
+		if len(d.Variables) == 0 {
+			labels = []string{"Usage", "Usage (slug)"}
+		}
+		for i, u := range d.Usage {
+			nextgen := "nextgen" + strings.TrimPrefix(u, "ng")
+			b.WriteString(fmt.Sprintf("- %s: `%s` | `%s`\n", labels[min(i, 1)], u, nextgen))
+		}
+		if len(d.Variables) > 0 {
+			names := make([]string, len(d.Variables))
+			for i, v := range d.Variables {
+				names[i] = v.Name
+			}
+			b.WriteString("- Variables: " + strings.Join(names, ", ") + "\n")
+		}
+		b.WriteString("\n")
+		if d.Preview == "" {
+			b.WriteString("(No file changes preview available)\n\n")
+		} else {
+			b.WriteString("```text\n" + d.Preview + "\n```\n\n")
+		}
+	}
+
+	if len(hidden) > 0 {
+		b.WriteString("### Hidden Commands\n\n")
+		b.WriteString("Not available in this project. Run `ng explain <slug>` for every condition.\n\n")
+		for _, h := range hidden {
+			b.WriteString("- " + h.Slug + ": " + h.Reason + "\n")
+		}
+	}
+	return b.String()
+}
+
+// renderDocsAgentsSection renders the AGENTS.md section, markers included.
+func renderDocsAgentsSection(docs []commandDoc) string {
+	var b strings.Builder
+	b.WriteString(agentsSectionStart + "\n")
+	b.WriteString("## NextGen CLI commands\n\n")
+	b.WriteString("This section is generated by `ng docs generate`; edits inside it are overwritten. ")
+	b.WriteString("Prefer these commands over writing the files they create by hand. ")
+	b.WriteString("Run `ng which <command>` to see what a name runs, and `.nextgen/commands.json` (when generated) for variables and previews.\n\n")
+	for _, d := range docs {
+		b.WriteString(fmt.Sprintf("- `%s` — %s\n", d.Usage[0], commandSummary(d)))
+	}
+	b.WriteString(agentsSectionEnd + "\n")
+	return b.String()
+}
+
+// spliceAgentsSection replaces the generated section in an AGENTS.md file, appending it
+// when the file has none.
+func spliceAgentsSection(existing, section string) string {
+	start := strings.Index(existing, agentsSectionStart)
+	end := strings.Index(existing, agentsSectionEnd)
+	if start >= 0 && end > start {
+		rest := strings.TrimPrefix(existing[end+len(agentsSectionEnd):], "\n")
+		return existing[:start] + section + rest
+	}
+	if strings.TrimSpace(existing) == "" {
+		return "# AGENTS.md\n\n" + section
+	}
+	return strings.TrimRight(existing, "\n") + "\n\n" + section
+}
+
+// renderDocsLLMs renders an llms.txt overview of the project's commands.
+func renderDocsLLMs(projectName string, docs []commandDoc) string {
+	var b strings.Builder
+	b.WriteString("# " + projectName + " NextGen CLI commands\n\n")
+	b.WriteString("> Code generators and scripts available in this project through the NextGen CLI (`ng`). ")
+	b.WriteString("Each command below can be run from the project root.\n\n")
+	groups := []struct {
+		title string
+		match func(commandDoc) bool
+	}{
+		{"Templates", func(d commandDoc) bool { return d.Kind == "template" || d.Kind == "composite" }},
+		{"Project and saved commands", func(d commandDoc) bool { return d.Kind == "shell" }},
+		{"CLI commands", func(d commandDoc) bool { return d.Kind == "args" }},
+		{"Other", func(d commandDoc) bool {
+			return d.Kind != "template" && d.Kind != "composite" && d.Kind != "shell" && d.Kind != "args"
+		}},
+	}
+	for _, g := range groups {
+		var lines []string
+		for _, d := range docs {
+			if g.match(d) {
+				lines = append(lines, fmt.Sprintf("- `%s`: %s", d.Usage[0], commandSummary(d)))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		b.WriteString("## " + g.title + "\n\n")
+		b.WriteString(strings.Join(lines, "\n") + "\n\n")
+	}
+	return b.String()
+}
+
+// renderDocsJSON renders the machine-readable command list.
+func renderDocsJSON(docs []commandDoc) ([]byte, error) {
+	out := struct {
+		Commands []commandDoc `json:"commands"`
+	}{Commands: docs}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep <Variable> placeholders readable
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return nil, fmt.Errorf("failed to encode commands.json: %w", err)
+	}
+	return buf.Bytes(), nil
+}
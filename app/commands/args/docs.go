@@ -0,0 +1,529 @@
+package args
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [DOCS] Generated command documentation for a project
+// -----------------------------------------------------------------------------
+
+// `ng docs generate` documents every command available in a project, for editors and coding
+// agents. Projects opt in to having the docs kept current on every run with `ng docs enable`,
+// which writes .nextgen/docs.json. Output is only rewritten when its inputs change: the hash
+// of the command list, the templates behind it and the CLI version is kept in
+// .nextgen/docs.hash.
+
+// Docs output formats.
+const (
+	DocsFormatMDC    = "mdc"    // .nextgen/nextgen-cli-commands.mdc (Cursor rule)
+	DocsFormatAgents = "agents" // a marked section of AGENTS.md in the project root
+	DocsFormatLLMs   = "llms"   // .nextgen/llms.txt
+	DocsFormatJSON   = "json"   // .nextgen/commands.json
+)
+
+// DocsFormats lists every docs output format.
+var DocsFormats = []string{DocsFormatMDC, DocsFormatAgents, DocsFormatLLMs, DocsFormatJSON}
+
+const (
+	docsConfigFile = "docs.json"
+	docsHashFile   = "docs.hash"
+)
+
+// DocsConfig is the per-project docs setting stored in .nextgen/docs.json.
+type DocsConfig struct {
+	Auto    bool     `json:"auto"`              // regenerate on every run when inputs changed
+	Formats []string `json:"formats,omitempty"` // formats to write; all when empty
+}
+
+// DocsResult reports what a docs generation did.
+type DocsResult struct {
+	Written   []string // files written, relative to the project
+	Unchanged bool     // inputs matched the previous generation, nothing was written
+}
+
+// hiddenCommandDoc is a built-in command that the project's visibility rules hide, with why.
+type hiddenCommandDoc struct {
+	Slug   string
+	Reason string
+}
+
+// hiddenCommandDocs lists the hidden built-in commands by slug.
+func hiddenCommandDocs(projectPath string) []hiddenCommandDoc {
+	var out []hiddenCommandDoc
+	for _, spec := range commands_pkg.RegisteredCommands() {
+		if trace := commands_pkg.ExplainVisibility(spec, projectPath); !trace.Visible {
+			out = append(out, hiddenCommandDoc{Slug: spec.Slug, Reason: trace.Reason})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Slug < out[j].Slug })
+	return out
+}
+
+// commandDoc describes one command in the generated docs.
+type commandDoc struct {
+	Name        string        `json:"name"`
+	Slug        string        `json:"slug,omitempty"`
+	Source      string        `json:"source"`
+	Kind        string        `json:"kind"`
+	Description string        `json:"description,omitempty"`
+	Usage       []string      `json:"usage"`
+	Variables   []variableDoc `json:"variables,omitempty"`
+	Args        []optionDoc   `json:"args,omitempty"`
+	Flags       []optionDoc   `json:"flags,omitempty"`
+	Overrides   string        `json:"overrides,omitempty"` // built-in template an override replaces
+	Location    string        `json:"location,omitempty"`
+	Preview     string        `json:"preview,omitempty"`
+}
+
+// variableDoc describes a template variable.
+type variableDoc struct {
+	Name        string   `json:"name"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+}
+
+// optionDoc describes a positional argument or flag of an args command.
+type optionDoc struct {
+	Name        string `json:"name"`
+	Short       string `json:"short,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	HasValue    bool   `json:"hasValue,omitempty"`
+}
+
+// docsPath returns the path of a file in the project's .nextgen folder.
+func docsPath(projectPath, name string) string {
+	return filepath.Join(projectPath, ".nextgen", name)
+}
+
+// LoadDocsConfig reads .nextgen/docs.json. A project without it has the zero config.
+func LoadDocsConfig(projectPath string) (DocsConfig, bool, error) {
+	var cfg DocsConfig
+	data, err := os.ReadFile(docsPath(projectPath, docsConfigFile))
+	if os.IsNotExist(err) {
+		return cfg, false, nil
+	}
+	if err != nil {
+		return cfg, false, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, true, fmt.Errorf("invalid %s: %w", docsConfigFile, err)
+	}
+	return cfg, true, nil
+}
+
+// SaveDocsConfig writes .nextgen/docs.json.
+func SaveDocsConfig(projectPath string, cfg DocsConfig) error {
+	if err := os.MkdirAll(filepath.Join(projectPath, ".nextgen"), 0755); err != nil {
+		return fmt.Errorf("failed to create .nextgen directory: %w", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(docsPath(projectPath, docsConfigFile), append(data, '\n'), 0644)
+}
+
+// parseDocsFormats validates a list of formats; an empty list means every format.
+func parseDocsFormats(formats []string) ([]string, error) {
+	var out []string
+	seen := map[string]bool{}
+	for _, f := range formats {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if f == "" || seen[f] {
+			continue
+		}
+		known := false
+		for _, k := range DocsFormats {
+			known = known || k == f
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown docs format %q (expected one of: %s)", f, strings.Join(DocsFormats, ", "))
+		}
+		seen[f] = true
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return DocsFormats, nil
+	}
+	return out, nil
+}
+
+// docsOutputPath returns the file a format is written to, relative to the project.
+func docsOutputPath(format string) string {
+	switch format {
+	case DocsFormatMDC:
+		return filepath.Join(".nextgen", "nextgen-cli-commands.mdc")
+	case DocsFormatAgents:
+		return "AGENTS.md"
+	case DocsFormatLLMs:
+		return filepath.Join(".nextgen", "llms.txt")
+	case DocsFormatJSON:
+		return filepath.Join(".nextgen", "commands.json")
+	}
+	return ""
+}
+
+// AutoGenerateDocs regenerates the docs of a project that enabled them in .nextgen/docs.json.
+// Other projects are left untouched.
+func AutoGenerateDocs(projectPath string, registry *project.ProjectRegistry) (DocsResult, error) {
+	cfg, found, err := LoadDocsConfig(projectPath)
+	if err != nil || !found || !cfg.Auto {
+		return DocsResult{Unchanged: true}, err
+	}
+	return GenerateDocs(projectPath, registry, cfg.Formats, false)
+}
+
+// GenerateDocs writes the given formats (every format when empty) for the project. Unless
+// force is set, nothing is written when the inputs hash matches the previous generation and
+// every output still exists.
+func GenerateDocs(projectPath string, registry *project.ProjectRegistry, formats []string, force bool) (DocsResult, error) {
+	formats, err := parseDocsFormats(formats)
+	if err != nil {
+		return DocsResult{}, err
+	}
+	names := BuildAllAvailableCommandNames(projectPath, registry)
+	resolver := commands_pkg.NewResolver(projectPath, registry)
+
+	hidden := hiddenCommandDocs(projectPath)
+	hash := docsInputsHash(projectPath, names, hidden, resolver, formats)
+	if !force && docsUpToDate(projectPath, hash, formats) {
+		return DocsResult{Unchanged: true}, nil
+	}
+
+	docs := make([]commandDoc, 0, len(names))
+	for _, name := range names {
+		docs = append(docs, buildCommandDoc(name, projectPath, registry, resolver))
+	}
+
+	if err := os.MkdirAll(filepath.Join(projectPath, ".nextgen"), 0755); err != nil {
+		return DocsResult{}, fmt.Errorf("failed to create .nextgen directory: %w", err)
+	}
+	var result DocsResult
+	for _, format := range formats {
+		rel := docsOutputPath(format)
+		target := filepath.Join(projectPath, rel)
+		var content []byte
+		switch format {
+		case DocsFormatMDC:
+			content = []byte(renderDocsMDC(docs, hidden))
+		case DocsFormatAgents:
+			existing, readErr := os.ReadFile(target)
+			if readErr != nil && !os.IsNotExist(readErr) {
+				return result, fmt.Errorf("failed to read %s: %w", rel, readErr)
+			}
+			content = []byte(spliceAgentsSection(string(existing), renderDocsAgentsSection(docs)))
+		case DocsFormatLLMs:
+			content = []byte(renderDocsLLMs(filepath.Base(projectPath), docs))
+		case DocsFormatJSON:
+			content, err = renderDocsJSON(docs)
+			if err != nil {
+				return result, err
+			}
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", rel, err)
+		}
+		result.Written = append(result.Written, rel)
+	}
+	if err := os.WriteFile(docsPath(projectPath, docsHashFile), []byte(hash+"\n"), 0644); err != nil {
+		return result, fmt.Errorf("failed to write %s: %w", docsHashFile, err)
+	}
+	return result, nil
+}
+
+// docsUpToDate reports whether the previous generation used the same inputs and its outputs
+// are still in place.
+func docsUpToDate(projectPath, hash string, formats []string) bool {
+	prev, err := os.ReadFile(docsPath(projectPath, docsHashFile))
+	if err != nil || strings.TrimSpace(string(prev)) != hash {
+		return false
+	}
+	for _, format := range formats {
+		if _, err := os.Stat(filepath.Join(projectPath, docsOutputPath(format))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// docsInputsHash hashes everything the docs are generated from: the CLI version, the
+// formats, the hidden commands with their reasons, and for every command its resolution and
+// template (with partials expanded).
+func docsInputsHash(projectPath string, names []string, hidden []hiddenCommandDoc, resolver commands_pkg.Resolver, formats []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "version %s\nformats %s\n", cli.Version(), strings.Join(formats, ","))
+	for _, hc := range hidden {
+		fmt.Fprintf(h, "hidden %q %q\n", hc.Slug, hc.Reason)
+	}
+	for _, name := range names {
+		fmt.Fprintf(h, "command %q\n", name)
+		if cmd, ok := GetCommand(name); ok {
+			fmt.Fprintf(h, "args %q %q\n", cmd.Usage(), cmd.Description())
+			continue
+		}
+		resolved, err := resolver.Resolve(name)
+		if err != nil {
+			fmt.Fprintf(h, "error %v\n", err)
+			continue
+		}
+		fmt.Fprintf(h, "%s %s %q %q %q\n", resolved.Source, resolved.Kind, resolved.Location, resolved.Spec.Slug, resolved.Shell)
+		if resolved.Template != nil {
+			data, err := commands_pkg.ResolveTemplateIncludes(resolved.Template, projectPath)
+			if err != nil {
+				data = resolved.Template
+			}
+			fmt.Fprintf(h, "template %d\n", len(data))
+			h.Write(data)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// buildCommandDoc collects the documentation of one command, including a file tree preview
+// rendered with a <Variable> placeholder for each variable.
+func buildCommandDoc(name, projectPath string, registry *project.ProjectRegistry, resolver commands_pkg.Resolver) commandDoc {
+	doc := commandDoc{Name: name, Usage: []string{"ng " + name}}
+	resolved, err := resolver.Resolve(name)
+	if err != nil {
+		doc.Source = "unknown"
+		return doc
+	}
+	doc.Source = string(resolved.Source)
+	doc.Kind = string(resolved.Kind)
+	if resolved.Kind == commands_pkg.KindArgs {
+		if cmd, ok := GetCommand(name); ok {
+			doc.Description = cmd.Description()
+			for _, a := range cmd.ExpectedArgs() {
+				doc.Args = append(doc.Args, optionDoc{Name: a.Name, Description: a.Description, Required: a.Required})
+			}
+			for _, f := range cmd.ExpectedFlags() {
+				doc.Flags = append(doc.Flags, optionDoc{Name: f.Name, Short: f.ShortName, Description: f.Description, Required: f.Required, HasValue: f.HasValue})
+			}
+			doc.Usage = []string{strings.TrimSpace("ng " + name + " " + cmd.Usage())}
+		}
+		return doc
+	}
+	if resolved.Kind == commands_pkg.KindShell {
+		doc.Description = "Runs `" + resolved.Shell + "`"
+		return doc
+	}
+
+	spec := commands_pkg.GetCommandSpec(name)
+	doc.Slug = spec.Slug
+	if spec.Overridden() && spec.Name == name {
+		doc.Overrides = spec.BuiltinPath
+		doc.Location = ".nextgen/" + spec.TemplatePath
+	}
+
+	keys, _ := commands_pkg.GetCommandVariableKeys(name, projectPath, registry)
+	priorities, _ := commands_pkg.GetCommandVariablePriorities(name, projectPath, registry)
+	sort.Slice(keys, func(i, j int) bool {
+		pi, iok := priorities[keys[i]]
+		pj, jok := priorities[keys[j]]
+		if iok != jok {
+			return iok
+		}
+		if pi != pj {
+			return pi < pj
+		}
+		return keys[i] < keys[j]
+	})
+	titles, _ := commands_pkg.GetCommandVariableTitles(name, projectPath, registry)
+	descs, _ := commands_pkg.GetCommandVariableDescriptions(name, projectPath, registry)
+	examples, _ := commands_pkg.GetCommandVariableExamples(name, projectPath, registry)
+	for _, k := range keys {
+		doc.Variables = append(doc.Variables, variableDoc{Name: k, Title: titles[k], Description: descs[k], Examples: examples[k]})
+	}
+
+	usage := func(cmd string) string {
+		var b strings.Builder
+		b.WriteString("ng " + cmd)
+		for _, k := range keys {
+			b.WriteString(" <" + k + ">")
+		}
+		return b.String()
+	}
+	doc.Usage = []string{usage(name)}
+	if spec.Slug != "" && spec.Slug != name {
+		doc.Usage = append(doc.Usage, usage(spec.Slug))
+	}
+
+	var placeholders map[string]string
+	if len(keys) > 0 {
+		vars := map[string]string{}
+		for _, k := range keys {
+			vars[k] = "<" + k + ">"
+		}
+		placeholders = commands_pkg.BuildPlaceholders(vars)
+	} else {
+		placeholders = commands_pkg.BuildAutoPlaceholders(map[string]string{"Main": "<Filename>"})
+	}
+	if resolved.Template != nil {
+		if pv, err := commands_pkg.GeneratePreviewFileTreeFromBytes(resolved.Template, placeholders, projectPath); err == nil {
+			doc.Preview = strings.TrimSpace(stripANSI(pv))
+		}
+	}
+	return doc
+}
+
+// DocsGenerateCommand writes the command docs for the current project.
+type DocsGenerateCommand struct{}
+
+// DocsEnableCommand opts the current project in to keeping its docs current on every run.
+type DocsEnableCommand struct{}
+
+// DocsDisableCommand stops regenerating the current project's docs on every run.
+type DocsDisableCommand struct{}
+
+func init() {
+	RegisterCommand(&DocsGenerateCommand{})
+	RegisterCommand(&DocsEnableCommand{})
+	RegisterCommand(&DocsDisableCommand{})
+}
+
+// docsFormatFlag describes the --format flag shared by the docs commands.
+var docsFormatFlag = FlagDef{
+	Name:        "format",
+	Description: "Comma-separated formats to write: " + strings.Join(DocsFormats, ", ") + " (default: all, or the formats in .nextgen/docs.json)",
+	HasValue:    true,
+	Repeatable:  true,
+}
+
+// splitFormats splits the --format values (`--format mdc,llms --format json`).
+func splitFormats(values []string) []string {
+	var formats []string
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			formats = append(formats, strings.Split(value, ",")...)
+		}
+	}
+	return formats
+}
+
+func (c *DocsGenerateCommand) Name() string { return "docs generate" }
+
+func (c *DocsGenerateCommand) Description() string {
+	return "Writes command docs for the project (.nextgen MDC rule, AGENTS.md section, llms.txt, commands.json)."
+}
+
+func (c *DocsGenerateCommand) Usage() string { return "[--format mdc,agents,llms,json] [--force]" }
+
+func (c *DocsGenerateCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *DocsGenerateCommand) ExpectedFlags() []FlagDef {
+	return []FlagDef{docsFormatFlag, {Name: "force", Description: "Regenerate even when nothing changed"}}
+}
+
+func (c *DocsGenerateCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+	formats := splitFormats(args.FlagValues("format"))
+	if len(formats) == 0 {
+		cfg, _, cfgErr := LoadDocsConfig(projectPath)
+		if cfgErr != nil {
+			return cfgErr
+		}
+		formats = cfg.Formats
+	}
+	result, err := GenerateDocs(projectPath, registry, formats, args.BoolFlags["force"])
+	if err != nil {
+		return err
+	}
+	if result.Unchanged {
+		fmt.Println("Docs are up to date.")
+		return nil
+	}
+	for _, f := range result.Written {
+		fmt.Printf("Wrote %s\n", filepath.ToSlash(f))
+	}
+	return nil
+}
+
+func (c *DocsEnableCommand) Name() string { return "docs enable" }
+
+func (c *DocsEnableCommand) Description() string {
+	return "Keeps the project's command docs current on every run (saved in .nextgen/docs.json)."
+}
+
+func (c *DocsEnableCommand) Usage() string { return "[--format mdc,agents,llms,json]" }
+
+func (c *DocsEnableCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *DocsEnableCommand) ExpectedFlags() []FlagDef { return []FlagDef{docsFormatFlag} }
+
+func (c *DocsEnableCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	cfg, _, err := LoadDocsConfig(projectPath)
+	if err != nil {
+		return err
+	}
+	if formats := splitFormats(args.FlagValues("format")); len(formats) > 0 {
+		if _, err := parseDocsFormats(formats); err != nil {
+			return err
+		}
+		cfg.Formats = formats
+	}
+	cfg.Auto = true
+	if err := SaveDocsConfig(projectPath, cfg); err != nil {
+		return err
+	}
+	formats, _ := parseDocsFormats(cfg.Formats)
+	fmt.Printf("Docs (%s) will be regenerated when commands change.\n", strings.Join(formats, ", "))
+	return nil
+}
+
+func (c *DocsDisableCommand) Name() string { return "docs disable" }
+
+func (c *DocsDisableCommand) Description() string {
+	return "Stops regenerating the project's command docs on every run."
+}
+
+func (c *DocsDisableCommand) Usage() string { return "" }
+
+func (c *DocsDisableCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *DocsDisableCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *DocsDisableCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	cfg, found, err := LoadDocsConfig(projectPath)
+	if err != nil {
+		return err
+	}
+	if !found || !cfg.Auto {
+		fmt.Println("Docs are not regenerated automatically for this project.")
+		return nil
+	}
+	cfg.Auto = false
+	if err := SaveDocsConfig(projectPath, cfg); err != nil {
+		return err
+	}
+	fmt.Println("Docs will only be written by `ng docs generate`.")
+	return nil
+}
@@ -0,0 +1,65 @@
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSpliceAgentsSection tests that only the generated section of AGENTS.md is replaced.
+func TestSpliceAgentsSection(t *testing.T) {
+	section := agentsSectionStart + "\nnew\n" + agentsSectionEnd + "\n"
+	if got := spliceAgentsSection("", section); got != "# AGENTS.md\n\n"+section {
+		t.Errorf("unexpected new file: %q", got)
+	}
+	existing := "# Rules\n\nBe nice.\n"
+	appended := spliceAgentsSection(existing, section)
+	if appended != "# Rules\n\nBe nice.\n\n"+section {
+		t.Errorf("unexpected append: %q", appended)
+	}
+	replaced := spliceAgentsSection(strings.Replace(appended, "new", "old", 1)+"\nFooter\n", section)
+	if replaced != appended+"\nFooter\n" {
+		t.Errorf("unexpected replacement: %q", replaced)
+	}
+}
+
+// TestGenerateDocsIncremental tests that docs are only rewritten when inputs or outputs change.
+func TestGenerateDocsIncremental(t *testing.T) {
+	dir := t.TempDir()
+	if res, err := AutoGenerateDocs(dir, nil); err != nil || !res.Unchanged {
+		t.Fatalf("projects without docs.json must be left alone: %+v %v", res, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".nextgen")); !os.IsNotExist(err) {
+		t.Fatal("AutoGenerateDocs created .nextgen")
+	}
+
+	res, err := GenerateDocs(dir, nil, []string{"json", "llms"}, false)
+	if err != nil || len(res.Written) != 2 {
+		t.Fatalf("unexpected first generation: %+v %v", res, err)
+	}
+	if res, err := GenerateDocs(dir, nil, []string{"json", "llms"}, false); err != nil || !res.Unchanged {
+		t.Errorf("expected unchanged inputs to skip generation: %+v %v", res, err)
+	}
+	if err := os.Remove(filepath.Join(dir, docsOutputPath(DocsFormatLLMs))); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := GenerateDocs(dir, nil, []string{"json", "llms"}, false); err != nil || res.Unchanged {
+		t.Errorf("expected a missing output to be regenerated: %+v %v", res, err)
+	}
+
+	// A new project command changes the inputs
+	if err := os.MkdirAll(filepath.Join(dir, ".nextgen", "local-commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".nextgen", "local-commands", "say-hi.json"), []byte(`{"command": "echo hi"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := GenerateDocs(dir, nil, []string{"json", "llms"}, false); err != nil || res.Unchanged {
+		t.Fatalf("expected a new command to trigger generation: %+v %v", res, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, docsOutputPath(DocsFormatJSON)))
+	if err != nil || !strings.Contains(string(data), `"name": "say-hi"`) {
+		t.Errorf("commands.json does not list the project command: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package args
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// DoctorCommand reports on configuration, project detection and command health.
+type DoctorCommand struct{}
+
+func init() {
+	RegisterCommand(&DoctorCommand{})
+}
+
+func (c *DoctorCommand) Name() string { return "doctor" }
+
+func (c *DoctorCommand) Description() string {
+	return "Checks config files, project detection, command packages, hidden commands, local commands and indexer markers, and suggests fixes."
+}
+
+func (c *DoctorCommand) Usage() string { return "[--all]" }
+
+func (c *DoctorCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *DoctorCommand) ExpectedFlags() []FlagDef {
+	return []FlagDef{{Name: "all", Description: "Also list passing checks and every hidden command"}}
+}
+
+// doctorSymbols marks each check status in text output.
+var doctorSymbols = map[string]string{
+	commands_pkg.DoctorOK:    "✓",
+	commands_pkg.DoctorInfo:  "·",
+	commands_pkg.DoctorWarn:  "!",
+	commands_pkg.DoctorError: "✗",
+}
+
+func (c *DoctorCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+	report := commands_pkg.RunDoctor(projectPath, registry)
+	errorCount := report.Count(commands_pkg.DoctorError)
+
+	if cli.IsJSONOutput() {
+		cli.SetResultData(map[string]any{"projectPath": report.ProjectPath, "checks": report.Checks, "fixes": report.Fixes()})
+	} else {
+		printDoctorReport(report, args.BoolFlags["all"])
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", errorCount)
+	}
+	return nil
+}
+
+// printDoctorReport prints the checks by section. Without all, hidden commands are only
+// counted and passing checks are left out of the Commands section.
+func printDoctorReport(report commands_pkg.DoctorReport, all bool) {
+	for _, section := range commands_pkg.DoctorSections {
+		fmt.Printf("%s\n", section)
+		hidden := 0
+		for _, check := range report.Checks {
+			if check.Section != section {
+				continue
+			}
+			if section == "Commands" && check.Status == commands_pkg.DoctorInfo && !all {
+				hidden++
+				continue
+			}
+			fmt.Printf("  %s %s: %s\n", doctorSymbols[check.Status], check.Name, check.Detail)
+			if check.Status == commands_pkg.DoctorInfo && check.Fix != "" {
+				fmt.Printf("      %s\n", check.Fix)
+			}
+		}
+		if hidden > 0 {
+			fmt.Printf("  · %d commands are hidden in this project (run `ng doctor --all` to see why)\n", hidden)
+		}
+		fmt.Println()
+	}
+
+	fixes := report.Fixes()
+	if len(fixes) == 0 {
+		fmt.Println("No problems found.")
+		return
+	}
+	fmt.Println("Suggested fixes")
+	for i, fix := range fixes {
+		fmt.Printf("  %d. %s\n", i+1, fix)
+	}
+}
@@ -0,0 +1,129 @@
+package args
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// ExplainCommand shows whether a command is shown in the current project and why.
+type ExplainCommand struct{}
+
+func init() {
+	RegisterCommand(&ExplainCommand{})
+}
+
+func (c *ExplainCommand) Name() string { return "explain" }
+
+func (c *ExplainCommand) Description() string {
+	return "Shows whether a command is shown in this project, evaluating each visibility condition against the project and suggesting how to show it."
+}
+
+func (c *ExplainCommand) Usage() string { return "<command...>" }
+
+func (c *ExplainCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "command...", Description: "Command name or slug (multiple words are joined)", Required: true}}
+}
+
+func (c *ExplainCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *ExplainCommand) Execute(args cli.CommandArgs) error {
+	if len(args.Variables) == 0 {
+		return fmt.Errorf("missing command name")
+	}
+	name := strings.Join(args.Variables, " ")
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+
+	resolved, err := commands_pkg.NewResolver(projectPath, registry).Resolve(name)
+	if errors.Is(err, commands_pkg.ErrCommandNotFound) {
+		return fmt.Errorf("no command named %q", name)
+	}
+	if err != nil {
+		return err
+	}
+	trace := explainResolved(resolved, projectPath)
+
+	if cli.IsJSONOutput() {
+		cli.SetResultData(map[string]any{
+			"name":    name,
+			"runs":    resolved.Describe(),
+			"slug":    resolved.Spec.Slug,
+			"visible": trace.Visible,
+			"reason":  trace.Reason,
+			"checks":  trace.Checks,
+			"fixes":   trace.Fixes,
+		})
+		return nil
+	}
+	printVisibilityTrace(name, resolved, trace)
+	return nil
+}
+
+// explainResolved returns the visibility trace of a resolved command. Only registered
+// templates have visibility rules; every other command is always shown.
+func explainResolved(resolved commands_pkg.ResolvedCommand, projectPath string) commands_pkg.VisibilityTrace {
+	if resolved.Spec.Name == "" {
+		return commands_pkg.VisibilityTrace{Visible: true, Reason: fmt.Sprintf("%s commands have no visibility rule", resolved.Source)}
+	}
+	return commands_pkg.ExplainVisibility(resolved.Spec, projectPath)
+}
+
+// printVisibilityTrace prints the outcome and every evaluated condition, by anyOf clause.
+func printVisibilityTrace(name string, resolved commands_pkg.ResolvedCommand, trace commands_pkg.VisibilityTrace) {
+	fmt.Printf("%s\n", name)
+	fmt.Printf("  runs:    %s\n", resolved.Describe())
+	if resolved.Spec.Slug != "" {
+		fmt.Printf("  slug:    %s\n", resolved.Spec.Slug)
+	}
+	state := "shown"
+	if !trace.Visible {
+		state = "hidden"
+	}
+	fmt.Printf("  visible: %s (%s)\n", state, trace.Reason)
+
+	groups := []int{}
+	seen := map[int]bool{}
+	for _, check := range trace.Checks {
+		if !seen[check.Group] {
+			seen[check.Group] = true
+			groups = append(groups, check.Group)
+		}
+	}
+	for _, g := range groups {
+		if g < 0 {
+			fmt.Println("  all of:")
+		} else {
+			fmt.Printf("  anyOf clause %d:\n", g+1)
+		}
+		for _, check := range trace.Group(g) {
+			symbol := "✗"
+			if check.Passed {
+				symbol = "✓"
+			}
+			where := ""
+			if check.Path != "" {
+				where = check.Path + ": "
+			}
+			fmt.Printf("    %s %s%s\n", symbol, where, check.Describe())
+		}
+	}
+	for i, fix := range trace.Fixes {
+		label := "to show:"
+		if i > 0 {
+			label = "or:"
+		}
+		fmt.Printf("  %-8s %s\n", label, fix)
+	}
+}
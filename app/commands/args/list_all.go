@@ -62,20 +62,18 @@ func (c *ListAllCommand) ExpectedFlags() []FlagDef {
 
 // Execute function replicates the logic from getPrioritizedCommandList
 func (c *ListAllCommand) Execute(args cli.CommandArgs) error {
-	fmt.Println("Available Commands (Prioritized):")
-
 	// --- Load Registry ---
 	registry, err := project.LoadProjectRegistry()
 	if err != nil {
 		// Handle case where registry might not exist yet gracefully
-		fmt.Printf("Warning: Could not load project registry: %v. Some command types might be missing.\n", err)
+		cli.Warn("Could not load project registry: %v. Some command types might be missing.", err)
 		registry = &project.ProjectRegistry{} // Use an empty registry
 	}
 
 	// --- Get Project Path ---
 	projectPath, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Warning: Could not get current directory: %v. Project commands will be skipped.\n", err)
+		cli.Warn("Could not get current directory: %v. Project commands will be skipped.", err)
 		projectPath = "" // Continue without project path if needed
 	}
 
@@ -144,6 +142,10 @@ func (c *ListAllCommand) Execute(args cli.CommandArgs) error {
 			for _, entry := range entries {
 				if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
 					name := strings.TrimSuffix(entry.Name(), ".json")
+					// Overrides are listed under the name of the command they shadow
+					if _, isOverride := commands_pkg.OverrideForLocalFile(name); isOverride {
+						continue
+					}
 					projNames = append(projNames, name)
 				}
 			}
@@ -155,7 +157,7 @@ func (c *ListAllCommand) Execute(args cli.CommandArgs) error {
 				}
 			}
 		} else if !os.IsNotExist(readErr) {
-			fmt.Printf("Warning: Could not read project commands directory '%s': %v\n", localCmdDir, readErr)
+			cli.Warn("Could not read project commands directory '%s': %v", localCmdDir, readErr)
 		}
 	}
 
@@ -222,12 +224,31 @@ func (c *ListAllCommand) Execute(args cli.CommandArgs) error {
 		}
 	}
 
+	// --- Report the list as data in JSON mode ---
+	if cli.IsJSONOutput() {
+		type listedCommand struct {
+			Name       string `json:"name"`
+			Overridden bool   `json:"overridden,omitempty"`
+		}
+		listed := make([]listedCommand, len(fullList))
+		for i, cmdName := range fullList {
+			listed[i] = listedCommand{Name: cmdName, Overridden: commands_pkg.IsOverridden(cmdName)}
+		}
+		cli.SetResultData(map[string]any{"commands": listed})
+		return nil
+	}
+
 	// --- Print the Final List ---
+	fmt.Println("Available Commands (Prioritized):")
 	if len(fullList) == 0 {
 		fmt.Println("  (No commands found)")
 	} else {
 		for _, cmdName := range fullList {
 			// TODO: Add favorite indicator (⭐) based on registry check?
+			if commands_pkg.IsOverridden(cmdName) {
+				fmt.Printf("  - %s (overridden)\n", cmdName)
+				continue
+			}
 			fmt.Printf("  - %s\n", cmdName)
 		}
 	}
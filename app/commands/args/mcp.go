@@ -0,0 +1,576 @@
+package args
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [MCP] Model Context Protocol server over stdio
+// -----------------------------------------------------------------------------
+
+// `ng mcp serve` lets AI agents discover and run the project's commands through typed tool
+// calls instead of guessed shell invocations. Messages are newline-delimited JSON-RPC 2.0 on
+// stdin/stdout; anything commands print goes to stderr so it cannot corrupt the stream.
+//
+// Every file template visible in the project becomes a tool whose input schema has one
+// string property per template variable (with the titles, descriptions and examples the
+// template declares) plus "dryRun", which returns the planned files and a file tree preview
+// without writing, and "install", which installs the packages the template needs but the
+// project lacks (both runs report them). A real run returns the created files and a unified
+// diff of every planned file. The read-only args commands in mcpArgsCommands become tools
+// with their positional arguments as properties and their flags under "flags"; commands
+// that install, remove or write anything (pack install, docs enable, apply, ...) are not
+// exposed, since an agent could run them without the user confirming.
+
+// mcpArgsCommands lists the args commands exposed as tools.
+var mcpArgsCommands = map[string]bool{
+	"which":       true,
+	"explain":     true,
+	"doctor":      true,
+	"list-all":    true,
+	"pack list":   true,
+	"pack verify": true,
+	"workspaces":  true,
+}
+
+// mcpProtocolVersions lists the protocol revisions the server speaks, newest first.
+var mcpProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// mcpTool is a command exposed as an MCP tool.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+
+	command string  // command name it runs
+	args    Command // set for args commands
+}
+
+// mcpServer serves one client session for a project.
+type mcpServer struct {
+	projectPath string
+	registry    *project.ProjectRegistry
+	out         io.Writer
+	tools       map[string]mcpTool
+}
+
+// ServeMCP serves MCP requests read from in, writing responses to out, until in is closed.
+func ServeMCP(in io.Reader, out io.Writer, projectPath string, registry *project.ProjectRegistry) error {
+	s := &mcpServer{projectPath: projectPath, registry: registry, out: out}
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if werr := s.handle(line); werr != nil {
+				return werr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// handle processes one message and writes its response, if any.
+func (s *mcpServer) handle(line []byte) error {
+	var req rpcRequest
+	if err := json.Unmarshal(line, &req); err != nil {
+		return s.reply(rpcResponse{ID: json.RawMessage("null"), Error: &rpcError{Code: rpcParseError, Message: err.Error()}})
+	}
+	isNotification := len(req.ID) == 0
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if isNotification {
+			return nil
+		}
+		return s.reply(rpcResponse{ID: req.ID, Error: &rpcError{Code: rpcInvalidRequest, Message: "expected a JSON-RPC 2.0 request"}})
+	}
+
+	result, rerr := s.dispatch(req)
+	if isNotification {
+		return nil
+	}
+	return s.reply(rpcResponse{ID: req.ID, Result: result, Error: rerr})
+}
+
+func (s *mcpServer) reply(resp rpcResponse) error {
+	resp.JSONRPC = "2.0"
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	_, err = s.out.Write(append(data, '\n'))
+	return err
+}
+
+// dispatch runs a method and returns its result or error.
+func (s *mcpServer) dispatch(req rpcRequest) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		version := mcpProtocolVersions[0]
+		for _, v := range mcpProtocolVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": "nextgen-cli", "version": cli.Version()},
+			"instructions":    "Tools generate code in " + s.projectPath + ". Call a template tool with dryRun first to see the files it will write.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		s.tools = s.buildTools()
+		list := make([]mcpTool, 0, len(s.tools))
+		for _, t := range s.tools {
+			list = append(list, t)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+		return map[string]any{"tools": list}, nil
+	case "tools/call":
+		var params struct {
+			Name      string         `json:"name"`
+			Arguments map[string]any `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		if s.tools == nil {
+			s.tools = s.buildTools()
+		}
+		tool, ok := s.tools[params.Name]
+		if !ok {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+		}
+		return s.callTool(tool, params.Arguments), nil
+	}
+	if strings.HasPrefix(req.Method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+}
+
+var toolNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// mcpFlagType returns the JSON schema type of a flag's values.
+func mcpFlagType(f FlagDef) string {
+	switch {
+	case !f.HasValue:
+		return "boolean"
+	case f.Type == cli.FlagTypeInt:
+		return "integer"
+	case f.Type == cli.FlagTypeFloat:
+		return "number"
+	}
+	return "string"
+}
+
+// mcpToolName turns a slug or command name into a valid tool name.
+func mcpToolName(name string) string {
+	n := strings.Trim(toolNameInvalid.ReplaceAllString(strings.ReplaceAll(name, " ", "-"), "-"), "-")
+	if len(n) > 64 {
+		n = n[:64]
+	}
+	return n
+}
+
+// buildTools lists the commands exposed as tools: file templates visible in the project and
+// the args commands in mcpArgsCommands. Earlier sources win when two commands map to the
+// same tool name.
+func (s *mcpServer) buildTools() map[string]mcpTool {
+	tools := map[string]mcpTool{}
+	resolver := commands_pkg.NewResolver(s.projectPath, s.registry)
+	for _, name := range BuildAllAvailableCommandNames(s.projectPath, s.registry) {
+		resolved, err := resolver.Resolve(name)
+		if err != nil {
+			continue
+		}
+		var tool mcpTool
+		switch {
+		case resolved.Kind == commands_pkg.KindArgs:
+			cmd, ok := GetCommand(name)
+			if !ok || !mcpArgsCommands[cmd.Name()] {
+				continue
+			}
+			tool = argsTool(cmd)
+		case resolved.Kind == commands_pkg.KindTemplate && commands_pkg.IsFileTemplate(resolved.Template):
+			if resolved.Source == commands_pkg.SourceBuiltin && !commands_pkg.IsCommandVisible(resolved.Spec, s.projectPath) {
+				continue
+			}
+			tool = s.templateTool(name, resolved)
+		default:
+			continue
+		}
+		if tool.Name == "" {
+			continue
+		}
+		if _, taken := tools[tool.Name]; !taken {
+			tools[tool.Name] = tool
+		}
+	}
+	return tools
+}
+
+// argsTool describes an args command: one property per positional argument (an array for
+// variadic "name..." arguments) and its flags under "flags".
+func argsTool(cmd Command) mcpTool {
+	props := map[string]any{}
+	required := []string{}
+	for _, a := range cmd.ExpectedArgs() {
+		name := strings.TrimSuffix(a.Name, "...")
+		if name != a.Name {
+			props[name] = map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": a.Description}
+		} else {
+			props[name] = map[string]any{"type": "string", "description": a.Description}
+		}
+		if a.Required {
+			required = append(required, name)
+		}
+	}
+	if flags := cmd.ExpectedFlags(); len(flags) > 0 {
+		flagProps := map[string]any{}
+		for _, f := range flags {
+			prop := map[string]any{"type": mcpFlagType(f), "description": f.Description}
+			if f.Repeatable {
+				prop = map[string]any{"type": "array", "items": map[string]any{"type": mcpFlagType(f)}, "description": f.Description}
+			}
+			flagProps[f.Name] = prop
+		}
+		props["flags"] = map[string]any{"type": "object", "properties": flagProps, "additionalProperties": false}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return mcpTool{
+		Name:        mcpToolName(cmd.Name()),
+		Description: fmt.Sprintf("%s (ng %s %s)", cmd.Description(), cmd.Name(), cmd.Usage()),
+		InputSchema: schema,
+		command:     cmd.Name(),
+		args:        cmd,
+	}
+}
+
+// templateTool describes a file template: one string property per variable, with the
+// choices, default and required flag its args declare.
+func (s *mcpServer) templateTool(name string, resolved commands_pkg.ResolvedCommand) mcpTool {
+	toolName := resolved.Spec.Slug
+	if toolName == "" {
+		toolName = name
+	}
+	vars := commands_pkg.TemplateVariables(resolved.Template, s.projectPath)
+	titles, _ := commands_pkg.GetCommandVariableTitles(name, s.projectPath, s.registry)
+	descs, _ := commands_pkg.GetCommandVariableDescriptions(name, s.projectPath, s.registry)
+	examples, _ := commands_pkg.GetCommandVariableExamples(name, s.projectPath, s.registry)
+
+	props := map[string]any{
+		"dryRun":  map[string]any{"type": "boolean", "description": "Only return the planned files and a preview; write nothing"},
+		"install": map[string]any{"type": "boolean", "description": "After writing, install packages the template needs that package.json lacks"},
+	}
+	required := []string{}
+	for _, v := range vars {
+		p := map[string]any{"type": "string"}
+		if titles[v.Name] != "" {
+			p["title"] = titles[v.Name]
+		}
+		if descs[v.Name] != "" {
+			p["description"] = descs[v.Name]
+		} else if v.Message != "" {
+			p["description"] = v.Message
+		}
+		if len(examples[v.Name]) > 0 {
+			p["examples"] = examples[v.Name]
+		}
+		if len(v.Choices) > 0 {
+			p["enum"] = v.Choices
+		}
+		if v.Default != "" {
+			p["default"] = v.Default
+		}
+		if v.Required {
+			required = append(required, v.Name)
+		}
+		props[v.Name] = p
+	}
+	schema := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return mcpTool{
+		Name:        mcpToolName(toolName),
+		Description: fmt.Sprintf("Runs the NextGen template %q (%s).", name, resolved.Describe()),
+		InputSchema: schema,
+		command:     name,
+	}
+}
+
+// toolResult builds a tools/call result with a text summary and structured content.
+func toolResult(text string, structured map[string]any, isError bool) map[string]any {
+	res := map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+	if structured != nil {
+		res["structuredContent"] = structured
+	}
+	return res
+}
+
+// callTool runs a tool. Failures are reported as tool errors so the agent can react to them.
+func (s *mcpServer) callTool(tool mcpTool, arguments map[string]any) map[string]any {
+	if tool.args != nil {
+		return s.callArgsTool(tool, arguments)
+	}
+	return s.callTemplateTool(tool, arguments)
+}
+
+func (s *mcpServer) callArgsTool(tool mcpTool, arguments map[string]any) map[string]any {
+	parsed := cli.CommandArgs{CommandName: tool.command, Flags: map[string]string{}, BoolFlags: map[string]bool{}, ListFlags: map[string][]string{}}
+	for _, a := range tool.args.ExpectedArgs() {
+		name := strings.TrimSuffix(a.Name, "...")
+		switch v := arguments[name].(type) {
+		case string:
+			parsed.Variables = append(parsed.Variables, v)
+		case []any:
+			for _, item := range v {
+				parsed.Variables = append(parsed.Variables, fmt.Sprint(item))
+			}
+		case nil:
+			if a.Required {
+				return toolResult(fmt.Sprintf("missing required argument %q", name), nil, true)
+			}
+		default:
+			parsed.Variables = append(parsed.Variables, fmt.Sprint(v))
+		}
+	}
+	if flags, ok := arguments["flags"].(map[string]any); ok {
+		for k, v := range flags {
+			switch val := v.(type) {
+			case bool:
+				parsed.BoolFlags[k] = val
+			case []any:
+				for _, item := range val {
+					parsed.ListFlags[k] = append(parsed.ListFlags[k], fmt.Sprint(item))
+					parsed.Flags[k] = fmt.Sprint(item)
+				}
+			default:
+				parsed.Flags[k] = fmt.Sprint(val)
+			}
+		}
+	}
+
+	output, err := captureStdout(func() error { return tool.args.Execute(parsed) })
+	structured := map[string]any{"command": tool.command, "args": parsed.Variables, "output": output}
+	if err != nil {
+		structured["error"] = err.Error()
+		return toolResult(strings.TrimSpace(output+"\n"+err.Error()), structured, true)
+	}
+	return toolResult(output, structured, false)
+}
+
+// plannedFile is a file a template writes.
+type plannedFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // "create" or "update"
+}
+
+func (s *mcpServer) callTemplateTool(tool mcpTool, arguments map[string]any) map[string]any {
+	resolved, err := commands_pkg.NewResolver(s.projectPath, s.registry).Resolve(tool.command)
+	if err != nil {
+		return toolResult(err.Error(), nil, true)
+	}
+	named := map[string]string{}
+	for k, v := range arguments {
+		if k == "dryRun" || k == "install" || v == nil || strings.TrimSpace(fmt.Sprint(v)) == "" {
+			continue
+		}
+		named[k] = fmt.Sprint(v)
+	}
+	// The same defaults, requiredWhen rules and missing-variable messages as the CLI
+	vars, err := commands_pkg.ResolveTemplateVariables(commands_pkg.TemplateVariables(resolved.Template, s.projectPath), commands_pkg.VariableInput{Named: named})
+	if err != nil {
+		return toolResult(err.Error(), nil, true)
+	}
+	placeholders := commands_pkg.BuildPlaceholders(vars)
+
+	paths, err := commands_pkg.PlannedTemplateFiles(resolved.Template, placeholders, s.projectPath)
+	if err != nil {
+		return toolResult(err.Error(), nil, true)
+	}
+	before := map[string]string{}
+	var plan []plannedFile
+	for _, p := range paths {
+		action := "create"
+		if data, readErr := os.ReadFile(filepath.Join(s.projectPath, p)); readErr == nil {
+			before[p] = string(data)
+			action = "update"
+		}
+		plan = append(plan, plannedFile{Path: filepath.ToSlash(p), Action: action})
+	}
+	structured := map[string]any{"command": tool.command, "source": resolved.Describe(), "variables": vars, "plan": plan}
+	missingDeps := commands_pkg.MissingDependencies(resolved.Template, s.projectPath)
+	if len(missingDeps) > 0 {
+		structured["missingDependencies"] = missingDeps
+		structured["installCommand"] = commands_pkg.InstallHint(s.projectPath, missingDeps)
+	}
+
+	if dry, _ := arguments["dryRun"].(bool); dry {
+		preview, _ := commands_pkg.GeneratePreviewFileTreeFromBytes(resolved.Template, placeholders, s.projectPath)
+		preview = stripANSI(preview)
+		structured["preview"] = preview
+		text := fmt.Sprintf("Dry run of %s; files it would write:\n%s", tool.command, preview)
+		if len(missingDeps) > 0 {
+			text += "\nMissing packages; install them with: " + commands_pkg.InstallHint(s.projectPath, missingDeps)
+		}
+		return toolResult(text, structured, false)
+	}
+
+	commands_pkg.CreatedFiles = []string{}
+	commands_pkg.EditedIndexers = make(map[string]bool)
+	output, err := captureStdout(func() error {
+		return commands_pkg.ExecuteJSONTemplateFromMemory(resolved.Template, s.projectPath, placeholders)
+	})
+	created := append([]string{}, commands_pkg.CreatedFiles...)
+	structured["createdFiles"] = created
+	if strings.TrimSpace(output) != "" {
+		structured["output"] = output
+	}
+	if err != nil {
+		structured["error"] = err.Error()
+		return toolResult(err.Error(), structured, true)
+	}
+
+	var diff strings.Builder
+	for _, p := range paths {
+		after, readErr := os.ReadFile(filepath.Join(s.projectPath, p))
+		if readErr != nil {
+			continue
+		}
+		from := "a/" + filepath.ToSlash(p)
+		if _, existed := before[p]; !existed {
+			from = "/dev/null"
+		}
+		diff.WriteString(commands_pkg.UnifiedDiff(from, "b/"+filepath.ToSlash(p), before[p], string(after)))
+	}
+	structured["diff"] = diff.String()
+
+	if s.registry != nil && resolved.Source != commands_pkg.SourceClipboard {
+		_ = s.registry.RecordCommandHistory(s.projectPath, project.HistoricCommand{
+			Name:           tool.command,
+			Variables:      placeholders,
+			Timestamp:      time.Now().Unix(),
+			GeneratedFiles: created,
+		})
+	}
+	text := fmt.Sprintf("Ran %s; %d file(s) created.", tool.command, len(created))
+	if len(missingDeps) > 0 {
+		if install, _ := arguments["install"].(bool); install {
+			installOutput, installErr := captureStdout(func() error { return commands_pkg.InstallDependencies(s.projectPath, missingDeps) })
+			structured["installOutput"] = installOutput
+			if installErr != nil {
+				structured["error"] = installErr.Error()
+				return toolResult(text+"\nInstalling dependencies failed: "+installErr.Error(), structured, true)
+			}
+			structured["installed"] = true
+			text += fmt.Sprintf("\nInstalled %d missing package(s).", len(missingDeps))
+		} else {
+			text += "\nMissing packages; install them with: " + commands_pkg.InstallHint(s.projectPath, missingDeps)
+		}
+	}
+	if diff.Len() > 0 {
+		text += "\n\n" + diff.String()
+	}
+	return toolResult(text, structured, false)
+}
+
+// captureStdout runs fn and returns what it printed to stdout, without colours.
+func captureStdout(fn func() error) (string, error) {
+	output, err := cli.CaptureStdout(fn)
+	return stripANSI(output), err
+}
+
+// MCPServeCommand serves the project's commands to AI agents over MCP.
+type MCPServeCommand struct{}
+
+func init() {
+	RegisterCommand(&MCPServeCommand{})
+}
+
+func (c *MCPServeCommand) Name() string { return "mcp serve" }
+
+func (c *MCPServeCommand) Description() string {
+	return "Serves the project's commands as MCP tools over stdio, for AI agents."
+}
+
+func (c *MCPServeCommand) Usage() string { return "" }
+
+func (c *MCPServeCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *MCPServeCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *MCPServeCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+	// stdout carries the protocol; everything else printed while serving goes to stderr
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+	fmt.Fprintf(os.Stderr, "NextGen MCP server for %s listening on stdio\n", projectPath)
+	return ServeMCP(os.Stdin, out, projectPath, registry)
+}
@@ -0,0 +1,121 @@
+package args
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// mcpSession sends requests to a server for projectPath and returns the responses by id.
+func mcpSession(t *testing.T, projectPath string, requests ...string) map[int]map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	if err := ServeMCP(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out, projectPath, nil); err != nil {
+		t.Fatal(err)
+	}
+	responses := map[int]map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		id, _ := resp["id"].(float64)
+		responses[int(id)] = resp
+	}
+	return responses
+}
+
+// TestMCPServe tests listing, dry-running and running a project template over MCP.
+func TestMCPServe(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".nextgen", "local-commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := `{"title": "make note", "args": [{"name": "Name", "description": "Note name", "required": true},
+		{"name": "Tag", "default": "idea", "choices": [{"name": "Idea", "value": "idea"}, {"name": "Todo", "value": "todo"}]}],
+		"filePaths": [{"path": "notes", "nodes": [{"name": "{{.KebabName}}.md", "code": "# {{.Name}}\n"}]}]}`
+	if err := os.WriteFile(filepath.Join(dir, ".nextgen", "local-commands", "make-note.json"), []byte(tmpl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	responses := mcpSession(t, dir,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"make-note","arguments":{"Name":"Shopping List","Tag":"todo","dryRun":true}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"make-note","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"make-note","arguments":{"Name":"Shopping List"}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"no/such/method"}`,
+	)
+	if len(responses) != 6 {
+		t.Fatalf("expected 6 responses (none for the notification), got %d", len(responses))
+	}
+	if v := responses[1]["result"].(map[string]any)["protocolVersion"]; v != "2024-11-05" {
+		t.Errorf("expected the client's protocol version, got %v", v)
+	}
+
+	var note map[string]any
+	listed := map[string]bool{}
+	for _, raw := range responses[2]["result"].(map[string]any)["tools"].([]any) {
+		tool := raw.(map[string]any)
+		listed[tool["name"].(string)] = true
+		if tool["name"] == "make-note" {
+			note = tool
+		}
+	}
+	if note == nil {
+		t.Fatal("make-note tool not listed")
+	}
+	// Only read-only args commands are exposed; agents must not install or remove packs
+	for _, name := range []string{"pack-install", "pack-add-git", "pack-update", "pack-remove", "docs-enable", "docs-disable", "docs-generate", "apply", "completion", "mcp-serve"} {
+		if listed[name] {
+			t.Errorf("%s must not be exposed as a tool", name)
+		}
+	}
+	for _, name := range []string{"which", "explain", "doctor", "list-all"} {
+		if !listed[name] {
+			t.Errorf("%s tool not listed", name)
+		}
+	}
+	schema := note["inputSchema"].(map[string]any)
+	if name := schema["properties"].(map[string]any)["Name"].(map[string]any); name["description"] != "Note name" {
+		t.Errorf("variable metadata missing from schema: %v", name)
+	}
+	// Declared args carry their choices and default, and only required ones are required
+	if tag := schema["properties"].(map[string]any)["Tag"].(map[string]any); tag["default"] != "idea" || len(tag["enum"].([]any)) != 2 {
+		t.Errorf("declared arg metadata missing from schema: %v", tag)
+	}
+	if required, _ := schema["required"].([]any); len(required) != 1 || required[0] != "Name" {
+		t.Errorf("expected only Name to be required, got %v", schema["required"])
+	}
+
+	dry := responses[3]["result"].(map[string]any)
+	plan := dry["structuredContent"].(map[string]any)["plan"].([]any)
+	if len(plan) != 1 || plan[0].(map[string]any)["path"] != "notes/shopping-list.md" {
+		t.Errorf("unexpected plan: %v", plan)
+	}
+	if _, ran := dry["structuredContent"].(map[string]any)["createdFiles"]; ran {
+		t.Error("dry run wrote files")
+	}
+	if missing := responses[4]["result"].(map[string]any); missing["isError"] != true || !strings.Contains(fmt.Sprint(missing["content"]), "missing required variables") {
+		t.Errorf("expected missing variables to be a tool error, got %v", missing)
+	}
+	if vars := dry["structuredContent"].(map[string]any)["variables"].(map[string]any); vars["Tag"] != "todo" {
+		t.Errorf("declared arg not passed through: %v", vars)
+	}
+
+	run := responses[5]["result"].(map[string]any)["structuredContent"].(map[string]any)
+	if diff, _ := run["diff"].(string); !strings.Contains(diff, "+# Shopping List") {
+		t.Errorf("unexpected diff: %q", diff)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "notes", "shopping-list.md")); err != nil || string(data) != "# Shopping List\n" {
+		t.Errorf("file not written: %q %v", data, err)
+	}
+	if code := responses[6]["error"].(map[string]any)["code"]; code != float64(rpcMethodNotFound) {
+		t.Errorf("unexpected error code: %v", code)
+	}
+}
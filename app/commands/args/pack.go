@@ -0,0 +1,323 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+)
+
+// PackInstallCommand installs a template pack, or every pack pinned in .nextgen/packs.lock.
+type PackInstallCommand struct{}
+
+// PackListCommand lists the packs installed in the current project.
+type PackListCommand struct{}
+
+// PackRemoveCommand removes an installed pack.
+type PackRemoveCommand struct{}
+
+// PackAddGitCommand installs a pack from a commit of a local git clone.
+type PackAddGitCommand struct{}
+
+// PackUpdateCommand moves installed packs to the latest content of their source.
+type PackUpdateCommand struct{}
+
+// PackVerifyCommand reports installed packs whose contents drifted from the lockfile, and
+// pack folders that cannot be verified.
+type PackVerifyCommand struct{}
+
+func init() {
+	RegisterCommand(&PackInstallCommand{})
+	RegisterCommand(&PackListCommand{})
+	RegisterCommand(&PackRemoveCommand{})
+	RegisterCommand(&PackAddGitCommand{})
+	RegisterCommand(&PackUpdateCommand{})
+	RegisterCommand(&PackVerifyCommand{})
+}
+
+// packProjectPath returns the working directory that packs are installed into.
+func packProjectPath() (string, error) {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("could not get current directory: %w", err)
+	}
+	return projectPath, nil
+}
+
+func (c *PackInstallCommand) Name() string { return "pack install" }
+
+func (c *PackInstallCommand) Description() string {
+	return "Installs a template pack from a directory or tarball (no argument: install everything in .nextgen/packs.lock)."
+}
+
+func (c *PackInstallCommand) Usage() string { return "[path|tarball]" }
+
+func (c *PackInstallCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "source", Description: "Pack directory or .tar.gz/.tgz/.tar archive containing pack.json", Required: false}}
+}
+
+func (c *PackInstallCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *PackInstallCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	if len(args.Variables) == 0 {
+		installed, err := commands_pkg.InstallPacksFromLock(projectPath)
+		for _, m := range installed {
+			fmt.Printf("✓ Installed %s@%s\n", m.Name, m.Version)
+		}
+		if err != nil {
+			return err
+		}
+		if len(installed) == 0 {
+			fmt.Println("No packs pinned in .nextgen/packs.lock.")
+		}
+		return nil
+	}
+	for _, source := range args.Variables {
+		m, err := commands_pkg.InstallPack(projectPath, cli.UserPath(source))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Installed %s@%s from %s\n", m.Name, m.Version, source)
+		if len(m.Identifiers) > 0 {
+			fmt.Printf("  Identifiers recorded in .nextgen/command-packages.json: %s\n", strings.Join(m.Identifiers, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *PackListCommand) Name() string { return "pack list" }
+
+func (c *PackListCommand) Description() string {
+	return "Lists template packs installed in the current project."
+}
+
+func (c *PackListCommand) Usage() string { return "" }
+
+func (c *PackListCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *PackListCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *PackListCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	packs, err := commands_pkg.ListInstalledPacks(projectPath)
+	if err != nil {
+		return err
+	}
+	if len(packs) == 0 {
+		fmt.Println("No packs installed. Use `ng pack install <path|tarball>` to add one.")
+		return nil
+	}
+	fmt.Println("Installed Packs:")
+	for _, p := range packs {
+		line := fmt.Sprintf("  - %s@%s", p.Manifest.Name, p.Manifest.Version)
+		if p.Manifest.Description != "" {
+			line += " — " + p.Manifest.Description
+		}
+		fmt.Println(line)
+		if g := p.Lock.Git; g != nil {
+			fmt.Printf("    source: %s (ref %s @ %s)\n", g.Repo, g.Ref, shortCommit(g.Commit))
+		} else {
+			fmt.Printf("    source: %s\n", p.Lock.Source)
+		}
+	}
+	return nil
+}
+
+func (c *PackRemoveCommand) Name() string { return "pack remove" }
+
+func (c *PackRemoveCommand) Description() string {
+	return "Removes an installed template pack and its lockfile entry."
+}
+
+func (c *PackRemoveCommand) Usage() string { return "<name>" }
+
+func (c *PackRemoveCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "name", Description: "Name of the installed pack", Required: true}}
+}
+
+func (c *PackRemoveCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *PackRemoveCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	if len(args.Variables) == 0 {
+		return fmt.Errorf("missing pack name")
+	}
+	name := args.Variables[0]
+	if err := commands_pkg.RemovePack(projectPath, name); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Removed %s\n", name)
+	return nil
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func (c *PackAddGitCommand) Name() string { return "pack add-git" }
+
+func (c *PackAddGitCommand) Description() string {
+	return "Installs a template pack from a local git clone, pinned to the commit of --ref (default HEAD)."
+}
+
+func (c *PackAddGitCommand) Usage() string {
+	return "<path-to-local-clone> [--ref <ref>] [--path <dir>]"
+}
+
+func (c *PackAddGitCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "repo", Description: "Path to a local git clone containing pack.json", Required: true}}
+}
+
+func (c *PackAddGitCommand) ExpectedFlags() []FlagDef {
+	return []FlagDef{
+		{Name: "ref", Description: "Branch, tag or commit to read the pack from (default HEAD)", HasValue: true},
+		{Name: "path", Description: "Pack folder inside the repository, if not the root", HasValue: true},
+	}
+}
+
+func (c *PackAddGitCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	if len(args.Variables) == 0 {
+		return fmt.Errorf("missing repository path")
+	}
+	m, err := commands_pkg.AddGitPack(projectPath, cli.UserPath(args.Variables[0]), args.Flags["ref"], args.Flags["path"])
+	if err != nil {
+		return err
+	}
+	lock, _ := commands_pkg.LoadPackLock(projectPath)
+	commit := ""
+	if entry, ok := lock.Packs[m.Name]; ok && entry.Git != nil {
+		commit = shortCommit(entry.Git.Commit)
+	}
+	fmt.Printf("✓ Installed %s@%s from %s @ %s\n", m.Name, m.Version, args.Variables[0], commit)
+	return nil
+}
+
+func (c *PackUpdateCommand) Name() string { return "pack update" }
+
+func (c *PackUpdateCommand) Description() string {
+	return "Updates installed packs from their source and summarizes changed commands."
+}
+
+func (c *PackUpdateCommand) Usage() string { return "[name...] [--ref <ref>]" }
+
+func (c *PackUpdateCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "name...", Description: "Packs to update (default: all)", Required: false}}
+}
+
+func (c *PackUpdateCommand) ExpectedFlags() []FlagDef {
+	return []FlagDef{{Name: "ref", Description: "Switch git packs to a different ref before updating", HasValue: true}}
+}
+
+func (c *PackUpdateCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	updates, err := commands_pkg.UpdatePacks(projectPath, args.Variables, args.Flags["ref"])
+	for _, u := range updates {
+		if u.UpToDate {
+			fmt.Printf("= %s@%s is up to date\n", u.Name, u.NewVersion)
+			continue
+		}
+		header := fmt.Sprintf("↑ %s %s → %s", u.Name, u.OldVersion, u.NewVersion)
+		if u.NewCommit != "" {
+			header += fmt.Sprintf(" (%s → %s)", shortCommit(u.OldCommit), shortCommit(u.NewCommit))
+		}
+		fmt.Println(header)
+		for _, a := range u.Added {
+			fmt.Printf("    + %s\n", a)
+		}
+		for _, ch := range u.Changed {
+			fmt.Printf("    ~ %s\n", ch)
+		}
+		for _, r := range u.Removed {
+			fmt.Printf("    - %s\n", r)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if len(updates) == 0 {
+		fmt.Println("No packs installed.")
+	}
+	return nil
+}
+
+func (c *PackVerifyCommand) Name() string { return "pack verify" }
+
+func (c *PackVerifyCommand) Description() string {
+	return "Checks installed packs against the digests in .nextgen/packs.lock, their manifest and signature."
+}
+
+func (c *PackVerifyCommand) Usage() string { return "[name...]" }
+
+func (c *PackVerifyCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "name...", Description: "Packs to verify (default: all)", Required: false}}
+}
+
+func (c *PackVerifyCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *PackVerifyCommand) Execute(args cli.CommandArgs) error {
+	projectPath, err := packProjectPath()
+	if err != nil {
+		return err
+	}
+	results, err := commands_pkg.VerifyPacks(projectPath, args.Variables)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("No packs installed.")
+		return nil
+	}
+	drifted := 0
+	for _, v := range results {
+		if !v.Drifted() {
+			line := fmt.Sprintf("✓ %s@%s", v.Name, v.Version)
+			if v.SignedBy != "" {
+				line += " (signed by " + v.SignedBy + ")"
+			}
+			fmt.Println(line)
+			continue
+		}
+		drifted++
+		fmt.Printf("✗ %s@%s\n", v.Name, v.Version)
+		for _, f := range v.Modified {
+			fmt.Printf("    ~ %s (modified)\n", f)
+		}
+		for _, f := range v.Missing {
+			fmt.Printf("    - %s (missing)\n", f)
+		}
+		for _, f := range v.Unexpected {
+			fmt.Printf("    + %s (not in lockfile)\n", f)
+		}
+		if v.Problem != "" {
+			fmt.Printf("    ! %s\n", v.Problem)
+		}
+	}
+	if drifted > 0 {
+		return fmt.Errorf("%d pack(s) failed verification against %s or their manifest; reinstall with `ng pack install`", drifted, filepath.Base(commands_pkg.PacksLockPath(projectPath)))
+	}
+	return nil
+}
@@ -4,10 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
-	"time"
 
 	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
 	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
@@ -44,6 +42,11 @@ type Command interface {
 // We use a map for quick lookup by command name.
 var commandRegistry = make(map[string]Command)
 
+func init() {
+	// Let the command resolver recognize args commands
+	commands_pkg.SetArgsCommandLookup(CommandExists)
+}
+
 // RegisterCommand adds a command to the registry.
 // It should ideally be called during initialization (e.g., in an init() function
 // within each command's file).
@@ -112,6 +115,10 @@ func BuildAllAvailableCommandNames(projectPath string, registry *project.Project
 			for _, e := range entries {
 				if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
 					name := strings.TrimSuffix(e.Name(), ".json")
+					// Overrides are listed under the name of the command they shadow
+					if _, isOverride := commands_pkg.OverrideForLocalFile(name); isOverride {
+						continue
+					}
 					if !added[name] {
 						all = append(all, name)
 						added[name] = true
@@ -144,145 +151,3 @@ func BuildAllAvailableCommandNames(projectPath string, registry *project.Project
 	sort.Strings(all)
 	return all
 }
-
-var ansiRegexp = regexp.MustCompile("\u001B\\[[0-9;?]*[ -/]*[@-~]")
-
-func stripANSI(s string) string { return ansiRegexp.ReplaceAllString(s, "") }
-
-// WriteNextgenCommandsMDC writes the auto-generated list to .nextgen/nextgen-cli-commands.mdc
-func WriteNextgenCommandsMDC(projectPath string, registry *project.ProjectRegistry) error {
-	names := BuildAllAvailableCommandNames(projectPath, registry)
-
-	// Ensure folder exists
-	targetDir := filepath.Join(projectPath, ".nextgen")
-	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return fmt.Errorf("failed to create .nextgen directory: %w", err)
-	}
-	targetFile := filepath.Join(targetDir, "nextgen-cli-commands.mdc")
-
-	var b strings.Builder
-	b.WriteString("---\n")
-	b.WriteString("description: Auto-generated list of NextGen CLI commands\n")
-	b.WriteString("globs:\n")
-	b.WriteString("alwaysApply: false\n")
-	b.WriteString("---\n\n")
-	b.WriteString("### All Commands\n")
-	b.WriteString(fmt.Sprintf("Updated: %s UTC\n\n", time.Now().UTC().Format(time.RFC3339)))
-	for _, n := range names {
-		b.WriteString("- ")
-		b.WriteString(n)
-		b.WriteString("\n")
-	}
-
-	// Include file tree previews similar to UI preview logic
-	b.WriteString("\n### File Tree Previews\n\n")
-	for _, name := range names {
-		preview := ""
-		// Determine placeholders via key inference
-		keys, _ := commands_pkg.GetCommandVariableKeys(name, projectPath, registry)
-		var placeholderMap map[string]string
-		if len(keys) > 0 {
-			// Use the first key as the primary placeholder for preview
-			placeholders := map[string]string{keys[0]: "<" + keys[0] + ">"}
-			placeholderMap = commands_pkg.BuildPlaceholders(placeholders)
-		} else {
-			// Fallback if no keys found
-			placeholderMap = commands_pkg.BuildAutoPlaceholders(map[string]string{"Main": "<Filename>"})
-		}
-
-		// Clipboard template
-		if registry != nil {
-			if clipSpec, ok := registry.ClipboardCommands[name]; ok {
-				if pv, err := commands_pkg.GeneratePreviewFileTreeFromBytes([]byte(clipSpec.Template), placeholderMap, projectPath); err == nil && strings.TrimSpace(pv) != "" {
-					preview = pv
-				}
-			}
-		}
-
-		// Project-local template
-		if preview == "" && projectPath != "" {
-			kebab := commands_pkg.ToKebabCase(name)
-			localPath := filepath.Join(projectPath, ".nextgen", "local-commands", kebab+".json")
-			if data, readErr := os.ReadFile(localPath); readErr == nil {
-				if pv, err := commands_pkg.GeneratePreviewFileTreeFromBytes(data, placeholderMap, projectPath); err == nil && strings.TrimSpace(pv) != "" {
-					preview = pv
-				}
-			}
-		}
-
-		// Built-in template
-		if preview == "" {
-			if pv, err := commands_pkg.GeneratePreviewFileTree(name, placeholderMap, projectPath); err == nil && strings.TrimSpace(pv) != "" {
-				preview = pv
-			}
-		}
-
-		b.WriteString("#### ")
-		b.WriteString(name)
-		b.WriteString("\n\n")
-
-		// Usage details
-		if len(keys) > 0 {
-			b.WriteString("- Usage (name): ")
-			b.WriteString("`ng ")
-			b.WriteString(name)
-			for _, k := range keys {
-				b.WriteString(" <")
-				b.WriteString(k)
-				b.WriteString(">")
-			}
-			b.WriteString("` | `nextgen ")
-			b.WriteString(name)
-			for _, k := range keys {
-				b.WriteString(" <")
-				b.WriteString(k)
-				b.WriteString(">")
-			}
-			b.WriteString("`\n")
-			// Slug variant if available
-			if spec := commands_pkg.GetCommandSpec(name); spec.Slug != "" {
-				b.WriteString("- Usage (slug): ")
-				b.WriteString("`ng ")
-				b.WriteString(spec.Slug)
-				for _, k := range keys {
-					b.WriteString(" <")
-					b.WriteString(k)
-					b.WriteString(">")
-				}
-				b.WriteString("` | `nextgen ")
-				b.WriteString(spec.Slug)
-				for _, k := range keys {
-					b.WriteString(" <")
-					b.WriteString(k)
-					b.WriteString(">")
-				}
-				b.WriteString("`\n")
-			}
-			b.WriteString("- Variables: ")
-			b.WriteString(strings.Join(keys, ", "))
-			b.WriteString("\n\n")
-		} else {
-			// Arg-based or no variables
-			b.WriteString("- Usage: ")
-			b.WriteString("`ng ")
-			b.WriteString(name)
-			b.WriteString("` | `nextgen ")
-			b.WriteString(name)
-			b.WriteString("`\n\n")
-		}
-
-		clean := stripANSI(preview)
-		if strings.TrimSpace(clean) == "" {
-			b.WriteString("(No file changes preview available)\n\n")
-		} else {
-			b.WriteString("```text\n")
-			b.WriteString(clean)
-			b.WriteString("\n```\n\n")
-		}
-	}
-
-	if err := os.WriteFile(targetFile, []byte(b.String()), 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", targetFile, err)
-	}
-	return nil
-}
@@ -0,0 +1,151 @@
+package args
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+)
+
+// -----------------------------------------------------------------------------
+// [SUGGEST] "Did you mean" suggestions for unknown command names
+// -----------------------------------------------------------------------------
+
+// maxSuggestions is how many close matches are suggested for an unknown command.
+const maxSuggestions = 3
+
+// suggestionCandidate is a runnable command with the kebab-case forms it is matched by.
+type suggestionCandidate struct {
+	name  string   // what the user types to run it
+	forms []string // kebab-case name and slug
+}
+
+// suggestionCandidates lists every command the user can run in the project: visible
+// built-in templates (by slug), args commands, and project, clipboard and native commands.
+func suggestionCandidates(projectPath string, registry *project.ProjectRegistry) []suggestionCandidate {
+	var out []suggestionCandidate
+	seen := map[string]bool{}
+	add := func(name string, forms ...string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		c := suggestionCandidate{name: name}
+		for _, f := range append(forms, name) {
+			c.forms = append(c.forms, commands_pkg.ToKebabCase(f))
+		}
+		out = append(out, c)
+	}
+	for _, spec := range commands_pkg.RegisteredCommands() {
+		if commands_pkg.IsCommandVisible(spec, projectPath) {
+			add(spec.Slug, spec.Name)
+		}
+		// Built-ins are only run by slug, and hidden ones not at all
+		seen[spec.Name] = true
+	}
+	for _, name := range BuildAllAvailableCommandNames(projectPath, registry) {
+		add(name)
+	}
+	return out
+}
+
+// SuggestCommands returns up to maxSuggestions commands whose names are close to the words
+// the user typed. Leading runs of words are tried too, since the rest may be variables.
+func SuggestCommands(words []string, projectPath string, registry *project.ProjectRegistry) []string {
+	best := map[string]int{}
+	for _, c := range suggestionCandidates(projectPath, registry) {
+		for n := 1; n <= len(words); n++ {
+			input := commands_pkg.ToKebabCase(strings.Join(words[:n], " "))
+			for _, form := range c.forms {
+				d := suggestionDistance(input, form)
+				if d < 0 {
+					continue
+				}
+				if prev, ok := best[c.name]; !ok || d < prev {
+					best[c.name] = d
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(best))
+	for name := range best {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if best[names[i]] != best[names[j]] {
+			return best[names[i]] < best[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names[:min(len(names), maxSuggestions)]
+}
+
+// PrefixCommandMatch returns the single command that the leading words are a prefix of
+// (e.g. "add-page" for "add-page-type-with-pagebuilder"), with the number of words used.
+// Longer runs of words are tried first; ambiguous prefixes match nothing.
+func PrefixCommandMatch(words []string, projectPath string, registry *project.ProjectRegistry) (string, int, bool) {
+	candidates := suggestionCandidates(projectPath, registry)
+	for n := len(words); n > 0; n-- {
+		input := commands_pkg.ToKebabCase(strings.Join(words[:n], " "))
+		if len(input) < 3 {
+			continue
+		}
+		var matches []string
+		for _, c := range candidates {
+			for _, form := range c.forms {
+				if strings.HasPrefix(form, input) {
+					matches = append(matches, c.name)
+					break
+				}
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], n, true
+		}
+		if len(matches) > 1 {
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
+
+// suggestionDistance scores how close input is to a command form (lower is closer), or
+// returns -1 when they are too different to suggest. Forms containing the input score as
+// a single edit.
+func suggestionDistance(input, form string) int {
+	if input == "" {
+		return -1
+	}
+	if strings.Contains(form, input) && len(input) >= 3 {
+		return 1
+	}
+	d := levenshtein(input, form)
+	if d > max(2, len(input)/3) {
+		return -1
+	}
+	return d
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
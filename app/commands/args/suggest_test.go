@@ -0,0 +1,60 @@
+package args
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// suggestProject returns a project with the local commands add-page-type-with-pagebuilder
+// and add-block.
+func suggestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	local := filepath.Join(dir, ".nextgen", "local-commands")
+	if err := os.MkdirAll(local, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := `{"filePaths": [{"path": "out", "nodes": [{"name": "{{.Name}}.md", "code": "x"}]}]}`
+	for _, name := range []string{"add-page-type-with-pagebuilder", "add-block"} {
+		if err := os.WriteFile(filepath.Join(local, name+".json"), []byte(tmpl), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// TestSuggestCommands tests "did you mean" suggestions for misspelled command names.
+func TestSuggestCommands(t *testing.T) {
+	dir := suggestProject(t)
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"add-blok"}, "add-block"},
+		{[]string{"add", "block", "Hero"}, "add-block"},
+		{[]string{"pagebuilder"}, "add-page-type-with-pagebuilder"},
+		{[]string{"docs", "generat"}, "docs generate"},
+	}
+	for _, tt := range tests {
+		if got := SuggestCommands(tt.words, dir, nil); !slices.Contains(got, tt.want) {
+			t.Errorf("SuggestCommands(%q) = %q, want it to contain %q", tt.words, got, tt.want)
+		}
+	}
+	if got := SuggestCommands([]string{"zzzzzzzzzz"}, dir, nil); len(got) != 0 {
+		t.Errorf("SuggestCommands(zzzzzzzzzz) = %q, want none", got)
+	}
+}
+
+// TestPrefixCommandMatch tests resolving unambiguous command name prefixes.
+func TestPrefixCommandMatch(t *testing.T) {
+	dir := suggestProject(t)
+	match, consumed, ok := PrefixCommandMatch([]string{"add-page", "Article"}, dir, nil)
+	if !ok || match != "add-page-type-with-pagebuilder" || consumed != 1 {
+		t.Errorf("PrefixCommandMatch(add-page Article) = %q, %d, %v", match, consumed, ok)
+	}
+	if _, _, ok := PrefixCommandMatch([]string{"add"}, dir, nil); ok {
+		t.Error("PrefixCommandMatch(add) matched, want ambiguous")
+	}
+}
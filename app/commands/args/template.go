@@ -0,0 +1,45 @@
+package args
+
+import (
+	"fmt"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+)
+
+// TemplateDiffBuiltinCommand shows how a project override differs from the built-in template.
+type TemplateDiffBuiltinCommand struct{}
+
+func init() {
+	RegisterCommand(&TemplateDiffBuiltinCommand{})
+}
+
+func (c *TemplateDiffBuiltinCommand) Name() string { return "template diff-builtin" }
+
+func (c *TemplateDiffBuiltinCommand) Description() string {
+	return "Shows a diff from a built-in command's template to the project override in .nextgen/local-commands."
+}
+
+func (c *TemplateDiffBuiltinCommand) Usage() string { return "<slug>" }
+
+func (c *TemplateDiffBuiltinCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "slug", Description: "Slug (or name) of the overridden command", Required: true}}
+}
+
+func (c *TemplateDiffBuiltinCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *TemplateDiffBuiltinCommand) Execute(args cli.CommandArgs) error {
+	if len(args.Variables) == 0 {
+		return fmt.Errorf("missing command slug")
+	}
+	diff, err := commands_pkg.DiffBuiltin(args.Variables[0])
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Println("The override is identical to the built-in template.")
+		return nil
+	}
+	fmt.Print(diff)
+	return nil
+}
@@ -0,0 +1,92 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	commands_pkg "github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// WhichCommand explains which source a command name resolves to.
+type WhichCommand struct{}
+
+func init() {
+	RegisterCommand(&WhichCommand{})
+}
+
+func (c *WhichCommand) Name() string { return "which" }
+
+func (c *WhichCommand) Description() string {
+	return "Shows what a command name runs, and which lower-precedence commands it shadows."
+}
+
+func (c *WhichCommand) Usage() string { return "<command...>" }
+
+func (c *WhichCommand) ExpectedArgs() []ArgDef {
+	return []ArgDef{{Name: "command...", Description: "Command name or slug (multiple words are joined)", Required: true}}
+}
+
+func (c *WhichCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *WhichCommand) Execute(args cli.CommandArgs) error {
+	if len(args.Variables) == 0 {
+		return fmt.Errorf("missing command name")
+	}
+	name := strings.Join(args.Variables, " ")
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
+		registry = nil
+	}
+
+	order := make([]string, len(commands_pkg.ResolutionOrder))
+	for i, s := range commands_pkg.ResolutionOrder {
+		order[i] = string(s)
+	}
+	candidates, err := commands_pkg.NewResolver(projectPath, registry).Candidates(name)
+	if err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("no command named %q (looked in: %s)", name, strings.Join(order, " → "))
+	}
+
+	winner := candidates[0]
+	if cli.IsJSONOutput() {
+		shadows := make([]string, 0, len(candidates)-1)
+		for _, shadowed := range candidates[1:] {
+			shadows = append(shadows, shadowed.Describe())
+		}
+		cli.SetResultData(map[string]any{
+			"name":    name,
+			"runs":    winner.Describe(),
+			"kind":    winner.Kind,
+			"source":  winner.Source,
+			"slug":    winner.Spec.Slug,
+			"shadows": shadows,
+			"order":   order,
+		})
+		return nil
+	}
+	fmt.Printf("%s\n", name)
+	fmt.Printf("  runs:   %s\n", winner.Describe())
+	fmt.Printf("  kind:   %s\n", winner.Kind)
+	fmt.Printf("  source: %s\n", winner.Source)
+	if winner.Spec.Slug != "" {
+		fmt.Printf("  slug:   %s\n", winner.Spec.Slug)
+	}
+	if len(candidates) > 1 {
+		fmt.Println("  shadows:")
+		for _, shadowed := range candidates[1:] {
+			fmt.Printf("    - %s (%s)\n", shadowed.Describe(), shadowed.Kind)
+		}
+	}
+	fmt.Printf("  order:  %s\n", strings.Join(order, " → "))
+	return nil
+}
@@ -0,0 +1,87 @@
+package args
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// WorkspacesCommand lists the packages of the monorepo the current directory is in.
+type WorkspacesCommand struct{}
+
+func init() {
+	RegisterCommand(&WorkspacesCommand{})
+}
+
+func (c *WorkspacesCommand) Name() string { return "workspaces" }
+
+func (c *WorkspacesCommand) Description() string {
+	return "Lists the packages of the current monorepo. Run any command in one with --workspace <name>."
+}
+
+func (c *WorkspacesCommand) Usage() string { return "" }
+
+func (c *WorkspacesCommand) ExpectedArgs() []ArgDef { return []ArgDef{} }
+
+func (c *WorkspacesCommand) ExpectedFlags() []FlagDef { return []FlagDef{} }
+
+func (c *WorkspacesCommand) Execute(args cli.CommandArgs) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	mono, ok := project.DetectMonorepo(cwd)
+	if !ok {
+		return fmt.Errorf("%s is not in a monorepo (no %s found)", cwd, strings.Join(project.MonorepoMarkers, ", "))
+	}
+	current, _ := mono.Containing(cwd)
+
+	type workspaceData struct {
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Type    string `json:"type,omitempty"`
+		Current bool   `json:"current,omitempty"`
+	}
+	list := make([]workspaceData, 0, len(mono.Workspaces))
+	for _, ws := range mono.Workspaces {
+		data := workspaceData{Name: ws.Name, Path: ws.RelPath, Current: ws.Path == current.Path}
+		if info, found := project.DetectProject(ws.Path); found && info.RootPath == ws.Path {
+			data.Type = info.Type
+		}
+		list = append(list, data)
+	}
+
+	if cli.IsJSONOutput() {
+		cli.SetResultData(map[string]any{
+			"root":       mono.Root,
+			"markers":    mono.Markers,
+			"patterns":   mono.Patterns,
+			"workspaces": list,
+		})
+		return nil
+	}
+	fmt.Printf("%s (%s)\n", mono.Root, strings.Join(mono.Markers, ", "))
+	if len(list) == 0 {
+		fmt.Printf("  no packages match %s\n", strings.Join(mono.Patterns, ", "))
+		return nil
+	}
+	nameWidth, pathWidth := 0, 0
+	for _, ws := range list {
+		nameWidth, pathWidth = max(nameWidth, len(ws.Name)), max(pathWidth, len(ws.Path))
+	}
+	for _, ws := range list {
+		marker := " "
+		if ws.Current {
+			marker = "*"
+		}
+		line := fmt.Sprintf("%s %-*s  %-*s", marker, nameWidth, ws.Name, pathWidth, ws.Path)
+		if ws.Type != "" {
+			line += "  (" + ws.Type + ")"
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
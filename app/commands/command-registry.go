@@ -3,10 +3,10 @@ package commands
 import (
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -53,116 +53,28 @@ func splitCamelWordsToken(s string) []string {
 // normalizeBaseVarName strips recognized transform prefixes (case-insensitive, separators allowed)
 // and returns a canonical PascalCase base variable name (e.g., "ComponentName").
 func normalizeBaseVarName(identifier string) string {
-	id := strings.TrimSpace(identifier)
-	if id == "" {
-		return ""
-	}
-	lowerCompacted := strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(id), "-", ""), "_", "")
-	// Ordered by specificity
-	prefixes := []string{
-		"screamingsnakecase", "screamingsnake",
-		"snakecase", "snake",
-		"kebabcase", "kebab",
-		"pascalcase", "pascal",
-		"camelcase", "camel",
-		"uppercase", "upper",
-		"lowercase", "lower",
-	}
-	cutIndex := 0
-	for _, p := range prefixes {
-		if strings.HasPrefix(lowerCompacted, p) {
-			cutIndex = len(p)
-			break
-		}
-	}
-	remainder := id
-	if cutIndex > 0 {
-		// Walk the original string to skip cutIndex non-separator chars
-		count := 0
-		pos := 0
-		for pos < len(id) && count < cutIndex {
-			c := id[pos]
-			if c != '-' && c != '_' {
-				count++
-			}
-			pos++
-		}
-		remainder = id[pos:]
-		// Skip one optional separator
-		if strings.HasPrefix(remainder, "-") || strings.HasPrefix(remainder, "_") {
-			remainder = remainder[1:]
-		}
-	}
-	rem := strings.TrimSpace(remainder)
-	if rem == "" {
-		return ""
-	}
-	// Turn separators into spaces; if still single token, attempt camel split
-	remSpaced := strings.NewReplacer("-", " ", "_", " ").Replace(rem)
-	words := strings.Fields(remSpaced)
-	if len(words) <= 1 {
-		if cw := splitCamelWordsToken(rem); len(cw) > 1 {
-			words = cw
-		}
-	}
-	if len(words) == 0 {
-		return ToPascalCase(rem)
-	}
-	return ToPascalCase(strings.Join(words, " "))
+	_, base := splitPlaceholderIdentifier(identifier)
+	return base
 }
 
 func init() {
-	// Walk the embedded FS and store each .json file in our registry map
-	// Also collect discovered JSON paths for synthesizing folder-level commands
-	discovered := []string{}
-	err := fs.WalkDir(commandFiles, ".", func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if !d.IsDir() && filepath.Ext(d.Name()) == ".json" {
-			data, readErr := commandFiles.ReadFile(path)
-			if readErr != nil {
-				return fmt.Errorf("could not read file %s: %w", path, readErr)
-			}
-			// Store file contents in the registry, keyed by filename (like "page-and-archive.json")
-			templateRegistry[path] = data
-			discovered = append(discovered, path)
-			// Populate Commands dynamically from embedded files
-			type minimal struct {
-				Title     string             `json:"title"`
-				Name      string             `json:"name"`
-				Slug      string             `json:"slug"`
-				Show      *CommandVisibility `json:"show"`
-				FilePaths []any              `json:"filePaths"`
-				Run       []any              `json:"run"`
-			}
-			var m minimal
-			_ = json.Unmarshal(data, &m) // ignore parse error for title extraction; fallback to filename
-			name := strings.TrimSpace(m.Title)
-			if name == "" {
-				base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
-				name = strings.ReplaceAll(base, "-", " ")
-			}
-			lower := strings.ToLower(name)
-			if !strings.HasPrefix(lower, "add ") && !strings.HasPrefix(lower, "remove ") {
-				name = "add " + name
-			}
-			// Determine slug: prefer explicit JSON `slug`, else fall back to filename base
-			slug := strings.TrimSpace(m.Slug)
-			if slug == "" {
-				slug = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
-			}
-			// Only register runnable commands (have filePaths or run)
-			if len(m.FilePaths) > 0 || len(m.Run) > 0 {
-				Commands = append(Commands, CommandSpec{Name: name, Slug: slug, TemplatePath: path, Visibility: m.Show})
-			}
-		}
-		return nil
-	})
-
+	// Walk the embedded FS and the user template directory and store each .json file in
+	// our registry map. Also collect discovered JSON paths for synthesizing folder-level commands
+	discovered, err := scanTemplateSource(commandFiles, nativeTemplatesRoot, nativeTemplatesRoot)
 	if err != nil {
 		log.Fatalf("Failed to init command registry: %v", err)
 	}
+	discovered = append(discovered, scanUserTemplates()...)
+
+	registerTemplates(discovered)
+}
+
+// registerTemplates registers commands for the given registry keys and synthesizes
+// folder-level bundle commands for <root>/<category>/<bundle> folders. Templates are only
+// queued here; they are parsed when the command list is first read (see
+// RegisteredCommands).
+func registerTemplates(discovered []string) {
+	pendingTemplates = append(pendingTemplates, discovered...)
 
 	// Synthesize folder-level commands for native-commands/<category>/<bundle>
 	dirsAdded := map[string]bool{}
@@ -170,11 +82,13 @@ func init() {
 	categoriesAdded := map[string]bool{}
 	for _, p := range discovered {
 		// Expect paths like native-commands/<category>/<bundle>/.../file.json
-		parts := strings.Split(p, "/")
-		if len(parts) < 4 {
+		// (or user-templates/... and packs/<pack>/...); the root counts as one segment
+		root, rel := splitTemplateRoot(p)
+		if root == "" {
 			continue
 		}
-		if parts[0] != "native-commands" {
+		parts := append([]string{root}, strings.Split(rel, "/")...)
+		if len(parts) < 4 {
 			continue
 		}
 
@@ -201,6 +115,10 @@ func init() {
 		slug := category + "-" + bundle
 		tmpl := fmt.Sprintf(`{"_type":"command","title":"%s","slug":"%s","autoBrowseRoot":"%s"}`, strings.Title(title), slug, folderKey)
 		key := "auto/" + slug + ".json"
+		if _, exists := templateRegistry[key]; exists {
+			// Another source (or an earlier load of the same pack) already provides this bundle
+			continue
+		}
 		templateRegistry[key] = []byte(tmpl)
 
 		// Restrict visibility of bundle wrappers to projects that declare the category
@@ -222,6 +140,59 @@ func init() {
 	sort.Slice(Commands, func(i, j int) bool { return Commands[i].Name < Commands[j].Name })
 }
 
+// loadPendingCommands parses the templates queued by registerTemplates, materializing
+// "extends" first so a variant inherits its base's title and files, and adds the runnable
+// ones to Commands.
+func loadPendingCommands() {
+	if len(pendingTemplates) == 0 {
+		return
+	}
+	pending := pendingTemplates
+	pendingTemplates = nil
+	loaded := map[string]bool{}
+	for _, c := range Commands {
+		loaded[c.TemplatePath] = true
+	}
+	for _, path := range pending {
+		if _, ok := templateRegistry[path]; !ok || loaded[path] {
+			continue
+		}
+		loaded[path] = true
+		data, loadErr := LoadCommandTemplate(path)
+		if loadErr != nil {
+			log.Printf("Skipping template %s: %v", path, loadErr)
+			continue
+		}
+		m := cachedTemplate(path, data).Header() // parse errors leave it empty; fallback to filename
+		name := strings.TrimSpace(m.Title)
+		if name == "" {
+			base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+			name = strings.ReplaceAll(base, "-", " ")
+		}
+		lower := strings.ToLower(name)
+		if !strings.HasPrefix(lower, "add ") && !strings.HasPrefix(lower, "remove ") {
+			name = "add " + name
+		}
+		// Determine slug: prefer explicit JSON `slug`, else fall back to filename base
+		slug := strings.TrimSpace(m.Slug)
+		if slug == "" {
+			slug = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		// Only register runnable commands (have filePaths or run)
+		if m.HasFilePaths || m.HasRun {
+			Commands = append(Commands, CommandSpec{Name: name, Slug: slug, TemplatePath: path, Visibility: m.Show})
+		}
+	}
+	sort.Slice(Commands, func(i, j int) bool { return Commands[i].Name < Commands[j].Name })
+}
+
+// RegisteredCommands returns every registered command, parsing templates registered since
+// the last call first.
+func RegisteredCommands() []CommandSpec {
+	loadPendingCommands()
+	return Commands
+}
+
 // FSChild represents a child entry in the embedded native-commands tree.
 type FSChild struct {
 	Name  string
@@ -229,10 +200,11 @@ type FSChild struct {
 	IsDir bool
 }
 
-// ListNativeChildren lists directories and .json files under the given embedded prefix path.
+// ListNativeChildren lists directories and .json files under the given template tree prefix.
 // Example prefix: "native-commands/nextjs/add-index-and-slug".
 func ListNativeChildren(prefix string) ([]FSChild, error) {
-	entries, err := fs.ReadDir(commandFiles, prefix)
+	fsys, dir := templateFS(prefix)
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -250,14 +222,16 @@ func ListNativeChildren(prefix string) ([]FSChild, error) {
 	return out, nil
 }
 
-// ReadEmbeddedTemplate returns the raw bytes of an embedded template path.
+// ReadEmbeddedTemplate returns the raw bytes of an embedded or user template path.
 func ReadEmbeddedTemplate(path string) ([]byte, error) {
-	return commandFiles.ReadFile(path)
+	fsys, name := templateFS(path)
+	return fs.ReadFile(fsys, name)
 }
 
 // FindFirstJSONUnder returns the path to the first JSON file found under prefix (depth-first).
 func FindFirstJSONUnder(prefix string) (string, bool) {
-	entries, err := fs.ReadDir(commandFiles, prefix)
+	fsys, dir := templateFS(prefix)
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return "", false
 	}
@@ -274,9 +248,11 @@ func FindFirstJSONUnder(prefix string) (string, bool) {
 }
 
 // FindCommandByTemplatePath returns the registered CommandSpec for an embedded template path.
+// A built-in path that is overridden by the project yields the overriding command.
 func FindCommandByTemplatePath(path string) (CommandSpec, bool) {
+	loadPendingCommands()
 	for _, c := range Commands {
-		if c.TemplatePath == path {
+		if c.TemplatePath == path || c.BuiltinPath == path {
 			return c, true
 		}
 	}
@@ -285,16 +261,18 @@ func FindCommandByTemplatePath(path string) (CommandSpec, bool) {
 
 // LoadCommandTemplate retrieves the raw JSON template data from memory.
 // The path should match what's stored in templateRegistry (e.g. "app/commands/page-and-archive.json").
+// Templates that extend another template are returned fully materialized.
 func LoadCommandTemplate(path string) ([]byte, error) {
 	data, found := templateRegistry[path]
 	if !found {
 		return nil, fmt.Errorf("template %q not found in registry", path)
 	}
-	return data, nil
+	return resolveTemplateExtends(data, []string{path})
 }
 
 // GetCommandSpec returns the CommandSpec for a given command name.
 func GetCommandSpec(cmdName string) CommandSpec {
+	loadPendingCommands()
 	for _, spec := range Commands {
 		if spec.Name == cmdName {
 			return spec
@@ -318,11 +296,16 @@ type CommandSpec struct {
 	Slug         string
 	TemplatePath string
 	Visibility   *CommandVisibility
+	BuiltinPath  string // template shadowed by a project override (see LoadLocalOverrides)
 }
 
-// Commands is our single authoritative list of all possible commands.
+// Commands is our single authoritative list of all possible commands. Templates still
+// queued in pendingTemplates are missing from it; read it through RegisteredCommands.
 var Commands = []CommandSpec{}
 
+// pendingTemplates holds registry keys of templates registered but not parsed yet.
+var pendingTemplates []string
+
 // RecentUsed & NextSteps remain separate slices, for usage in the UI.
 var RecentUsed = []string{}
 
@@ -347,6 +330,7 @@ func CommandWithIcon(cmd string) string {
 
 // AllCommandNames returns the command names in the order they appear in Commands.
 func AllCommandNames() []string {
+	loadPendingCommands()
 	names := make([]string, len(Commands))
 	for i, c := range Commands {
 		names[i] = c.Name
@@ -357,6 +341,7 @@ func AllCommandNames() []string {
 // TemplatePathFor looks up the first command in Commands with the given name
 // and returns its TemplatePath (plus true if found).
 func TemplatePathFor(cmdName string) (string, bool) {
+	loadPendingCommands()
 	for _, c := range Commands {
 		if c.Name == cmdName {
 			return c.TemplatePath, true
@@ -375,17 +360,13 @@ func TemplatePathFor(cmdName string) (string, bool) {
 // InferVariableKeys scans content for placeholders like {{.VarName}}
 // and returns a unique, sorted list of the base variable names found.
 func InferVariableKeys(content string) []string {
-	matches := placeholderRegex.FindAllStringSubmatch(content, -1)
 	keys := make(map[string]bool)
-	for _, match := range matches {
-		if len(match) > 1 {
-			token := match[1]
-			base := normalizeBaseVarName(token)
-			if base == "" {
-				base = ToPascalCase(strings.NewReplacer("-", " ", "_", " ").Replace(token))
-			}
-			keys[base] = true
+	for _, token := range placeholderIdentifiers(content) {
+		base := normalizeBaseVarName(token)
+		if base == "" {
+			base = ToPascalCase(strings.NewReplacer("-", " ", "_", " ").Replace(token))
 		}
+		keys[base] = true
 	}
 	var uniqueKeys []string
 	for k := range keys {
@@ -394,8 +375,20 @@ func InferVariableKeys(content string) []string {
 	return uniqueKeys
 }
 
+// placeholderFields lists the template JSON fields whose string values are rendered.
+var placeholderFields = map[string]bool{
+	"name": true, "code": true, "path": true,
+	"target": true, "targetStart": true, "targetEnd": true,
+	"content": true, "replacement": true, "requireAbsent": true,
+}
+
 // getTemplateVariableKeysFromBytes parses template bytes and infers variable keys.
-func getTemplateVariableKeysFromBytes(templateBytes []byte) ([]string, error) {
+// Includes and codeFrom references are resolved first so partials contribute their keys.
+func getTemplateVariableKeysFromBytes(templateBytes []byte, projectPath string) ([]string, error) {
+	templateBytes, err := ResolveTemplateIncludes(templateBytes, projectPath)
+	if err != nil {
+		return nil, err
+	}
 	var genericData interface{}
 	if err := json.Unmarshal(templateBytes, &genericData); err != nil {
 		return nil, fmt.Errorf("failed to parse generic template JSON: %w", err)
@@ -408,10 +401,15 @@ func getTemplateVariableKeysFromBytes(templateBytes []byte) ([]string, error) {
 	traverse = func(data interface{}) {
 		switch value := data.(type) {
 		case map[string]interface{}:
+			// Nodes marked rawCode emit their code verbatim, so it carries no variables
+			rawCode, _ := value["rawCode"].(bool)
 			// If it's a map, iterate through its key-value pairs
 			for key, v := range value {
-				// If the key is "name" or "code" and the value is a string, infer keys
-				if key == "name" || key == "code" {
+				if rawCode && key == "code" {
+					continue
+				}
+				// If the key can carry placeholders and the value is a string, infer keys
+				if placeholderFields[key] {
 					if strVal, ok := v.(string); ok {
 						for _, inferredKey := range InferVariableKeys(strVal) {
 							allKeys[inferredKey] = true
@@ -441,52 +439,33 @@ func getTemplateVariableKeysFromBytes(templateBytes []byte) ([]string, error) {
 	return finalKeys, nil
 }
 
+// InferTemplateVariableKeys infers variable keys from template bytes, honouring escapes and
+// rawCode nodes. Content that is not valid JSON is scanned as plain text.
+func InferTemplateVariableKeys(templateBytes []byte, projectPath string) []string {
+	keys, err := cachedTemplate("", templateBytes).VariableKeys(projectPath)
+	if err != nil {
+		return InferVariableKeys(string(templateBytes))
+	}
+	return keys
+}
+
 // GetCommandVariableKeys attempts to determine the required variable keys for a command.
-// It checks clipboard, built-in templates, and local project commands.
+// The command is resolved like every other lookup (see Resolver); commands without a
+// template, and unknown commands, have no keys.
 func GetCommandVariableKeys(cmdName, projectPath string, registry *project.ProjectRegistry) ([]string, error) {
-	// 1. Handle clipboard command
+	// Clipboard content may be a plain-text template rather than JSON
 	if strings.ToLower(cmdName) == "paste from clipboard" {
 		return ExtractVariablesFromClipboard() // Uses helper from command-helpers.go
 	}
-
-	// 2. Check built-in commands
-	spec := GetCommandSpec(cmdName)
-	if spec.TemplatePath != "" {
-		templateBytes, err := LoadCommandTemplate(spec.TemplatePath)
-		if err != nil {
-			return nil, fmt.Errorf("error loading built-in template %s: %w", spec.TemplatePath, err)
-		}
-		return getTemplateVariableKeysFromBytes(templateBytes)
-	}
-	// 2b. If cmdName looks like an embedded template path, try loading directly (auto-browse file)
-	if strings.HasSuffix(strings.ToLower(cmdName), ".json") {
-		if templateBytes, err := LoadCommandTemplate(cmdName); err == nil {
-			return getTemplateVariableKeysFromBytes(templateBytes)
-		}
+	resolved, err := NewResolver(projectPath, registry).Resolve(cmdName)
+	if errors.Is(err, ErrCommandNotFound) {
+		return nil, nil
 	}
-
-	// 3. Check project-local commands
-	if projectPath != "" && projectPath != "." {
-		localCmdDir := filepath.Join(projectPath, ".nextgen", "local-commands")
-		kebabName := ToKebabCase(cmdName)
-		cmdFilePath := filepath.Join(localCmdDir, kebabName+".json")
-		if _, err := os.Stat(cmdFilePath); err == nil {
-			// File exists, read and parse
-			projectCmdBytes, readErr := os.ReadFile(cmdFilePath)
-			if readErr != nil {
-				return nil, fmt.Errorf("error reading project command file %s: %w", cmdFilePath, readErr)
-			}
-			return getTemplateVariableKeysFromBytes(projectCmdBytes)
-		}
+	if err != nil {
+		return nil, err
 	}
-
-	// 4. Check user-saved clipboard commands (if registry available)
-	if registry != nil && registry.ClipboardCommands != nil {
-		if clipSpec, found := registry.ClipboardCommands[cmdName]; found {
-			return getTemplateVariableKeysFromBytes([]byte(clipSpec.Template))
-		}
+	if resolved.Template == nil {
+		return nil, nil
 	}
-
-	// 5. Command not found or doesn't use templates that require variables
-	return nil, nil // Return nil, nil if no keys applicable or command not found
+	return resolved.parsed().VariableKeys(projectPath)
 }
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [DEPENDENCIES] npm packages templates need, installed as a post-step
+// -----------------------------------------------------------------------------
+
+// Templates whose code imports packages declare them next to filePaths:
+//
+//	"dependencies": { "@portabletext/react": "^3" },
+//	"devDependencies": { "@types/lodash": "" }
+//
+// Values are version ranges; an empty one installs the latest version. A package counts as
+// missing when the package.json nearest the project path lists it in neither section.
+
+// TemplateDependency is a package a template declares.
+type TemplateDependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Dev     bool   `json:"dev,omitempty"`
+}
+
+// Spec returns the package as the package manager takes it, e.g. "@portabletext/react@^3".
+func (d TemplateDependency) Spec() string {
+	if v := strings.TrimSpace(d.Version); v != "" && v != "*" && v != "latest" {
+		return d.Name + "@" + v
+	}
+	return d.Name
+}
+
+// TemplateDependencies returns the dependencies then devDependencies of a template, each
+// sorted by name.
+func TemplateDependencies(templateBytes []byte) []TemplateDependency {
+	var t struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(templateBytes, &t) != nil {
+		return nil
+	}
+	var deps []TemplateDependency
+	for _, section := range []struct {
+		deps map[string]string
+		dev  bool
+	}{{t.Dependencies, false}, {t.DevDependencies, true}} {
+		names := make([]string, 0, len(section.deps))
+		for name := range section.deps {
+			if strings.TrimSpace(name) != "" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			deps = append(deps, TemplateDependency{Name: strings.TrimSpace(name), Version: section.deps[name], Dev: section.dev})
+		}
+	}
+	return deps
+}
+
+// packageRoot returns the nearest directory at or above projectPath with a package.json,
+// or projectPath when there is none.
+func packageRoot(projectPath string) string {
+	for dir := projectPath; ; {
+		if _, err := os.Stat(filepath.Join(dir, "package.json")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return projectPath
+		}
+		dir = parent
+	}
+}
+
+// MissingDependencies returns the packages a template declares that the project does not
+// list in its package.json.
+func MissingDependencies(templateBytes []byte, projectPath string) []TemplateDependency {
+	deps := TemplateDependencies(templateBytes)
+	if len(deps) == 0 {
+		return nil
+	}
+	listed := loadProjectContext(packageRoot(projectPath)).Dependencies
+	var missing []TemplateDependency
+	for _, d := range deps {
+		if _, ok := listed[d.Name]; !ok {
+			missing = append(missing, d)
+		}
+	}
+	return missing
+}
+
+// MergeDependencies adds the packages of more to deps that deps does not have yet.
+func MergeDependencies(deps, more []TemplateDependency) []TemplateDependency {
+	for _, d := range more {
+		seen := false
+		for _, have := range deps {
+			seen = seen || have.Name == d.Name
+		}
+		if !seen {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// InstallCommands returns the command lines that install deps with the project's package
+// manager: one for dependencies and one for devDependencies.
+func InstallCommands(projectPath string, deps []TemplateDependency) [][]string {
+	manager := project.DetectPackageManager(packageRoot(projectPath))
+	var lines [][]string
+	for _, dev := range []bool{false, true} {
+		var specs []string
+		for _, d := range deps {
+			if d.Dev == dev {
+				specs = append(specs, d.Spec())
+			}
+		}
+		if len(specs) > 0 {
+			lines = append(lines, project.InstallArgs(manager, specs, dev))
+		}
+	}
+	return lines
+}
+
+// InstallHint returns the install commands of deps joined for display.
+func InstallHint(projectPath string, deps []TemplateDependency) string {
+	var hints []string
+	for _, line := range InstallCommands(projectPath, deps) {
+		hints = append(hints, strings.Join(line, " "))
+	}
+	return strings.Join(hints, " && ")
+}
+
+// InstallDependencies installs deps in the package nearest projectPath, printing the
+// package manager's output.
+func InstallDependencies(projectPath string, deps []TemplateDependency) error {
+	dir := packageRoot(projectPath)
+	if _, err := os.Stat(filepath.Join(dir, "package.json")); err != nil {
+		return fmt.Errorf("no package.json in %s or its parents to install into", projectPath)
+	}
+	for _, line := range InstallCommands(projectPath, deps) {
+		fmt.Printf("Running: %s\n", strings.Join(line, " "))
+		cmd := exec.Command(line[0], line[1:]...)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", strings.Join(line, " "), err)
+		}
+	}
+	return nil
+}
+
+// DependencyPostStep follows a template run that left packages missing: they are installed
+// when install (--install) is set or the user confirms at a terminal, and otherwise
+// reported with the command that installs them. It reports whether they were installed.
+func DependencyPostStep(projectPath string, missing []TemplateDependency, install bool) (bool, error) {
+	if len(missing) == 0 {
+		return false, nil
+	}
+	hint := InstallHint(projectPath, missing)
+	if !install && !cli.IsJSONOutput() && cli.StdinIsTerminal() {
+		fmt.Println("\nThese packages are needed but not listed in package.json:")
+		for _, d := range missing {
+			fmt.Printf("  %s\n", d.Spec())
+		}
+		fmt.Printf("Install them now (%s)? [y/N] ", hint)
+		var answer string
+		fmt.Scanln(&answer)
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		install = answer == "y" || answer == "yes"
+	}
+	if !install {
+		specs := make([]string, len(missing))
+		for i, d := range missing {
+			specs[i] = d.Spec()
+		}
+		cli.Warn("missing dependencies %s; install them with `%s` or rerun with --install", strings.Join(specs, ", "), hint)
+		return false, nil
+	}
+	if err := InstallDependencies(projectPath, missing); err != nil {
+		return false, fmt.Errorf("files were generated, but installing dependencies failed: %w", err)
+	}
+	return true, nil
+}
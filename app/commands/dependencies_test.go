@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestMissingDependencies tests reading a template's dependencies, comparing them with the
+// nearest package.json and building the install commands for the project's package manager.
+func TestMissingDependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":             "",
+		"pnpm-lock.yaml":        "",
+		"package.json":          `{"name": "root"}`,
+		"apps/web/package.json": `{"dependencies": {"next": "^15"}, "devDependencies": {"typescript": "^5"}}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmpl := []byte(`{
+		"title": "add block",
+		"dependencies": {"next": "^15", "@portabletext/react": "^3", "clsx": "latest"},
+		"devDependencies": {"typescript": "", "@types/lodash": "*"},
+		"filePaths": []
+	}`)
+
+	projectPath := filepath.Join(dir, "apps", "web", "components")
+	missing := MissingDependencies(tmpl, projectPath)
+	want := []TemplateDependency{
+		{Name: "@portabletext/react", Version: "^3"},
+		{Name: "clsx", Version: "latest"},
+		{Name: "@types/lodash", Version: "*", Dev: true},
+	}
+	if !reflect.DeepEqual(missing, want) {
+		t.Fatalf("missing = %+v, want %+v", missing, want)
+	}
+	wantLines := [][]string{
+		{"pnpm", "add", "@portabletext/react@^3", "clsx"},
+		{"pnpm", "add", "-D", "@types/lodash"},
+	}
+	if lines := InstallCommands(projectPath, missing); !reflect.DeepEqual(lines, wantLines) {
+		t.Errorf("install commands = %v, want %v", lines, wantLines)
+	}
+
+	if got := MergeDependencies(missing[:1], missing); len(got) != 3 {
+		t.Errorf("merge = %+v", got)
+	}
+	if got := MissingDependencies([]byte(`{"title": "plain"}`), projectPath); got != nil {
+		t.Errorf("template without dependencies reported %+v", got)
+	}
+}
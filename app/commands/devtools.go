@@ -20,25 +20,20 @@ import (
 // GeneratePreviewFileTree generates a string representation of the file tree
 // that *would* be created by a given command, without actually writing files.
 func GeneratePreviewFileTree(cmdName string, placeholders map[string]string, projectPath string) (string, error) {
-	// Load command spec or embedded template by path.
-	spec := GetCommandSpec(cmdName)
-
-	var data []byte
-	var err error
-	if spec.TemplatePath != "" {
-		data, err = LoadCommandTemplate(spec.TemplatePath)
-		if err != nil {
-			return "", fmt.Errorf("failed to load template: %w", err)
-		}
-	} else if strings.HasSuffix(strings.ToLower(cmdName), ".json") {
-		// Allow previewing embedded templates by full path
-		data, err = LoadCommandTemplate(cmdName)
-		if err != nil {
-			return "", fmt.Errorf("failed to load template by path: %w", err)
-		}
-	} else {
+	// Resolve the command like execution does, so previews show what would run
+	resolved, err := NewResolver(projectPath, nil).Resolve(cmdName)
+	if err != nil {
+		return "", fmt.Errorf("failed to load template: %w", err)
+	}
+	if resolved.Template == nil {
 		return "", fmt.Errorf("command %q has no template", cmdName)
 	}
+	data := resolved.Template
+
+	data, err = ResolveTemplateIncludes(data, projectPath)
+	if err != nil {
+		return "", err
+	}
 
 	// Unmarshal into the JSONCommandTemplate structure using original data.
 	var tmpl JSONCommandTemplate
@@ -89,6 +84,13 @@ func GeneratePreviewFileTree(cmdName string, placeholders map[string]string, pro
 		}
 		return false
 	})
+	if missing := MissingDependencies(data, projectPath); len(missing) > 0 {
+		specs := make([]string, len(missing))
+		for i, d := range missing {
+			specs[i] = d.Spec()
+		}
+		preview += "\nNeeds packages not in package.json: " + strings.Join(specs, ", ") + "\n"
+	}
 	return preview, nil
 }
 
@@ -108,9 +110,13 @@ func GeneratePreviewFileTreeFromClipboard(placeholders map[string]string, projec
 	if err != nil {
 		return "", fmt.Errorf("failed to read clipboard: %w", err)
 	}
+	resolved, err := ResolveTemplateIncludes([]byte(clipboardContent), projectPath)
+	if err != nil {
+		return "", err
+	}
 
 	var tmpl JSONCommandTemplate
-	if err := json.Unmarshal([]byte(clipboardContent), &tmpl); err != nil {
+	if err := json.Unmarshal(resolved, &tmpl); err != nil {
 		return "", fmt.Errorf("failed to parse clipboard JSON: %w", err)
 	}
 
@@ -185,7 +191,7 @@ func ExtractVariablesFromClipboard() ([]string, error) {
 		var processNodes func(nodes []TreeNode)
 		processNodes = func(nodes []TreeNode) {
 			for _, node := range nodes {
-				if node.Code != "" {
+				if node.Code != "" && !node.RawCode {
 					// Extract variables from code content
 					for _, key := range InferVariableKeys(node.Code) {
 						vars[key] = struct{}{}
@@ -211,10 +217,32 @@ func ExtractVariablesFromClipboard() ([]string, error) {
 // GeneratePreviewFileTreeFromBytes generates a file tree preview from template bytes.
 // Similar to GeneratePreviewFileTree but takes byte slice instead of command name.
 func GeneratePreviewFileTreeFromBytes(templateBytes []byte, placeholders map[string]string, projectPath string) (string, error) {
+	relPaths, err := PlannedTemplateFiles(templateBytes, placeholders, projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Build the file tree using the shared utils package.
+	treeRoot := utils.BuildFileTree(relPaths)
+	preview := utils.RenderFileTree(treeRoot, "", true, false, func(path string) bool {
+		// Preview doesn't know about edited indexers in this context
+		return false
+	})
+	return preview, nil
+}
+
+// PlannedTemplateFiles returns the files a template would write, relative to the project,
+// without writing anything.
+func PlannedTemplateFiles(templateBytes []byte, placeholders map[string]string, projectPath string) ([]string, error) {
+	templateBytes, err := ResolveTemplateIncludes(templateBytes, projectPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal into the JSONCommandTemplate structure.
 	var tmpl JSONCommandTemplate
 	if err := json.Unmarshal(templateBytes, &tmpl); err != nil {
-		return "", fmt.Errorf("failed to parse template JSON from bytes: %w", err)
+		return nil, fmt.Errorf("failed to parse template JSON from bytes: %w", err)
 	}
 
 	// Collect file paths that would be created.
@@ -247,14 +275,7 @@ func GeneratePreviewFileTreeFromBytes(templateBytes []byte, placeholders map[str
 			relPaths = append(relPaths, f)
 		}
 	}
-
-	// Build the file tree using the shared utils package.
-	treeRoot := utils.BuildFileTree(relPaths)
-	preview := utils.RenderFileTree(treeRoot, "", true, false, func(path string) bool {
-		// Preview doesn't know about edited indexers in this context
-		return false
-	})
-	return preview, nil
+	return relPaths, nil
 }
 
 //
@@ -266,268 +287,42 @@ func GeneratePreviewFileTreeFromBytes(templateBytes []byte, placeholders map[str
 // 1) variables: { "VarName": { "description": "..." }, ... }
 // 2) args: [ { "name": "VarName", "message": "..." | "description": "..." }, ... ]
 func GetCommandVariableDescriptions(cmdName, projectPath string, registry *project.ProjectRegistry) (map[string]string, error) {
-	descs := map[string]string{}
-	// Try to load the template bytes via common resolution
-	b, _, err := LoadTemplateBytesForName(cmdName, projectPath, registry)
-	if err != nil {
-		// If cmdName is an embedded template path, try that directly
-		if data, readErr := LoadCommandTemplate(cmdName); readErr == nil {
-			b = data
-		} else {
-			return descs, nil
-		}
-	}
-	var obj map[string]any
-	if jerr := json.Unmarshal(b, &obj); jerr != nil {
-		return descs, nil
-	}
-	// Case 1: variables object
-	if raw, ok := obj["variables"]; ok {
-		if m, ok2 := raw.(map[string]any); ok2 {
-			for k, v := range m {
-				if inner, ok3 := v.(map[string]any); ok3 {
-					if d, ok4 := inner["description"]; ok4 {
-						if s, ok5 := d.(string); ok5 && strings.TrimSpace(s) != "" {
-							descs[k] = s
-						}
-					}
-				}
-			}
-		}
+	t := commandTemplate(cmdName, projectPath, registry)
+	if t == nil {
+		return map[string]string{}, nil
 	}
-	// Case 2: args array (use description or message)
-	if raw, ok := obj["args"]; ok {
-		if arr, ok2 := raw.([]any); ok2 {
-			for _, it := range arr {
-				if m, ok3 := it.(map[string]any); ok3 {
-					nameVal, _ := m["name"].(string)
-					if strings.TrimSpace(nameVal) == "" {
-						continue
-					}
-					var d string
-					if dv, ok4 := m["description"]; ok4 {
-						if s, ok5 := dv.(string); ok5 {
-							d = s
-						}
-					}
-					if strings.TrimSpace(d) == "" {
-						if mv, ok6 := m["message"]; ok6 {
-							if s, ok7 := mv.(string); ok7 {
-								d = s
-							}
-						}
-					}
-					if strings.TrimSpace(d) != "" {
-						descs[nameVal] = d
-					}
-				}
-			}
-		}
-	}
-	return descs, nil
+	return t.Variables().Descriptions, nil
 }
 
 // GetCommandVariableTitles extracts display titles for variables from the template, if provided.
 // Supports variables.<Var>.title and args[].title (falls back to args[].label if present).
 func GetCommandVariableTitles(cmdName, projectPath string, registry *project.ProjectRegistry) (map[string]string, error) {
-	titles := map[string]string{}
-	b, _, err := LoadTemplateBytesForName(cmdName, projectPath, registry)
-	if err != nil {
-		if data, readErr := LoadCommandTemplate(cmdName); readErr == nil {
-			b = data
-		} else {
-			return titles, nil
-		}
-	}
-	var obj map[string]any
-	if jerr := json.Unmarshal(b, &obj); jerr != nil {
-		return titles, nil
+	t := commandTemplate(cmdName, projectPath, registry)
+	if t == nil {
+		return map[string]string{}, nil
 	}
-	// 1) variables object
-	if raw, ok := obj["variables"]; ok {
-		if m, ok2 := raw.(map[string]any); ok2 {
-			for k, v := range m {
-				if inner, ok3 := v.(map[string]any); ok3 {
-					if t, ok4 := inner["title"]; ok4 {
-						if s, ok5 := t.(string); ok5 && strings.TrimSpace(s) != "" {
-							titles[k] = s
-						}
-					}
-				}
-			}
-		}
-	}
-	// 2) args array: title or label fields
-	if raw, ok := obj["args"]; ok {
-		if arr, ok2 := raw.([]any); ok2 {
-			for _, it := range arr {
-				if m, ok3 := it.(map[string]any); ok3 {
-					nameVal, _ := m["name"].(string)
-					if strings.TrimSpace(nameVal) == "" {
-						continue
-					}
-					var t string
-					if tv, ok4 := m["title"]; ok4 {
-						if s, ok5 := tv.(string); ok5 {
-							t = s
-						}
-					}
-					if strings.TrimSpace(t) == "" {
-						if lv, ok6 := m["label"]; ok6 {
-							if s, ok7 := lv.(string); ok7 {
-								t = s
-							}
-						}
-					}
-					if strings.TrimSpace(t) != "" {
-						titles[nameVal] = t
-					}
-				}
-			}
-		}
-	}
-	return titles, nil
+	return t.Variables().Titles, nil
 }
 
 // GetCommandVariablePriorities extracts numeric priorities for variables from the template.
 // Supports variables.<Var>.priority (number) and args[].priority (number).
 // Lower values indicate earlier prompting.
 func GetCommandVariablePriorities(cmdName, projectPath string, registry *project.ProjectRegistry) (map[string]int, error) {
-	res := map[string]int{}
-	b, _, err := LoadTemplateBytesForName(cmdName, projectPath, registry)
-	if err != nil {
-		if data, readErr := LoadCommandTemplate(cmdName); readErr == nil {
-			b = data
-		} else {
-			return res, nil
-		}
-	}
-	var obj map[string]any
-	if jerr := json.Unmarshal(b, &obj); jerr != nil {
-		return res, nil
-	}
-	// variables object
-	if raw, ok := obj["variables"]; ok {
-		if m, ok2 := raw.(map[string]any); ok2 {
-			for k, v := range m {
-				if inner, ok3 := v.(map[string]any); ok3 {
-					if pv, ok4 := inner["priority"]; ok4 {
-						switch t := pv.(type) {
-						case float64:
-							res[k] = int(t)
-						case int:
-							res[k] = t
-						case json.Number:
-							if iv, e := t.Int64(); e == nil {
-								res[k] = int(iv)
-							}
-						case string:
-							if n, e := json.Number(t).Int64(); e == nil {
-								res[k] = int(n)
-							}
-						}
-					}
-				}
-			}
-		}
+	t := commandTemplate(cmdName, projectPath, registry)
+	if t == nil {
+		return map[string]int{}, nil
 	}
-	// args array
-	if raw, ok := obj["args"]; ok {
-		if arr, ok2 := raw.([]any); ok2 {
-			for _, it := range arr {
-				if m, ok3 := it.(map[string]any); ok3 {
-					nameVal, _ := m["name"].(string)
-					if strings.TrimSpace(nameVal) == "" {
-						continue
-					}
-					if pv, ok4 := m["priority"]; ok4 {
-						switch t := pv.(type) {
-						case float64:
-							res[nameVal] = int(t)
-						case int:
-							res[nameVal] = t
-						case json.Number:
-							if iv, e := t.Int64(); e == nil {
-								res[nameVal] = int(iv)
-							}
-						case string:
-							if n, e := json.Number(t).Int64(); e == nil {
-								res[nameVal] = int(n)
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-	return res, nil
+	return t.Variables().Priorities, nil
 }
 
 // GetCommandVariableExamples extracts example values per variable from the template.
 // Supports variables.<Var>.examples: []string and args[].examples: []string
 func GetCommandVariableExamples(cmdName, projectPath string, registry *project.ProjectRegistry) (map[string][]string, error) {
-	out := map[string][]string{}
-	b, _, err := LoadTemplateBytesForName(cmdName, projectPath, registry)
-	if err != nil {
-		if data, readErr := LoadCommandTemplate(cmdName); readErr == nil {
-			b = data
-		} else {
-			return out, nil
-		}
-	}
-	var obj map[string]any
-	if jerr := json.Unmarshal(b, &obj); jerr != nil {
-		return out, nil
-	}
-	// variables object
-	if raw, ok := obj["variables"]; ok {
-		if m, ok2 := raw.(map[string]any); ok2 {
-			for k, v := range m {
-				if inner, ok3 := v.(map[string]any); ok3 {
-					if exv, ok4 := inner["examples"]; ok4 {
-						if arr, ok5 := exv.([]any); ok5 {
-							var coll []string
-							for _, it := range arr {
-								if s, ok6 := it.(string); ok6 && strings.TrimSpace(s) != "" {
-									coll = append(coll, s)
-								}
-							}
-							if len(coll) > 0 {
-								out[k] = coll
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-	// args array
-	if raw, ok := obj["args"]; ok {
-		if arr, ok2 := raw.([]any); ok2 {
-			for _, it := range arr {
-				if m, ok3 := it.(map[string]any); ok3 {
-					nameVal, _ := m["name"].(string)
-					if strings.TrimSpace(nameVal) == "" {
-						continue
-					}
-					if exv, ok4 := m["examples"]; ok4 {
-						if arr2, ok5 := exv.([]any); ok5 {
-							var coll []string
-							for _, it2 := range arr2 {
-								if s, ok6 := it2.(string); ok6 && strings.TrimSpace(s) != "" {
-									coll = append(coll, s)
-								}
-							}
-							if len(coll) > 0 {
-								out[nameVal] = coll
-							}
-						}
-					}
-				}
-			}
-		}
+	t := commandTemplate(cmdName, projectPath, registry)
+	if t == nil {
+		return map[string][]string{}, nil
 	}
-	return out, nil
+	return t.Variables().Examples, nil
 }
 
 // Note: InferVariableKeys is defined in command-registry.go and reused here.Å
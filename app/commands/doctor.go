@@ -0,0 +1,499 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+	config "github.com/Guerrilla-Interactive/nextgen-go-cli/internal"
+)
+
+// -----------------------------------------------------------------------------
+// [DOCTOR] Diagnostics for configuration, project detection and commands (`ng doctor`)
+// -----------------------------------------------------------------------------
+
+// Doctor check statuses.
+const (
+	DoctorOK    = "ok"
+	DoctorInfo  = "info"
+	DoctorWarn  = "warn"
+	DoctorError = "error"
+)
+
+// DoctorCheck is one finding of `ng doctor`.
+type DoctorCheck struct {
+	Section string `json:"section"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// DoctorReport holds every finding, in report order.
+type DoctorReport struct {
+	ProjectPath string        `json:"projectPath"`
+	Checks      []DoctorCheck `json:"checks"`
+}
+
+// Sections of the doctor report, in order.
+const (
+	doctorConfig   = "Config"
+	doctorProject  = "Project"
+	doctorPackages = "Command packages"
+	doctorCommands = "Commands"
+	doctorLocal    = "Local commands"
+	doctorIndexers = "Indexers"
+)
+
+// DoctorSections lists the report sections in the order they are printed.
+var DoctorSections = []string{doctorConfig, doctorProject, doctorPackages, doctorCommands, doctorLocal, doctorIndexers}
+
+func (r *DoctorReport) add(section, name, status, detail, fix string) {
+	r.Checks = append(r.Checks, DoctorCheck{Section: section, Name: name, Status: status, Detail: detail, Fix: fix})
+}
+
+// Count returns how many checks have the given status.
+func (r DoctorReport) Count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Fixes returns the suggested fixes of warnings and errors, errors first.
+func (r DoctorReport) Fixes() []string {
+	var fixes []string
+	for _, status := range []string{DoctorError, DoctorWarn} {
+		for _, c := range r.Checks {
+			if c.Status == status && c.Fix != "" && !slices.Contains(fixes, c.Fix) {
+				fixes = append(fixes, c.Fix)
+			}
+		}
+	}
+	return fixes
+}
+
+// RunDoctor inspects the CLI configuration and the project at projectPath.
+func RunDoctor(projectPath string, registry *project.ProjectRegistry) DoctorReport {
+	r := DoctorReport{ProjectPath: projectPath}
+	r.checkConfig(projectPath, registry)
+	r.checkProject(projectPath)
+	r.checkCommandPackages(projectPath)
+	r.checkCommands(projectPath)
+	r.checkLocalCommands(projectPath)
+	r.checkIndexers(projectPath)
+	return r
+}
+
+// ---- Config ----
+
+func (r *DoctorReport) checkConfig(projectPath string, registry *project.ProjectRegistry) {
+	if path, err := config.ConfigPath(); err != nil {
+		r.add(doctorConfig, "config", DoctorError, err.Error(), "Set $HOME so the CLI can find its configuration")
+	} else {
+		r.checkJSONFile(doctorConfig, path, "not found; you are logged out and defaults are used",
+			"Fix the JSON in "+path+", or delete it and log in again")
+	}
+
+	path, err := project.RegistryFilePath()
+	if err != nil {
+		r.add(doctorConfig, "projects.json", DoctorError, err.Error(), "Set $HOME so the CLI can find its configuration")
+		return
+	}
+	if !r.checkJSONFile(doctorConfig, path, "not found; it is created on first use",
+		"Fix the JSON in "+path+", or delete it (project history and saved commands are lost)") {
+		return
+	}
+	if registry == nil {
+		return
+	}
+	detail := fmt.Sprintf("%d known projects, %d saved clipboard commands, %d saved native commands",
+		len(registry.Projects), len(registry.ClipboardCommands), len(registry.NativeCommands))
+	r.add(doctorConfig, "registry", DoctorOK, detail, "")
+	if _, known := registry.Projects[projectPath]; !known {
+		r.add(doctorConfig, "this project", DoctorInfo, "not in the registry yet; it is added when you run a command here", "")
+	}
+}
+
+// checkJSONFile reports whether a JSON file parses. It returns true when the file exists
+// and parses.
+func (r *DoctorReport) checkJSONFile(section, path, missing, fix string) bool {
+	name := displayPath(path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		r.add(section, name, DoctorInfo, missing, "")
+		return false
+	}
+	if err != nil {
+		r.add(section, name, DoctorError, err.Error(), "Check the permissions of "+path)
+		return false
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		r.add(section, name, DoctorError, "does not parse: "+err.Error(), fix)
+		return false
+	}
+	r.add(section, name, DoctorOK, "parses", "")
+	return true
+}
+
+// displayPath shortens paths in the home directory to ~/...
+func displayPath(path string) string {
+	if home, err := os.UserHomeDir(); err == nil {
+		if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(filepath.Join("~", rel))
+		}
+	}
+	return path
+}
+
+// ---- Project ----
+
+func (r *DoctorReport) checkProject(projectPath string) {
+	info, found := project.DetectProject(projectPath)
+	if !found {
+		r.add(doctorProject, "detection", DoctorWarn, "no package.json, "+strings.Join(project.EcosystemMarkers(), ", ")+" or .git in this directory or its parents",
+			"Run ng from inside your project")
+		return
+	}
+	detail := fmt.Sprintf("%s project %q at %s", info.Type, info.Name, info.RootPath)
+	r.add(doctorProject, "detection", DoctorOK, detail, "")
+	packages := append([]string{}, info.DetectedPackages...)
+	sort.Strings(packages)
+	if len(packages) > 0 {
+		r.add(doctorProject, "packages", DoctorOK, strings.Join(packages, ", "), "")
+	} else {
+		r.add(doctorProject, "packages", DoctorInfo, "no known frameworks in dependencies", "")
+	}
+	if info.RootPath != projectPath {
+		r.add(doctorProject, "working directory", DoctorWarn,
+			"command visibility is evaluated against the current directory, not the project root "+info.RootPath,
+			"Run ng from the project root: cd "+info.RootPath)
+	}
+	if info.WorkspaceRoot != "" && info.WorkspaceRoot == info.RootPath {
+		r.add(doctorProject, "workspaces", DoctorInfo,
+			fmt.Sprintf("monorepo root with %d packages; commands here see the root package.json", len(info.Workspaces)),
+			"Target a package with --workspace <name> (list them with ng workspaces)")
+	} else if info.WorkspaceRoot != "" {
+		r.add(doctorProject, "workspaces", DoctorOK, "package of the monorepo at "+info.WorkspaceRoot, "")
+	}
+	if info.PackageManager != "" {
+		r.add(doctorProject, "package manager", DoctorOK, info.PackageManager, "")
+	} else if _, err := os.Stat(filepath.Join(info.RootPath, "package.json")); err == nil {
+		r.add(doctorProject, "package manager", DoctorInfo, "no lockfile found; template dependencies install with npm", "")
+	}
+
+	pkgPath := filepath.Join(projectPath, "package.json")
+	if data, err := os.ReadFile(pkgPath); err == nil {
+		var v map[string]any
+		if err := json.Unmarshal(data, &v); err != nil {
+			r.add(doctorProject, "package.json", DoctorError, "does not parse: "+err.Error(),
+				"Fix the JSON in package.json; commands that depend on it stay hidden until then")
+		}
+	}
+}
+
+// ---- Command packages ----
+
+func (r *DoctorReport) checkCommandPackages(projectPath string) {
+	known := knownIdentifiers()
+
+	cpPath := filepath.Join(projectPath, ".nextgen", "command-packages.json")
+	const cpName = ".nextgen/command-packages.json"
+	if data, err := os.ReadFile(cpPath); os.IsNotExist(err) {
+		r.add(doctorPackages, cpName, DoctorInfo, "not found", "")
+	} else if err != nil {
+		r.add(doctorPackages, cpName, DoctorError, err.Error(), "Check the permissions of "+cpName)
+	} else if !json.Valid(data) {
+		r.add(doctorPackages, cpName, DoctorError, "does not parse as JSON",
+			`Fix the JSON in `+cpName+`: a list of identifiers (["nextjs"]) or {"identifiers": [...]}`)
+	} else if ids := parseCommandPackages(data); len(ids) == 0 {
+		r.add(doctorPackages, cpName, DoctorWarn, "lists no identifiers",
+			`List identifiers in `+cpName+`, e.g. ["nextjs"], or delete it`)
+	} else {
+		r.add(doctorPackages, cpName, DoctorOK, strings.Join(sortedKeys(ids), ", "), "")
+		r.checkIdentifiers(cpName, sortedKeys(ids), known)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return
+	}
+	var pkg map[string]any
+	if json.Unmarshal(data, &pkg) != nil {
+		return // reported under Project
+	}
+	raw, ok := pkg["nextgen-identifiers"]
+	if !ok {
+		r.add(doctorPackages, "nextgen-identifiers", DoctorInfo, "not set in package.json", "")
+		return
+	}
+	arr, ok := raw.([]any)
+	var ids []string
+	for _, v := range arr {
+		if s, isString := v.(string); isString {
+			ids = append(ids, strings.TrimSpace(s))
+		} else {
+			ok = false
+		}
+	}
+	if !ok {
+		r.add(doctorPackages, "nextgen-identifiers", DoctorError, "must be an array of strings",
+			`Set "nextgen-identifiers" in package.json to an array of strings, e.g. ["nextjs"]`)
+		return
+	}
+	r.add(doctorPackages, "nextgen-identifiers", DoctorOK, strings.Join(ids, ", "), "")
+	r.checkIdentifiers("nextgen-identifiers", ids, known)
+}
+
+// checkIdentifiers warns about identifiers that no command's visibility refers to.
+func (r *DoctorReport) checkIdentifiers(source string, ids []string, known map[string]bool) {
+	for _, id := range ids {
+		if !known[id] {
+			r.add(doctorPackages, source, DoctorWarn, fmt.Sprintf("identifier %q does not show any command", id),
+				fmt.Sprintf("Check the spelling of %q in %s (known: %s), or install the pack that provides it",
+					id, source, strings.Join(sortedKeys(known), ", ")))
+		}
+	}
+}
+
+// knownIdentifiers returns the identifiers that command visibility rules look for.
+func knownIdentifiers() map[string]bool {
+	known := map[string]bool{}
+	addClause := func(c CommandVisibilityClause) {
+		for _, id := range c.CommandPackagesContains {
+			known[strings.TrimSpace(id)] = true
+		}
+		if id, ok := c.PackageJSONArrayContains["nextgen-identifiers"]; ok {
+			known[strings.TrimSpace(id)] = true
+		}
+	}
+	for _, spec := range RegisteredCommands() {
+		if v := spec.Visibility; v != nil {
+			CommandVisibilityClause(*v).Walk(addClause)
+		}
+	}
+	return known
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ---- Commands ----
+
+func (r *DoctorReport) checkCommands(projectPath string) {
+	visible := 0
+	for _, spec := range RegisteredCommands() {
+		if _, err := LoadCommandTemplate(spec.TemplatePath); err != nil {
+			r.add(doctorCommands, spec.Name, DoctorError, "template does not load: "+err.Error(),
+				"Fix or remove the template "+spec.TemplatePath)
+			continue
+		}
+		trace := ExplainVisibility(spec, projectPath)
+		if trace.Visible {
+			visible++
+			continue
+		}
+		r.add(doctorCommands, spec.Slug, DoctorInfo, "hidden: "+trace.Reason,
+			"To show "+spec.Slug+": "+strings.Join(trace.Fixes, ", or "))
+	}
+	r.add(doctorCommands, "visible", DoctorOK, fmt.Sprintf("%d of %d template commands are shown in this project", visible, len(Commands)), "")
+}
+
+// ---- Local commands ----
+
+func (r *DoctorReport) checkLocalCommands(projectPath string) {
+	dir := LocalCommandsDir(projectPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		r.add(doctorLocal, ".nextgen/local-commands", DoctorInfo, "none", "")
+		return
+	}
+	resolver := NewResolver(projectPath, nil)
+	count := 0
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		count++
+		name := strings.TrimSuffix(e.Name(), ".json")
+		rel := filepath.ToSlash(filepath.Join(".nextgen", "local-commands", e.Name()))
+		resolved, _, err := resolver.lookupProject(name)
+		if err == nil && resolved.Template != nil {
+			var expanded []byte
+			if expanded, err = ResolveTemplateIncludes(resolved.Template, projectPath); err == nil {
+				var t JSONCommandTemplate
+				err = json.Unmarshal(expanded, &t)
+			}
+		}
+		if err != nil {
+			r.add(doctorLocal, rel, DoctorError, err.Error(), "Fix "+rel)
+			continue
+		}
+		if spec, isOverride := OverrideForLocalFile(name); isOverride {
+			r.add(doctorLocal, rel, DoctorOK, "overrides built-in "+spec.Slug, "")
+		} else {
+			r.add(doctorLocal, rel, DoctorOK, string(resolved.Kind)+" command", "")
+		}
+	}
+	if count == 0 {
+		r.add(doctorLocal, ".nextgen/local-commands", DoctorInfo, "none", "")
+	}
+}
+
+// ---- Indexers ----
+
+// indexerUse is an indexer file in the project that templates merge snippets into.
+type indexerUse struct {
+	file     string
+	missing  map[string]bool
+	commands map[string]bool
+}
+
+func (r *DoctorReport) checkIndexers(projectPath string) {
+	uses := map[string]*indexerUse{}
+	for _, spec := range RegisteredCommands() {
+		if !IsCommandVisible(spec, projectPath) {
+			continue
+		}
+		for _, t := range doctorTemplates(spec, projectPath) {
+			for _, group := range t.FilePaths {
+				walkIndexerNodes(group.Nodes, group.Path, func(rel string, node TreeNode) {
+					existing, err := os.ReadFile(filepath.Join(projectPath, rel))
+					if err != nil {
+						return // created from the template, markers included
+					}
+					for _, key := range missingMarkers(string(existing), node) {
+						use := uses[rel]
+						if use == nil {
+							use = &indexerUse{file: rel, missing: map[string]bool{}, commands: map[string]bool{}}
+							uses[rel] = use
+						}
+						use.missing[key] = true
+						use.commands[spec.Slug] = true
+					}
+				})
+			}
+		}
+	}
+	if len(uses) == 0 {
+		r.add(doctorIndexers, "markers", DoctorOK, "every indexer file used by the shown commands has its markers", "")
+		return
+	}
+	files := make([]string, 0, len(uses))
+	for f := range uses {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		use := uses[f]
+		keys := sortedKeys(use.missing)
+		markers := make([]string, len(keys))
+		for i, k := range keys {
+			markers[i] = "// ADD " + k + " BELOW"
+		}
+		r.add(doctorIndexers, filepath.ToSlash(f), DoctorWarn,
+			fmt.Sprintf("no markers or action targets for %s (used by %s); entries are placed by guesswork", strings.Join(keys, ", "), strings.Join(sortedKeys(use.commands), ", ")),
+			fmt.Sprintf("Add %s in %s where new entries belong", strings.Join(markers, " / "), filepath.ToSlash(f)))
+	}
+}
+
+// doctorTemplates returns the parsed templates a command can run: its own, or for bundle
+// commands every template in the bundle folder. Templates that fail to load are skipped.
+func doctorTemplates(spec CommandSpec, projectPath string) []JSONCommandTemplate {
+	data, err := LoadCommandTemplate(spec.TemplatePath)
+	if err != nil {
+		return nil // reported under Commands
+	}
+	var t JSONCommandTemplate
+	if expanded, err := ResolveTemplateIncludes(data, projectPath); err != nil || json.Unmarshal(expanded, &t) != nil {
+		return nil
+	}
+	if t.AutoBrowseRoot == "" {
+		return []JSONCommandTemplate{t}
+	}
+	var out []JSONCommandTemplate
+	var walk func(prefix string)
+	walk = func(prefix string) {
+		children, _ := ListNativeChildren(prefix)
+		for _, c := range children {
+			if c.IsDir {
+				walk(c.Path)
+				continue
+			}
+			data, err := ReadEmbeddedTemplate(c.Path)
+			if err != nil {
+				continue
+			}
+			var inner JSONCommandTemplate
+			if expanded, err := ResolveTemplateIncludes(data, projectPath); err == nil && json.Unmarshal(expanded, &inner) == nil {
+				out = append(out, inner)
+			}
+		}
+	}
+	walk(t.AutoBrowseRoot)
+	return out
+}
+
+// walkIndexerNodes calls fn for every indexer file of a template whose path has no
+// placeholders, with its path relative to the project.
+func walkIndexerNodes(nodes []TreeNode, base string, fn func(rel string, node TreeNode)) {
+	for _, n := range nodes {
+		rel := filepath.Join(base, n.Name)
+		if strings.Contains(rel, "{{") {
+			continue
+		}
+		if len(n.Children) > 0 {
+			walkIndexerNodes(n.Children, rel, fn)
+			continue
+		}
+		if n.IsIndexer || strings.Contains(n.Code, "THIS IS AN INDEXER FILE") || startMarkerRegex.MatchString(n.Code) {
+			fn(rel, n)
+		}
+	}
+}
+
+// missingMarkers returns the snippet keys of an indexer node that have neither an ADD
+// marker in the existing file nor an action whose target text is found in it.
+func missingMarkers(existing string, node TreeNode) []string {
+	snippets, _ := extractSnippets(node.Code)
+	handled := map[string]bool{}
+	for _, a := range node.getActions() {
+		target := strings.TrimSpace(a.Logic.Raw)
+		if spec := a.Logic.Spec; spec != nil {
+			target = spec.Target
+			if target == "" {
+				target = spec.TargetStart
+			}
+			if target == "" {
+				target = "{{" // no target needed
+			}
+		}
+		if target != "" && (strings.Contains(target, "{{") || strings.Contains(existing, target)) {
+			handled[strings.TrimSpace(a.Title)] = true
+		}
+	}
+	var missing []string
+	for key := range snippets {
+		if strings.Contains(key, "{{") || handled[key] || markerForKeyExists(existing, key) {
+			continue
+		}
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+	return missing
+}
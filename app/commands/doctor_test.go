@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// findCheck returns the first check of a section whose name and detail contain the given text.
+func findCheck(r DoctorReport, section, name, detail string) (DoctorCheck, bool) {
+	for _, c := range r.Checks {
+		if c.Section == section && strings.Contains(c.Name, name) && strings.Contains(c.Detail, detail) {
+			return c, true
+		}
+	}
+	return DoctorCheck{}, false
+}
+
+// TestRunDoctor tests the config, command package and local command checks.
+func TestRunDoctor(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".ngc"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ngc", "config.json"), []byte("{oops"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	projectPath := t.TempDir()
+	files := map[string]string{
+		"package.json":                          `{"name": "demo", "nextgen-identifiers": "nextjs"}`,
+		".nextgen/command-packages.json":        `["not-a-package"]`,
+		".nextgen/local-commands/broken.json":   `{"filePaths": `,
+		".nextgen/local-commands/hello.json":    `{"command": "echo hello"}`,
+		".nextgen/local-commands/template.json": `{"filePaths": [{"path": "src", "nodes": [{"name": "a.ts", "code": "a"}]}]}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(projectPath, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := RunDoctor(projectPath, nil)
+	tests := []struct {
+		section, name, detail, status string
+	}{
+		{doctorConfig, ".ngc/config.json", "does not parse", DoctorError},
+		{doctorConfig, "projects.json", "not found", DoctorInfo},
+		{doctorPackages, "command-packages.json", `identifier "not-a-package" does not show any command`, DoctorWarn},
+		{doctorPackages, "nextgen-identifiers", "must be an array of strings", DoctorError},
+		{doctorLocal, "broken.json", "failed to parse", DoctorError},
+		{doctorLocal, "hello.json", "shell command", DoctorOK},
+		{doctorLocal, "template.json", "template command", DoctorOK},
+	}
+	for _, tt := range tests {
+		c, ok := findCheck(r, tt.section, tt.name, tt.detail)
+		if !ok {
+			t.Errorf("no %s check %q with %q in %+v", tt.section, tt.name, tt.detail, r.Checks)
+			continue
+		}
+		if c.Status != tt.status {
+			t.Errorf("%s %q: status %s, want %s", tt.section, tt.name, c.Status, tt.status)
+		}
+	}
+	if got := r.Count(DoctorError); got != 3 {
+		t.Errorf("Count(error) = %d, want 3", got)
+	}
+	if fixes := r.Fixes(); len(fixes) != 4 || !strings.Contains(fixes[0], "config.json") {
+		t.Errorf("Fixes() = %q", fixes)
+	}
+}
+
+// TestMissingMarkers tests which snippet keys of an indexer are reported as lacking markers.
+func TestMissingMarkers(t *testing.T) {
+	node := TreeNode{
+		Name: "index.ts",
+		Code: "// START OF IMPORTS\nimport {x} from './x'\n// END OF IMPORTS\n// START OF ITEMS\n  x,\n// END OF ITEMS\n// START OF EXTRA\ny\n// END OF EXTRA",
+		Actions: []InsertionAction{
+			{Title: "ITEMS", Logic: MarkerFallback{Spec: &MarkerFallbackSpec{Behaviour: "addMarkerBelowTarget", Target: "export const items = ["}}},
+		},
+	}
+	tests := []struct {
+		existing string
+		want     []string
+	}{
+		{"// ADD IMPORTS BELOW\nexport const items = [\n]", []string{"EXTRA"}},
+		{"export const list = [\n]", []string{"EXTRA", "IMPORTS", "ITEMS"}},
+		{"// ADD IMPORTS BELOW\n// ADD EXTRA ABOVE\nexport const items = [\n]", nil},
+	}
+	for _, tt := range tests {
+		if got := missingMarkers(tt.existing, node); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("missingMarkers(%q) = %q, want %q", tt.existing, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [EXTENDS] Template inheritance
+// -----------------------------------------------------------------------------
+
+// A template can extend another template and describe only what differs:
+//
+//	{
+//	  "extends": "native-commands/nextjs/.../base.json",
+//	  "title": "Variant",
+//	  "overrides": [{ "_key": "index-page", "code": "..." }],
+//	  "add": [{ "parent": "pages-group", "node": { "_type": "treeNode", ... } }],
+//	  "remove": ["not-found-page"],
+//	  "patchActions": [{ "target": "schema-index", "remove": ["Old action"], "upsert": [{ "title": "...", "logic": { ... } }] }]
+//	}
+//
+// Nodes and filePath groups are addressed by their "_key" or "id". Top-level fields of the
+// extending template (title, slug, show, args, ...) replace those of the base, and a
+// "filePaths" list replaces the base groups entirely. Bases may themselves extend others.
+
+// extendsDirectives are the top-level fields consumed while resolving inheritance.
+var extendsDirectives = map[string]bool{
+	"extends": true, "overrides": true, "add": true, "remove": true, "patchActions": true,
+}
+
+// resolveTemplateExtends materializes a template that extends another one. Templates
+// without "extends" are returned unchanged. stack holds the template paths being resolved.
+func resolveTemplateExtends(templateBytes []byte, stack []string) ([]byte, error) {
+	if !bytes.Contains(templateBytes, []byte(`"extends"`)) {
+		return templateBytes, nil
+	}
+	doc, err := decodeTemplateJSON(templateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse JSON template: %w", err)
+	}
+	child, ok := doc.(map[string]any)
+	if !ok {
+		return templateBytes, nil
+	}
+	baseRef, ok := child["extends"].(string)
+	if !ok {
+		return templateBytes, nil
+	}
+	basePath := normalizeTemplateRef(baseRef)
+	for _, seen := range stack {
+		if seen == basePath {
+			return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(stack, " -> "), basePath)
+		}
+	}
+	baseRaw, found := templateRegistry[basePath]
+	if !found {
+		return nil, fmt.Errorf("base template %q not found", baseRef)
+	}
+	baseBytes, err := resolveTemplateExtends(baseRaw, append(stack, basePath))
+	if err != nil {
+		return nil, err
+	}
+	baseDoc, err := decodeTemplateJSON(baseBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse base template %q: %w", baseRef, err)
+	}
+	base, ok := baseDoc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("base template %q is not a JSON object", baseRef)
+	}
+
+	for k, v := range child {
+		if !extendsDirectives[k] {
+			base[k] = v
+		}
+	}
+	if err := applyTemplatePatches(base, child); err != nil {
+		return nil, fmt.Errorf("could not extend %q: %w", baseRef, err)
+	}
+	out, err := json.Marshal(base)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode extended template: %w", err)
+	}
+	return out, nil
+}
+
+// normalizeTemplateRef maps an extends reference to a template registry key. References
+// may be given with or without the "native-commands/" prefix.
+func normalizeTemplateRef(ref string) string {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "/")
+	if _, ok := templateRegistry[ref]; ok {
+		return ref
+	}
+	if !strings.HasPrefix(ref, "native-commands/") {
+		return "native-commands/" + ref
+	}
+	return ref
+}
+
+// applyTemplatePatches applies remove, overrides, add and patchActions (in that order).
+func applyTemplatePatches(base, child map[string]any) error {
+	for _, ref := range toStringList(child["remove"]) {
+		if !removeTemplateEntry(base, ref) {
+			return fmt.Errorf("remove: no node or group with _key/id %q", ref)
+		}
+	}
+	for _, raw := range toObjectList(child["overrides"]) {
+		ref := entryRef(raw)
+		if ref == "" {
+			return fmt.Errorf("overrides: each entry needs a _key or id")
+		}
+		target := findTemplateEntry(base, ref)
+		if target == nil {
+			return fmt.Errorf("overrides: no node or group with _key/id %q", ref)
+		}
+		for k, v := range raw {
+			target[k] = v
+		}
+	}
+	for _, raw := range toObjectList(child["add"]) {
+		parentRef, _ := raw["parent"].(string)
+		node, ok := raw["node"].(map[string]any)
+		if parentRef == "" || !ok {
+			return fmt.Errorf("add: each entry needs a parent and a node")
+		}
+		parent := findTemplateEntry(base, parentRef)
+		if parent == nil {
+			return fmt.Errorf("add: no node or group with _key/id %q", parentRef)
+		}
+		field := "children"
+		if _, isGroup := parent["nodes"]; isGroup {
+			field = "nodes"
+		}
+		existing, _ := parent[field].([]any)
+		parent[field] = append(existing, node)
+	}
+	for _, raw := range toObjectList(child["patchActions"]) {
+		ref, _ := raw["target"].(string)
+		target := findTemplateEntry(base, ref)
+		if target == nil {
+			return fmt.Errorf("patchActions: no node with _key/id %q", ref)
+		}
+		target["actions"] = patchActionList(target["actions"], toStringList(raw["remove"]), toObjectList(raw["upsert"]))
+	}
+	return nil
+}
+
+// patchActionList removes actions by title, then replaces actions with a matching title
+// or appends new ones.
+func patchActionList(current any, remove []string, upsert []map[string]any) []any {
+	removed := map[string]bool{}
+	for _, t := range remove {
+		removed[t] = true
+	}
+	var out []any
+	for _, a := range toObjectList(current) {
+		if title, _ := a["title"].(string); !removed[title] {
+			out = append(out, a)
+		}
+	}
+	for _, a := range upsert {
+		title, _ := a["title"].(string)
+		replaced := false
+		for i, existing := range out {
+			if t, _ := existing.(map[string]any)["title"].(string); title != "" && t == title {
+				out[i] = a
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// entryRef returns the _key of a template object, falling back to its id.
+func entryRef(entry map[string]any) string {
+	if k, _ := entry["_key"].(string); k != "" {
+		return k
+	}
+	id, _ := entry["id"].(string)
+	return id
+}
+
+// matchesRef reports whether a template object is addressed by ref.
+func matchesRef(entry map[string]any, ref string) bool {
+	if ref == "" {
+		return false
+	}
+	k, _ := entry["_key"].(string)
+	id, _ := entry["id"].(string)
+	return k == ref || id == ref
+}
+
+// findTemplateEntry finds a filePath group or node by _key/id anywhere in the template.
+func findTemplateEntry(tmpl map[string]any, ref string) map[string]any {
+	var search func(list any) map[string]any
+	search = func(list any) map[string]any {
+		for _, entry := range toObjectList(list) {
+			if matchesRef(entry, ref) {
+				return entry
+			}
+			if found := search(entry["children"]); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	for _, group := range toObjectList(tmpl["filePaths"]) {
+		if matchesRef(group, ref) {
+			return group
+		}
+		if found := search(group["nodes"]); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// removeTemplateEntry removes every filePath group or node addressed by ref.
+func removeTemplateEntry(tmpl map[string]any, ref string) bool {
+	removed := false
+	var prune func(list any) []any
+	prune = func(list any) []any {
+		var kept []any
+		for _, entry := range toObjectList(list) {
+			if matchesRef(entry, ref) {
+				removed = true
+				continue
+			}
+			if children, ok := entry["children"]; ok {
+				entry["children"] = prune(children)
+			}
+			kept = append(kept, entry)
+		}
+		return kept
+	}
+	var groups []any
+	for _, group := range toObjectList(tmpl["filePaths"]) {
+		if matchesRef(group, ref) {
+			removed = true
+			continue
+		}
+		group["nodes"] = prune(group["nodes"])
+		groups = append(groups, group)
+	}
+	tmpl["filePaths"] = groups
+	return removed
+}
+
+// toObjectList returns the JSON objects in a decoded array, skipping other values.
+func toObjectList(v any) []map[string]any {
+	arr, _ := v.([]any)
+	out := make([]map[string]any, 0, len(arr))
+	for _, item := range arr {
+		if obj, ok := item.(map[string]any); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+// toStringList returns the strings in a decoded array, skipping other values.
+func toStringList(v any) []string {
+	arr, _ := v.([]any)
+	out := make([]string, 0, len(arr))
+	for _, item := range arr {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
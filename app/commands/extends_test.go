@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestResolveTemplateExtends tests overrides, additions, removals and action patches on a base template.
+func TestResolveTemplateExtends(t *testing.T) {
+	basePath := "native-commands/test/extends-base.json"
+	templateRegistry[basePath] = []byte(`{
+		"title": "Base", "slug": "base",
+		"filePaths": [{"id": "group", "path": "src", "nodes": [
+			{"_key": "index", "name": "index.ts", "code": "base", "actions": [{"title": "keep"}, {"title": "drop"}, {"title": "swap", "mark": "old"}]},
+			{"_key": "extra", "name": "extra.ts", "code": "extra"}
+		]}]
+	}`)
+	defer delete(templateRegistry, basePath)
+
+	child := []byte(`{
+		"extends": "test/extends-base.json",
+		"title": "Child",
+		"overrides": [{"_key": "index", "code": "child"}],
+		"add": [{"parent": "group", "node": {"name": "added.ts", "code": "added"}}],
+		"remove": ["extra"],
+		"patchActions": [{"target": "index", "remove": ["drop"], "upsert": [{"title": "swap", "mark": "new"}, {"title": "more"}]}]
+	}`)
+	out, err := resolveTemplateExtends(child, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tmpl struct {
+		Title string `json:"title"`
+		Slug  string `json:"slug"`
+		JSONCommandTemplate
+	}
+	if err := json.Unmarshal(out, &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Title != "Child" || tmpl.Slug != "base" {
+		t.Errorf("unexpected top-level fields: title=%q slug=%q", tmpl.Title, tmpl.Slug)
+	}
+	nodes := tmpl.FilePaths[0].Nodes
+	if len(nodes) != 2 || nodes[0].Code != "child" || nodes[1].Name != "added.ts" {
+		t.Fatalf("unexpected nodes: %+v", nodes)
+	}
+	var titles []string
+	for _, a := range nodes[0].Actions {
+		titles = append(titles, a.Title+":"+a.Mark)
+	}
+	if got := strings.Join(titles, ","); got != "keep:,swap:new,more:" {
+		t.Errorf("unexpected actions: %s", got)
+	}
+
+	if _, err := resolveTemplateExtends([]byte(`{"extends": "test/extends-base.json", "remove": ["missing"]}`), nil); err == nil {
+		t.Error("expected error when removing an unknown node")
+	}
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [INCLUDE] Shared partials: node includes and code fragments
+// -----------------------------------------------------------------------------
+
+// Templates can reference shared partials instead of duplicating file bodies:
+//
+//	{ "include": "partials/sanity-schema-index.json#node", "name": "index.ts" }
+//	{ "name": "page.tsx", "codeFrom": "partials/page.tsx.tmpl" }
+//
+// An include is replaced by the referenced JSON object (the part after "#" selects a
+// top-level key); any other fields on the including object override the partial.
+// codeFrom sets the node's code from a text file unless the node already has code.
+// References are resolved against the project's .nextgen/ folder, then the user
+// template directory, then the embedded native-commands FS.
+
+// maxIncludeDepth guards against runaway nesting of partials.
+const maxIncludeDepth = 16
+
+// readPartial reads a partial by its reference path. It returns the file contents and a
+// description of where the partial was found.
+func readPartial(ref, projectPath string) ([]byte, string, error) {
+	clean := path.Clean(filepath.ToSlash(strings.TrimSpace(ref)))
+	if clean == "." || path.IsAbs(clean) || strings.HasPrefix(clean, "../") || clean == ".." {
+		return nil, "", fmt.Errorf("invalid partial reference %q", ref)
+	}
+	var searched []string
+	if projectPath != "" && projectPath != "." {
+		p := filepath.Join(projectPath, ".nextgen", filepath.FromSlash(clean))
+		if data, err := os.ReadFile(p); err == nil {
+			return data, p, nil
+		}
+		searched = append(searched, p)
+	}
+	if dir := UserTemplateDir(); dir != "" {
+		p := filepath.Join(dir, filepath.FromSlash(clean))
+		if data, err := os.ReadFile(p); err == nil {
+			return data, p, nil
+		}
+		searched = append(searched, p)
+	}
+	embedded := "native-commands/" + clean
+	if data, err := commandFiles.ReadFile(embedded); err == nil {
+		return data, "embedded " + embedded, nil
+	}
+	searched = append(searched, "embedded "+embedded)
+	return nil, "", fmt.Errorf("partial %q not found (searched %s)", ref, strings.Join(searched, ", "))
+}
+
+// decodeTemplateJSON decodes template bytes into generic JSON values, keeping numbers intact.
+func decodeTemplateJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// loadIncludeFragment loads the JSON object referenced by an include such as
+// "partials/file.json#node".
+func loadIncludeFragment(ref, projectPath string) (map[string]any, error) {
+	file, fragment, _ := strings.Cut(ref, "#")
+	data, _, err := readPartial(file, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeTemplateJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse partial %q: %w", file, err)
+	}
+	if strings.TrimSpace(fragment) != "" {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("partial %q is not an object; cannot select #%s", file, fragment)
+		}
+		doc, ok = obj[fragment]
+		if !ok {
+			return nil, fmt.Errorf("partial %q has no #%s", file, fragment)
+		}
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("include %q does not reference a JSON object", ref)
+	}
+	return obj, nil
+}
+
+// expandIncludes walks a decoded template and expands include/codeFrom references in place.
+func expandIncludes(value any, projectPath string, stack []string) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		if raw, ok := v["include"]; ok {
+			ref, isString := raw.(string)
+			if !isString || strings.TrimSpace(ref) == "" {
+				return nil, fmt.Errorf("include must be a non-empty string")
+			}
+			for _, seen := range stack {
+				if seen == ref {
+					return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), ref)
+				}
+			}
+			if len(stack) >= maxIncludeDepth {
+				return nil, fmt.Errorf("includes nested deeper than %d levels at %q", maxIncludeDepth, ref)
+			}
+			fragment, err := loadIncludeFragment(ref, projectPath)
+			if err != nil {
+				return nil, err
+			}
+			expanded, err := expandIncludes(fragment, projectPath, append(stack, ref))
+			if err != nil {
+				return nil, err
+			}
+			merged := expanded.(map[string]any)
+			for k, val := range v {
+				if k != "include" {
+					merged[k] = val
+				}
+			}
+			delete(merged, "include")
+			v = merged
+		}
+		if raw, ok := v["codeFrom"]; ok {
+			ref, isString := raw.(string)
+			if !isString || strings.TrimSpace(ref) == "" {
+				return nil, fmt.Errorf("codeFrom must be a non-empty string")
+			}
+			if existing, _ := v["code"].(string); existing == "" {
+				data, _, err := readPartial(ref, projectPath)
+				if err != nil {
+					return nil, err
+				}
+				v["code"] = string(data)
+			}
+			delete(v, "codeFrom")
+		}
+		for k, child := range v {
+			expanded, err := expandIncludes(child, projectPath, stack)
+			if err != nil {
+				return nil, err
+			}
+			v[k] = expanded
+		}
+		return v, nil
+	case []any:
+		for i, child := range v {
+			expanded, err := expandIncludes(child, projectPath, stack)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+		return v, nil
+	}
+	return value, nil
+}
+
+// ResolveTemplateIncludes expands "include" and "codeFrom" references in template bytes,
+// after materializing "extends" for templates that did not come from LoadCommandTemplate.
+// Templates without references are returned unchanged.
+func ResolveTemplateIncludes(templateBytes []byte, projectPath string) ([]byte, error) {
+	templateBytes, err := resolveTemplateExtends(templateBytes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Contains(templateBytes, []byte(`"include"`)) && !bytes.Contains(templateBytes, []byte(`"codeFrom"`)) {
+		return templateBytes, nil
+	}
+	doc, err := decodeTemplateJSON(templateBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse JSON template: %w", err)
+	}
+	expanded, err := expandIncludes(doc, projectPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve template includes: %w", err)
+	}
+	out, err := json.Marshal(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode resolved template: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestResolveTemplateIncludes tests node includes, field overrides, codeFrom and cycle detection.
+func TestResolveTemplateIncludes(t *testing.T) {
+	projectPath := t.TempDir()
+	t.Setenv("NEXTGEN_TEMPLATES_DIR", t.TempDir())
+	partials := filepath.Join(projectPath, ".nextgen", "partials")
+	if err := os.MkdirAll(partials, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"index.json":    `{"node":{"_type":"treeNode","name":"index.ts","codeFrom":"partials/index.ts.tmpl"}}`,
+		"index.ts.tmpl": "export * from './{{.KebabName}}'\n",
+		"loop.json":     `{"node":{"include":"partials/loop.json#node"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(partials, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src := []byte(`{"filePaths":[{"path":"src","nodes":[{"include":"partials/index.json#node","name":"{{.KebabName}}.ts"}]}]}`)
+	out, err := ResolveTemplateIncludes(src, projectPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var tmpl JSONCommandTemplate
+	if err := json.Unmarshal(out, &tmpl); err != nil {
+		t.Fatal(err)
+	}
+	node := tmpl.FilePaths[0].Nodes[0]
+	if node.Name != "{{.KebabName}}.ts" || node.Type != "treeNode" || node.Code != files["index.ts.tmpl"] {
+		t.Errorf("unexpected node after include: %+v", node)
+	}
+
+	loop := []byte(`{"filePaths":[{"path":"src","nodes":[{"include":"partials/loop.json#node"}]}]}`)
+	if _, err := ResolveTemplateIncludes(loop, projectPath); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected include cycle error, got %v", err)
+	}
+
+	for _, ref := range []string{"../secret.json", "/etc/passwd"} {
+		bad := []byte(`{"filePaths":[{"path":"src","nodes":[{"codeFrom":"` + ref + `"}]}]}`)
+		if _, err := ResolveTemplateIncludes(bad, projectPath); err == nil {
+			t.Errorf("expected error for reference %q", ref)
+		}
+	}
+}
+
+// TestPartialTemplatesMatchPreRefactor tests that templates sharing nodes and actions
+// through partials or extends resolve to what they contained before the refactor.
+// Ids, node _key values and node order are ignored: they only name nodes for
+// patches, and rebuilding a variant with extends can reorder its siblings.
+func TestPartialTemplatesMatchPreRefactor(t *testing.T) {
+	t.Setenv("NEXTGEN_TEMPLATES_DIR", t.TempDir())
+	templates := map[string]string{
+		"add-page-type-with-block-editor.json":                               "sanity-template-nextjs-clean/add-page-type/with-block-editor/add-page-type-with-block-editor.json",
+		"add-page-type-with-pagebuilder.json":                                "sanity-template-nextjs-clean/add-page-type/with-pagebuilder/add-page-type-with-pagebuilder.json",
+		"add-pagebuilder-block.json":                                         "sanity-template-nextjs-clean/add-pagebuilder-block/add-pagebuilder-block.json",
+		"nextjs-add-index-and-slug-for-app-router-singular.json":             "nextjs/add-index-and-slug/app-router/singular/nextjs-add-index-and-slug-for-app-router-singular.json",
+		"nextjs-add-index-and-slug-for-page-router-singular.json":            "nextjs/add-index-and-slug/page-router/singular/nextjs-add-index-and-slug-for-page-router-singular.json",
+		"nextjs-add-index-and-slug-for-app-router-singular-and-plural.json":  "nextjs/add-index-and-slug/app-router/singular-and-plural/nextjs-add-index-and-slug-for-app-router-singular-and-plural.json",
+		"nextjs-add-index-and-slug-for-page-router-singular-and-plural.json": "nextjs/add-index-and-slug/page-router/singular-and-plural/nextjs-add-index-and-slug-for-page-router-singular-and-plural.json",
+	}
+	decode := func(data []byte) JSONCommandTemplate {
+		t.Helper()
+		var tmpl JSONCommandTemplate
+		if err := json.Unmarshal(data, &tmpl); err != nil {
+			t.Fatal(err)
+		}
+		var normalize func(nodes []TreeNode)
+		normalize = func(nodes []TreeNode) {
+			for i := range nodes {
+				nodes[i].Key = ""
+				nodes[i].ID = ""
+				normalize(nodes[i].Children)
+			}
+			sortByJSON(t, nodes)
+		}
+		for i := range tmpl.FilePaths {
+			tmpl.FilePaths[i].Key = ""
+			tmpl.FilePaths[i].ID = ""
+			normalize(tmpl.FilePaths[i].Nodes)
+		}
+		sortByJSON(t, tmpl.FilePaths)
+		return tmpl
+	}
+	for golden, path := range templates {
+		want, err := os.ReadFile(filepath.Join("testdata", "pre-partials", golden))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := commandFiles.ReadFile("native-commands/" + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ResolveTemplateIncludes(data, t.TempDir())
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if !reflect.DeepEqual(decode(got), decode(want)) {
+			t.Errorf("%s resolves differently from testdata/pre-partials/%s", path, golden)
+		}
+	}
+}
+
+// sortByJSON orders items by their JSON encoding so comparisons ignore order.
+func sortByJSON[T any](t *testing.T, items []T) {
+	t.Helper()
+	keys := make(map[int]string, len(items))
+	for i, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = string(data)
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return keys[idx[a]] < keys[idx[b]] })
+	sorted := make([]T, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [OVERRIDES] Project-level overrides of built-in commands
+// -----------------------------------------------------------------------------
+
+// A template in .nextgen/local-commands whose slug matches a registered command shadows
+// that command for the project. The command keeps its name and visibility, but its
+// TemplatePath points at the project's template, so every lookup by name or slug
+// (GetCommandSpec, TemplatePathFor, previews, execution and the MDC output) resolves to
+// the override. The built-in template stays in the registry under BuiltinPath.
+
+// Registry keys of override templates start with localCommandsRoot.
+const localCommandsRoot = "local-commands"
+
+// LocalCommandsDir returns the directory holding a project's local commands.
+func LocalCommandsDir(projectPath string) string {
+	return filepath.Join(projectPath, ".nextgen", "local-commands")
+}
+
+// Overridden reports whether the command is shadowed by a project template.
+func (c CommandSpec) Overridden() bool {
+	return c.BuiltinPath != ""
+}
+
+// LoadLocalOverrides registers the overrides found in the project's local commands.
+// Calling it again replaces the overrides of a previous call.
+func LoadLocalOverrides(projectPath string) {
+	loadPendingCommands()
+	restoreBuiltinCommands()
+	dir := LocalCommandsDir(projectPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var m struct {
+			Slug      string `json:"slug"`
+			FilePaths []any  `json:"filePaths"`
+			Run       []any  `json:"run"`
+		}
+		// Shell commands and unparsable files are plain local commands, not overrides
+		if json.Unmarshal(data, &m) != nil || (len(m.FilePaths) == 0 && len(m.Run) == 0) {
+			continue
+		}
+		// Like registered templates, the slug defaults to the file name
+		slug := strings.TrimSpace(m.Slug)
+		if slug == "" {
+			slug = strings.TrimSuffix(e.Name(), ".json")
+		}
+		for i := range Commands {
+			c := &Commands[i]
+			if !c.Overridden() && strings.EqualFold(c.Slug, slug) {
+				key := localCommandsRoot + "/" + e.Name()
+				templateRegistry[key] = data
+				c.BuiltinPath, c.TemplatePath = c.TemplatePath, key
+				break
+			}
+		}
+	}
+}
+
+// restoreBuiltinCommands undoes every override.
+func restoreBuiltinCommands() {
+	for i := range Commands {
+		c := &Commands[i]
+		if c.Overridden() {
+			delete(templateRegistry, c.TemplatePath)
+			c.TemplatePath, c.BuiltinPath = c.BuiltinPath, ""
+		}
+	}
+}
+
+// IsOverridden reports whether the command with exactly this name is shadowed by a
+// project template.
+func IsOverridden(cmdName string) bool {
+	for _, c := range RegisteredCommands() {
+		if c.Name == cmdName {
+			return c.Overridden()
+		}
+	}
+	return false
+}
+
+// OverrideForLocalFile returns the command shadowed by the local command file
+// .nextgen/local-commands/<name>.json, if it is an override.
+func OverrideForLocalFile(name string) (CommandSpec, bool) {
+	key := localCommandsRoot + "/" + strings.TrimSuffix(name, ".json") + ".json"
+	for _, c := range RegisteredCommands() {
+		if c.Overridden() && c.TemplatePath == key {
+			return c, true
+		}
+	}
+	return CommandSpec{}, false
+}
+
+// DiffBuiltin returns a unified diff from the built-in template of an overridden command
+// to the project's override. Both sides are fully resolved and printed as indented JSON,
+// so the diff shows what actually changes rather than formatting.
+func DiffBuiltin(nameOrSlug string) (string, error) {
+	spec := GetCommandSpec(nameOrSlug)
+	if spec.TemplatePath == "" {
+		return "", fmt.Errorf("unknown command %q", nameOrSlug)
+	}
+	if !spec.Overridden() {
+		return "", fmt.Errorf("command %q is not overridden in this project", spec.Slug)
+	}
+	builtin, err := normalizedTemplate(spec.BuiltinPath)
+	if err != nil {
+		return "", err
+	}
+	override, err := normalizedTemplate(spec.TemplatePath)
+	if err != nil {
+		return "", err
+	}
+	return UnifiedDiff(spec.BuiltinPath, ".nextgen/"+spec.TemplatePath, builtin, override), nil
+}
+
+// normalizedTemplate loads a registry template and re-encodes it as indented JSON.
+func normalizedTemplate(path string) (string, error) {
+	data, err := LoadCommandTemplate(path)
+	if err != nil {
+		return "", err
+	}
+	doc, err := decodeTemplateJSON(data)
+	if err != nil {
+		return "", fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
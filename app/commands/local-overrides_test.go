@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoadLocalOverrides tests that a project template shadows the built-in with its slug.
+func TestLoadLocalOverrides(t *testing.T) {
+	var builtin CommandSpec
+	for _, c := range RegisteredCommands() {
+		if strings.HasPrefix(c.TemplatePath, nativeTemplatesRoot+"/") {
+			builtin = c
+			break
+		}
+	}
+	if builtin.Slug == "" {
+		t.Skip("no built-in commands registered")
+	}
+
+	projectPath := t.TempDir()
+	override := `{"title": "Project block", "slug": "` + builtin.Slug + `", "filePaths": [{"path": "src", "nodes": [{"_type": "treeNode", "type": "file", "name": "{{.KebabName}}.ts", "code": "project"}]}]}`
+	if err := os.MkdirAll(LocalCommandsDir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(LocalCommandsDir(projectPath), "my-block.json"), []byte(override), 0644); err != nil {
+		t.Fatal(err)
+	}
+	LoadLocalOverrides(projectPath)
+	defer LoadLocalOverrides(t.TempDir())
+
+	spec := GetCommandSpec(builtin.Slug)
+	if spec.TemplatePath != "local-commands/my-block.json" || spec.BuiltinPath != builtin.TemplatePath || spec.Name != builtin.Name {
+		t.Fatalf("override not applied: %+v", spec)
+	}
+	if !IsOverridden(builtin.Name) {
+		t.Error("expected IsOverridden to report the override")
+	}
+	if data, source, err := LoadTemplateBytesForName(builtin.Name, projectPath, nil); err != nil || source != "builtin" || string(data) != override {
+		t.Errorf("expected override bytes, got %q from %s (%v)", data, source, err)
+	}
+	if _, ok := OverrideForLocalFile("my-block"); !ok {
+		t.Error("expected my-block to be reported as an override")
+	}
+	if diff, err := DiffBuiltin(builtin.Slug); err != nil || !strings.Contains(diff, "+++ .nextgen/local-commands/my-block.json") {
+		t.Errorf("unexpected diff (%v):\n%s", err, diff)
+	}
+
+	// Reloading for a project without overrides restores the built-in
+	LoadLocalOverrides(t.TempDir())
+	if spec := GetCommandSpec(builtin.Slug); spec.TemplatePath != builtin.TemplatePath || spec.Overridden() {
+		t.Errorf("built-in not restored: %+v", spec)
+	}
+}
+
+// TestUnifiedDiff tests hunk output for changed lines.
+func TestUnifiedDiff(t *testing.T) {
+	from := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	to := "a\nb\nc\nd\nE\nf\ng\nh\ni\nj\nk\n"
+	expected := "--- old\n+++ new\n@@ -2,9 +2,10 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n i\n j\n+k\n"
+	if got := UnifiedDiff("old", "new", from, to); got != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", got, expected)
+	}
+	if got := UnifiedDiff("old", "new", from, from); got != "" {
+		t.Errorf("expected no diff for equal texts, got %q", got)
+	}
+}
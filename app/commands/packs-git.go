@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [PACKS] Git sources: packs read from a commit of a local clone
+// -----------------------------------------------------------------------------
+
+// PackGitSource pins a pack to a commit of a local git repository.
+type PackGitSource struct {
+	Repo   string `json:"repo"`           // path to the local clone, relative to the project
+	Ref    string `json:"ref"`            // ref the pack follows, e.g. "main" or "v2"
+	Commit string `json:"commit"`         // resolved commit the pack is pinned to
+	Path   string `json:"path,omitempty"` // pack folder within the repository, if not the root
+}
+
+// PackUpdate summarizes how `ng pack update` changed one pack.
+type PackUpdate struct {
+	Name       string
+	OldVersion string
+	NewVersion string
+	OldCommit  string
+	NewCommit  string
+	Added      []string
+	Changed    []string
+	Removed    []string
+	UpToDate   bool
+}
+
+// runGit runs git against repo and returns trimmed stdout.
+func runGit(repo string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// resolveGitCommit resolves ref (default HEAD) to a full commit hash in repo.
+func resolveGitCommit(repo, ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" {
+		ref = "HEAD"
+	}
+	if strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q", ref)
+	}
+	return runGit(repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
+
+// validGitPackPath reports whether p, a pack folder recorded in the lockfile, stays inside
+// the repository: like partial references, it must be relative and must not climb out
+// with "..".
+func validGitPackPath(p string) bool {
+	if p == "" {
+		return true
+	}
+	clean := path.Clean(filepath.ToSlash(p))
+	return !path.IsAbs(clean) && !filepath.IsAbs(p) && clean != ".." && !strings.HasPrefix(clean, "../")
+}
+
+// stageGitPack exports the pack at the pinned commit into a temporary directory.
+func stageGitPack(projectPath string, src PackGitSource) (string, func(), error) {
+	repo := resolveLockSource(projectPath, src.Repo)
+	tmp, err := os.MkdirTemp("", "nextgen-pack-git-")
+	if err != nil {
+		return "", func() {}, err
+	}
+	cleanup := func() { os.RemoveAll(tmp) }
+	archive := filepath.Join(tmp, "pack.tar")
+	args := []string{"archive", "--format=tar", "-o", archive, src.Commit}
+	if src.Path != "" {
+		args = append(args, "--", src.Path)
+	}
+	if _, err := runGit(repo, args...); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	out := filepath.Join(tmp, "tree")
+	if err := extractTarball(archive, out); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	return filepath.Join(out, filepath.FromSlash(src.Path)), cleanup, nil
+}
+
+// installGitPack installs the pack pinned by src.
+func installGitPack(projectPath string, src PackGitSource, wantIntegrity string) (PackManifest, PackLockEntry, error) {
+	dir, cleanup, err := stageGitPack(projectPath, src)
+	if err != nil {
+		return PackManifest{}, PackLockEntry{}, err
+	}
+	defer cleanup()
+	entry := PackLockEntry{Source: "git:" + src.Repo, Git: &src}
+	return installPackDir(projectPath, dir, entry, wantIntegrity)
+}
+
+// AddGitPack installs a pack from a local git clone at ref (default HEAD). subdir selects
+// the pack folder when the pack is not at the repository root.
+func AddGitPack(projectPath, repo, ref, subdir string) (PackManifest, error) {
+	repo = resolveLockSource(projectPath, repo)
+	if _, err := runGit(repo, "rev-parse", "--git-dir"); err != nil {
+		return PackManifest{}, fmt.Errorf("%s is not a git repository: %w", repo, err)
+	}
+	if strings.TrimSpace(ref) == "" {
+		ref = "HEAD"
+	}
+	commit, err := resolveGitCommit(repo, ref)
+	if err != nil {
+		return PackManifest{}, err
+	}
+	subdir = strings.Trim(path.Clean("/"+filepath.ToSlash(subdir)), "/")
+	src := PackGitSource{Repo: lockSource(projectPath, repo), Ref: ref, Commit: commit, Path: subdir}
+	m, _, err := installGitPack(projectPath, src, "")
+	return m, err
+}
+
+// UpdatePacks moves installed packs to the latest content of their source: git packs are
+// re-resolved from their ref (or newRef, when given) and path packs are re-read. names
+// limits the update to specific packs; empty means all.
+func UpdatePacks(projectPath string, names []string, newRef string) ([]PackUpdate, error) {
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for name := range lock.Packs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	var updates []PackUpdate
+	for _, name := range names {
+		old, ok := lock.Packs[name]
+		if !ok {
+			return updates, fmt.Errorf("pack %s is not installed", name)
+		}
+		u := PackUpdate{Name: name, OldVersion: old.Version}
+		var entry PackLockEntry
+		if old.Git != nil {
+			src := *old.Git
+			if newRef != "" {
+				src.Ref = newRef
+			}
+			commit, err := resolveGitCommit(resolveLockSource(projectPath, src.Repo), src.Ref)
+			if err != nil {
+				return updates, fmt.Errorf("failed to update %s: %w", name, err)
+			}
+			u.OldCommit, u.NewCommit = old.Git.Commit, commit
+			if commit == old.Git.Commit && src.Ref == old.Git.Ref {
+				u.NewVersion, u.UpToDate = old.Version, true
+				updates = append(updates, u)
+				continue
+			}
+			src.Commit = commit
+			if _, entry, err = installGitPack(projectPath, src, ""); err != nil {
+				return updates, fmt.Errorf("failed to update %s: %w", name, err)
+			}
+		} else {
+			if _, err := installPack(projectPath, old.Source, ""); err != nil {
+				return updates, fmt.Errorf("failed to update %s: %w", name, err)
+			}
+			refreshed, _ := LoadPackLock(projectPath)
+			entry = refreshed.Packs[name]
+		}
+		u.NewVersion = entry.Version
+		u.Added, u.Changed, u.Removed = diffPackFiles(name, old.Files, entry.Files)
+		u.UpToDate = entry.Integrity == old.Integrity
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+// diffPackFiles compares lockfile digests and describes changed templates. Templates that
+// are registered as commands are reported by slug, other files by path.
+func diffPackFiles(name string, oldFiles, newFiles map[string]string) (added, changed, removed []string) {
+	label := func(rel string) string {
+		if spec, ok := FindCommandByTemplatePath(packsRoot + "/" + name + "/" + rel); ok && spec.Slug != "" {
+			return spec.Slug + " (" + rel + ")"
+		}
+		return rel
+	}
+	for rel, sum := range newFiles {
+		if oldSum, ok := oldFiles[rel]; !ok {
+			added = append(added, label(rel))
+		} else if oldSum != sum {
+			changed = append(changed, label(rel))
+		}
+	}
+	for rel := range oldFiles {
+		if _, ok := newFiles[rel]; !ok {
+			removed = append(removed, rel)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return added, changed, removed
+}
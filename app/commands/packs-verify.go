@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [PACKS] Integrity: manifest digests, signatures and lockfile drift
+// -----------------------------------------------------------------------------
+
+// A manifest may list the sha256 digest of every file in the pack under "files". When it
+// does, installing refuses packs whose contents differ from the list. A pack may also ship
+// pack.sig, a base64 ed25519 signature over the exact bytes of pack.json; it must verify
+// against one of the public keys in TrustedKeysDir(). The signature only covers the
+// templates through those digests, so a signed manifest must list every file. Once installed, templates are checked
+// against the digests pinned in the lockfile every time packs are loaded.
+
+const packSignatureName = "pack.sig"
+
+// TrustedKey is an ed25519 public key trusted to sign packs.
+type TrustedKey struct {
+	Name string // file name in the trusted keys directory
+	Key  ed25519.PublicKey
+}
+
+// PackVerification reports how an installed pack compares to its lockfile entry.
+type PackVerification struct {
+	Name       string
+	Version    string
+	SignedBy   string
+	Modified   []string // files whose digest differs from the lockfile
+	Missing    []string // files pinned in the lockfile but not on disk
+	Unexpected []string // files on disk that the lockfile does not know
+	Problem    string   // manifest digest or signature failure
+}
+
+// Drifted reports whether the pack no longer matches what was installed.
+func (v PackVerification) Drifted() bool {
+	return len(v.Modified) > 0 || len(v.Missing) > 0 || len(v.Unexpected) > 0 || v.Problem != ""
+}
+
+// TrustedKeysDir returns the directory of trusted pack signing keys. It can be overridden
+// with NEXTGEN_TRUSTED_KEYS_DIR and defaults to ~/.config/nextgen-cli/trusted-keys.
+func TrustedKeysDir() string {
+	if dir := strings.TrimSpace(os.Getenv("NEXTGEN_TRUSTED_KEYS_DIR")); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "nextgen-cli", "trusted-keys")
+}
+
+// LoadTrustedKeys reads every key in TrustedKeysDir(). Each file holds one base64-encoded
+// ed25519 public key, optionally prefixed with "ed25519 ".
+func LoadTrustedKeys() ([]TrustedKey, error) {
+	dir := TrustedKeysDir()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) || dir == "" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	var keys []TrustedKey
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read trusted key %s: %w", e.Name(), err)
+		}
+		text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "ed25519 "))
+		raw, err := base64.StdEncoding.DecodeString(text)
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trusted key %s is not a base64 ed25519 public key", e.Name())
+		}
+		keys = append(keys, TrustedKey{Name: e.Name(), Key: ed25519.PublicKey(raw)})
+	}
+	return keys, nil
+}
+
+// checkPackContents verifies the manifest digests and signature of the pack in dir, given
+// the digests of its files. It returns the name of the trusted key that signed the pack,
+// or "" for unsigned packs.
+func checkPackContents(dir string, manifest PackManifest, files map[string]string) (string, error) {
+	if _, err := os.Stat(filepath.Join(dir, packSignatureName)); err == nil && len(manifest.Files) == 0 {
+		return "", fmt.Errorf("pack %s@%s is signed but its manifest lists no file digests, so the signature does not cover its templates", manifest.Name, manifest.Version)
+	}
+	if len(manifest.Files) > 0 {
+		var problems []string
+		for rel, sum := range files {
+			if rel == packManifestName || rel == packSignatureName {
+				continue
+			}
+			want, ok := manifest.Files[rel]
+			if !ok {
+				problems = append(problems, rel+" (not listed)")
+			} else if !strings.EqualFold(want, sum) {
+				problems = append(problems, rel+" (digest mismatch)")
+			}
+		}
+		for rel := range manifest.Files {
+			if _, ok := files[rel]; !ok {
+				problems = append(problems, rel+" (missing)")
+			}
+		}
+		if len(problems) > 0 {
+			sort.Strings(problems)
+			return "", fmt.Errorf("pack %s@%s does not match its manifest digests: %s", manifest.Name, manifest.Version, strings.Join(problems, ", "))
+		}
+	}
+	return verifyPackSignature(dir, manifest)
+}
+
+// verifyPackSignature checks pack.sig against the trusted keys. Unsigned packs pass.
+func verifyPackSignature(dir string, manifest PackManifest) (string, error) {
+	sigData, err := os.ReadFile(filepath.Join(dir, packSignatureName))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", packSignatureName, err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", fmt.Errorf("pack %s has a malformed %s", manifest.Name, packSignatureName)
+	}
+	manifestData, err := os.ReadFile(filepath.Join(dir, packManifestName))
+	if err != nil {
+		return "", err
+	}
+	keys, err := LoadTrustedKeys()
+	if err != nil {
+		return "", err
+	}
+	for _, k := range keys {
+		if ed25519.Verify(k.Key, manifestData, sig) {
+			return k.Name, nil
+		}
+	}
+	return "", fmt.Errorf("signature of pack %s@%s does not match any trusted key in %s", manifest.Name, manifest.Version, TrustedKeysDir())
+}
+
+// comparePackFiles compares the digests on disk with the expected ones.
+func comparePackFiles(expected, actual map[string]string) (modified, missing, unexpected []string) {
+	for rel, sum := range actual {
+		if want, ok := expected[rel]; !ok {
+			unexpected = append(unexpected, rel)
+		} else if want != sum {
+			modified = append(modified, rel)
+		}
+	}
+	for rel := range expected {
+		if _, ok := actual[rel]; !ok {
+			missing = append(missing, rel)
+		}
+	}
+	sort.Strings(modified)
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return modified, missing, unexpected
+}
+
+// tamperedPackFiles returns the files of an installed pack that must not be loaded because
+// they differ from the lockfile (or, for packs without a lockfile entry, from the manifest
+// digests). verified is false when there is nothing to check the pack against.
+func tamperedPackFiles(dir string, entry *PackLockEntry) (tampered map[string]bool, verified bool) {
+	var expected map[string]string
+	skip := map[string]bool{}
+	if entry != nil {
+		expected = entry.Files
+	} else if m, err := readPackManifest(dir); err == nil && len(m.Files) > 0 {
+		expected = map[string]string{}
+		for rel, sum := range m.Files {
+			expected[rel] = strings.ToLower(sum)
+		}
+		skip[packManifestName], skip[packSignatureName] = true, true
+	} else {
+		return nil, false
+	}
+	actual, err := hashPackFiles(dir)
+	if err != nil {
+		return nil, false
+	}
+	modified, _, unexpected := comparePackFiles(expected, actual)
+	tampered = map[string]bool{}
+	for _, rel := range append(modified, unexpected...) {
+		if !skip[rel] {
+			tampered[rel] = true
+		}
+	}
+	return tampered, true
+}
+
+// allowUnverifiedPacks reports whether packs with nothing to verify them against may be
+// loaded anyway, opted into with NEXTGEN_ALLOW_UNVERIFIED_PACKS=1.
+func allowUnverifiedPacks() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("NEXTGEN_ALLOW_UNVERIFIED_PACKS"))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// unlockedPackNames returns the pack folders in .nextgen/packs that have no lockfile entry.
+func unlockedPackNames(projectPath string, lock PackLock) []string {
+	entries, err := os.ReadDir(PacksDir(projectPath))
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, e := range entries {
+		if _, ok := lock.Packs[e.Name()]; e.IsDir() && !ok && !strings.HasPrefix(e.Name(), ".") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// verifyUnlockedPack checks a pack folder that has no lockfile entry against its manifest
+// digests and signature. Without digests it cannot be verified and is reported as such.
+func verifyUnlockedPack(dir, name string) PackVerification {
+	v := PackVerification{Name: name}
+	m, err := readPackManifest(dir)
+	if err != nil {
+		v.Problem = fmt.Sprintf("not in %s and %v", packsLockName, err)
+		return v
+	}
+	v.Version = m.Version
+	if len(m.Files) == 0 {
+		v.Problem = fmt.Sprintf("unverified: not in %s and its manifest lists no file digests", packsLockName)
+		return v
+	}
+	actual, err := hashPackFiles(dir)
+	if err != nil {
+		v.Problem = fmt.Sprintf("failed to hash pack %s: %v", name, err)
+	} else if signer, err := checkPackContents(dir, m, actual); err != nil {
+		v.Problem = err.Error()
+	} else {
+		v.SignedBy = signer
+	}
+	return v
+}
+
+// VerifyPacks compares installed packs with the lockfile, and re-checks their manifest
+// digests and signatures. Pack folders missing from the lockfile are checked against their
+// manifest digests alone, and reported as unverified when there are none. names limits the
+// check to specific packs; empty means all.
+func VerifyPacks(projectPath string, names []string) ([]PackVerification, error) {
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		for name := range lock.Packs {
+			names = append(names, name)
+		}
+		names = append(names, unlockedPackNames(projectPath, lock)...)
+		sort.Strings(names)
+	}
+	var results []PackVerification
+	for _, name := range names {
+		entry, ok := lock.Packs[name]
+		if !ok {
+			dir := filepath.Join(PacksDir(projectPath), name)
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() || !packNameRegex.MatchString(name) {
+				return results, fmt.Errorf("pack %s is not installed", name)
+			}
+			results = append(results, verifyUnlockedPack(dir, name))
+			continue
+		}
+		v := PackVerification{Name: name, Version: entry.Version}
+		dir := filepath.Join(PacksDir(projectPath), name)
+		actual, err := hashPackFiles(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return results, fmt.Errorf("failed to hash pack %s: %w", name, err)
+		}
+		v.Modified, v.Missing, v.Unexpected = comparePackFiles(entry.Files, actual)
+		if len(actual) > 0 {
+			if m, err := readPackManifest(dir); err != nil {
+				v.Problem = err.Error()
+			} else if signer, err := checkPackContents(dir, m, actual); err != nil {
+				v.Problem = err.Error()
+			} else {
+				v.SignedBy = signer
+			}
+		}
+		results = append(results, v)
+	}
+	return results, nil
+}
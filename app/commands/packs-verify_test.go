@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSignedManifest rewrites pack.json with the digests of the pack files and, when
+// priv is set, signs it into pack.sig.
+func writeSignedManifest(t *testing.T, dir string, priv ed25519.PrivateKey) {
+	t.Helper()
+	files, err := hashPackFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(files, packManifestName)
+	delete(files, packSignatureName)
+	writeManifest(t, dir, files, priv)
+}
+
+// writeManifest writes pack.json with the given file digests and, when priv is set, signs
+// it into pack.sig.
+func writeManifest(t *testing.T, dir string, files map[string]string, priv ed25519.PrivateKey) {
+	t.Helper()
+	m := PackManifest{Name: "acme", Version: "1.0.0", Identifiers: []string{"acme-ui"}, Files: files}
+	data, _ := json.Marshal(m)
+	if err := os.WriteFile(filepath.Join(dir, packManifestName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if priv != nil {
+		sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, data))
+		if err := os.WriteFile(filepath.Join(dir, packSignatureName), []byte(sig), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestPackIntegrity tests manifest digests, signatures and drift detection.
+func TestPackIntegrity(t *testing.T) {
+	projectPath := t.TempDir()
+	source := filepath.Join(projectPath, "vendor-packs", "acme")
+	keysDir := t.TempDir()
+	t.Setenv("NEXTGEN_TRUSTED_KEYS_DIR", keysDir)
+	defer unregisterTemplates(packsRoot + "/")
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	writePackFixture(t, source, "1.0.0")
+	writeSignedManifest(t, source, priv)
+
+	// The signing key is not trusted yet
+	if _, err := InstallPack(projectPath, source); err == nil {
+		t.Fatal("expected untrusted signature to be refused")
+	}
+	if err := os.WriteFile(filepath.Join(keysDir, "acme.pub"), []byte("ed25519 "+base64.StdEncoding.EncodeToString(pub)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallPack(projectPath, source); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if lock, _ := LoadPackLock(projectPath); lock.Packs["acme"].SignedBy != "acme.pub" {
+		t.Errorf("expected signer to be recorded, got %+v", lock.Packs["acme"])
+	}
+
+	// Templates that differ from the manifest digests are refused at install time
+	template := filepath.Join("acme-ui", "button", "btn.json")
+	if err := os.WriteFile(filepath.Join(source, template), []byte(`{"title": "Evil"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallPack(projectPath, source); err == nil {
+		t.Error("expected digest mismatch to be refused")
+	}
+
+	// Installed templates that drift from the lockfile are reported and not loaded
+	if err := os.WriteFile(filepath.Join(PacksDir(projectPath), "acme", template), []byte(`{"title": "Evil", "slug": "acme-button", "filePaths": [{"path": "src", "nodes": []}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results, err := VerifyPacks(projectPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Drifted() || len(results[0].Modified) != 1 || results[0].Modified[0] != "acme-ui/button/btn.json" {
+		t.Errorf("expected modified template to be reported, got %+v", results)
+	}
+	LoadProjectPacks(projectPath)
+	if spec := GetCommandSpec("acme-button"); spec.TemplatePath != "" {
+		t.Errorf("tampered template was loaded: %+v", spec)
+	}
+}
+
+// TestSignedPackWithoutDigests tests that a signature over a manifest without file digests,
+// which covers none of the templates, is refused.
+func TestSignedPackWithoutDigests(t *testing.T) {
+	projectPath := t.TempDir()
+	source := filepath.Join(projectPath, "vendor-packs", "acme")
+	keysDir := t.TempDir()
+	t.Setenv("NEXTGEN_TRUSTED_KEYS_DIR", keysDir)
+	defer unregisterTemplates(packsRoot + "/")
+
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	if err := os.WriteFile(filepath.Join(keysDir, "acme.pub"), []byte(base64.StdEncoding.EncodeToString(pub)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writePackFixture(t, source, "1.0.0")
+	writeManifest(t, source, nil, priv)
+	if _, err := InstallPack(projectPath, source); err == nil || !strings.Contains(err.Error(), "lists no file digests") {
+		t.Fatalf("expected the signed manifest without digests to be refused, got %v", err)
+	}
+
+	// Unsigned packs without digests still install
+	if err := os.Remove(filepath.Join(source, packSignatureName)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := InstallPack(projectPath, source); err != nil {
+		t.Fatalf("unsigned pack refused: %v", err)
+	}
+	// A signature added after installing is reported by pack verify
+	if err := os.WriteFile(filepath.Join(PacksDir(projectPath), "acme", packSignatureName), []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte("{}")))), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results, err := VerifyPacks(projectPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !strings.Contains(results[0].Problem, "lists no file digests") {
+		t.Errorf("expected pack verify to report the uncovered signature, got %+v", results)
+	}
+}
+
+// TestUnlockedPackIsUnverified tests that a pack folder with neither a lockfile entry nor
+// manifest digests fails pack verify and is only loaded when explicitly allowed.
+func TestUnlockedPackIsUnverified(t *testing.T) {
+	projectPath := t.TempDir()
+	t.Setenv("NEXTGEN_TRUSTED_KEYS_DIR", t.TempDir())
+	t.Setenv("NEXTGEN_ALLOW_UNVERIFIED_PACKS", "")
+	dir := filepath.Join(PacksDir(projectPath), "acme")
+	writePackFixture(t, dir, "1.0.0")
+	defer unregisterTemplates(packsRoot + "/")
+
+	results, err := VerifyPacks(projectPath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Drifted() || !strings.Contains(results[0].Problem, "unverified") {
+		t.Fatalf("expected the pack to be reported as unverified, got %+v", results)
+	}
+	LoadProjectPacks(projectPath)
+	if spec := GetCommandSpec("acme-button"); spec.TemplatePath != "" {
+		t.Errorf("unverified pack was loaded: %+v", spec)
+	}
+
+	t.Setenv("NEXTGEN_ALLOW_UNVERIFIED_PACKS", "1")
+	LoadProjectPacks(projectPath)
+	if spec := GetCommandSpec("acme-button"); spec.TemplatePath == "" {
+		t.Error("expected the unverified pack to load when allowed")
+	}
+
+	// Manifest digests are enough to verify a pack without a lockfile entry
+	writeSignedManifest(t, dir, nil)
+	if results, err := VerifyPacks(projectPath, []string{"acme"}); err != nil || len(results) != 1 || results[0].Drifted() {
+		t.Errorf("expected the pack to verify against its manifest digests, got %+v (%v)", results, err)
+	}
+}
@@ -0,0 +1,699 @@
+package commands
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+)
+
+// -----------------------------------------------------------------------------
+// [PACKS] Installable template packs and the project lockfile
+// -----------------------------------------------------------------------------
+
+// A pack is a directory (or .tar.gz/.tgz/.tar archive of one) with a pack.json manifest
+// plus templates laid out like native-commands. Installed packs are copied to
+// .nextgen/packs/<name>/ and pinned in .nextgen/packs.lock, so checking in the lockfile
+// lets every team member install the same versions with `ng pack install`.
+
+const (
+	packManifestName = "pack.json"
+	packsLockName    = "packs.lock"
+	packsLockVersion = 1
+)
+
+// PackManifest is the pack.json at the root of a pack.
+type PackManifest struct {
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	Description   string   `json:"description,omitempty"`
+	Identifiers   []string `json:"identifiers,omitempty"`   // identifiers the pack's commands are scoped to
+	MinCLIVersion string   `json:"minCliVersion,omitempty"` // oldest CLI version able to run the pack
+
+	Files map[string]string `json:"files,omitempty"` // pack-relative path -> sha256 hex digest of every file
+}
+
+// PackLock is the content of .nextgen/packs.lock.
+type PackLock struct {
+	LockfileVersion int                      `json:"lockfileVersion"`
+	Packs           map[string]PackLockEntry `json:"packs"`
+}
+
+// PackLockEntry pins one installed pack.
+type PackLockEntry struct {
+	Version   string            `json:"version"`
+	Source    string            `json:"source"`
+	Integrity string            `json:"integrity"`
+	Files     map[string]string `json:"files"`              // pack-relative path -> sha256 hex digest
+	Git       *PackGitSource    `json:"git,omitempty"`      // set for packs added from a local git clone
+	SignedBy  string            `json:"signedBy,omitempty"` // trusted key that verified pack.sig
+}
+
+// InstalledPack describes a pack recorded in the lockfile.
+type InstalledPack struct {
+	Manifest PackManifest
+	Lock     PackLockEntry
+}
+
+var packNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// loadedPacksDir is the .nextgen/packs directory whose templates are registered.
+var loadedPacksDir string
+
+// PacksDir returns the directory installed packs are copied to.
+func PacksDir(projectPath string) string {
+	return filepath.Join(projectPath, ".nextgen", "packs")
+}
+
+// PacksLockPath returns the path of the project's pack lockfile.
+func PacksLockPath(projectPath string) string {
+	return filepath.Join(projectPath, ".nextgen", packsLockName)
+}
+
+// LoadPackLock reads .nextgen/packs.lock. A missing lockfile yields an empty lock.
+func LoadPackLock(projectPath string) (PackLock, error) {
+	lock := PackLock{LockfileVersion: packsLockVersion, Packs: map[string]PackLockEntry{}}
+	data, err := os.ReadFile(PacksLockPath(projectPath))
+	if os.IsNotExist(err) {
+		return lock, nil
+	}
+	if err != nil {
+		return lock, fmt.Errorf("failed to read %s: %w", packsLockName, err)
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return lock, fmt.Errorf("failed to parse %s: %w", packsLockName, err)
+	}
+	if lock.Packs == nil {
+		lock.Packs = map[string]PackLockEntry{}
+	}
+	for name, entry := range lock.Packs {
+		if entry.Git != nil && !validGitPackPath(entry.Git.Path) {
+			return lock, fmt.Errorf("invalid git path %q for pack %s in %s", entry.Git.Path, name, packsLockName)
+		}
+	}
+	return lock, nil
+}
+
+// SavePackLock writes .nextgen/packs.lock.
+func SavePackLock(projectPath string, lock PackLock) error {
+	lock.LockfileVersion = packsLockVersion
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", packsLockName, err)
+	}
+	if err := os.MkdirAll(filepath.Join(projectPath, ".nextgen"), 0755); err != nil {
+		return fmt.Errorf("failed to create .nextgen directory: %w", err)
+	}
+	return os.WriteFile(PacksLockPath(projectPath), append(data, '\n'), 0644)
+}
+
+// readPackManifest reads and validates pack.json in dir.
+func readPackManifest(dir string) (PackManifest, error) {
+	var m PackManifest
+	data, err := os.ReadFile(filepath.Join(dir, packManifestName))
+	if err != nil {
+		return m, fmt.Errorf("not a pack (missing %s): %w", packManifestName, err)
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("failed to parse %s: %w", packManifestName, err)
+	}
+	if !packNameRegex.MatchString(m.Name) {
+		return m, fmt.Errorf("invalid pack name %q (use lowercase letters, digits, '.', '-' or '_')", m.Name)
+	}
+	if strings.TrimSpace(m.Version) == "" {
+		return m, fmt.Errorf("pack %s has no version", m.Name)
+	}
+	if m.MinCLIVersion != "" {
+		if _, _, ok := parseSemver(m.MinCLIVersion); !ok {
+			return m, fmt.Errorf("pack %s@%s has a malformed minCliVersion %q", m.Name, m.Version, m.MinCLIVersion)
+		}
+		// Development builds without a release version skip the check
+		if cmp, err := compareVersions(cli.Version(), m.MinCLIVersion); err == nil && cmp < 0 {
+			return m, fmt.Errorf("pack %s@%s requires CLI %s or newer (running %s)", m.Name, m.Version, m.MinCLIVersion, cli.Version())
+		}
+	}
+	return m, nil
+}
+
+// compareVersions compares versions such as "v1.2.10" and "1.3" with parseSemver,
+// ignoring any pre-release suffix. It returns -1, 0 or 1, or an error naming the version
+// that does not parse.
+func compareVersions(a, b string) (int, error) {
+	va, _, ok := parseSemver(a)
+	if !ok {
+		return 0, fmt.Errorf("invalid version %q", a)
+	}
+	vb, _, ok := parseSemver(b)
+	if !ok {
+		return 0, fmt.Errorf("invalid version %q", b)
+	}
+	switch {
+	case va.less(vb):
+		return -1, nil
+	case vb.less(va):
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// hashPackFiles returns the sha256 digest of every regular file under dir, keyed by
+// slash-separated relative path.
+func hashPackFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		files[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return files, err
+}
+
+// packIntegrity combines per-file digests into a single "sha256-<hex>" value.
+func packIntegrity(files map[string]string) string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	h := sha256.New()
+	for _, p := range paths {
+		fmt.Fprintf(h, "%s %s\n", p, files[p])
+	}
+	return "sha256-" + hex.EncodeToString(h.Sum(nil))
+}
+
+// stagePackSource returns a directory holding the pack at source, extracting archives to
+// a temporary directory. cleanup removes anything created.
+func stagePackSource(source string) (dir string, cleanup func(), err error) {
+	cleanup = func() {}
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("pack source %s: %w", source, err)
+	}
+	if info.IsDir() {
+		return source, cleanup, nil
+	}
+	tmp, err := os.MkdirTemp("", "nextgen-pack-")
+	if err != nil {
+		return "", cleanup, err
+	}
+	cleanup = func() { os.RemoveAll(tmp) }
+	if err := extractTarball(source, tmp); err != nil {
+		cleanup()
+		return "", func() {}, err
+	}
+	// Archives commonly wrap the pack in a single top-level folder
+	if _, statErr := os.Stat(filepath.Join(tmp, packManifestName)); os.IsNotExist(statErr) {
+		if entries, _ := os.ReadDir(tmp); len(entries) == 1 && entries[0].IsDir() {
+			return filepath.Join(tmp, entries[0].Name()), cleanup, nil
+		}
+	}
+	return tmp, cleanup, nil
+}
+
+// extractTarball extracts a .tar, .tar.gz or .tgz archive into dest.
+func extractTarball(archivePath, dest string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	lower := strings.ToLower(archivePath)
+	if strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archivePath, err)
+		}
+		defer gz.Close()
+		r = gz
+	} else if !strings.HasSuffix(lower, ".tar") {
+		return fmt.Errorf("unsupported pack archive %s (expected a directory, .tar, .tar.gz or .tgz)", archivePath)
+	}
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archivePath, err)
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("archive entry %q escapes the pack directory", hdr.Name)
+		}
+		target := filepath.Join(dest, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		}
+	}
+}
+
+// copyPackDir copies the regular files of a pack into dst, skipping hidden folders.
+func copyPackDir(src, dst string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != src && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0644)
+	})
+}
+
+// lockSource records a pack source as a slash path relative to the project, including
+// sources next to it such as ../templates, so the lockfile stays portable across machines.
+// Relative sources are taken relative to the project, as resolveLockSource reads them.
+func lockSource(projectPath, source string) string {
+	abs, err := filepath.Abs(resolveLockSource(projectPath, source))
+	if err != nil {
+		return source
+	}
+	if projAbs, err := filepath.Abs(projectPath); err == nil {
+		if rel, err := filepath.Rel(projAbs, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return abs
+}
+
+// mustAbs returns the absolute form of p, or p itself when it cannot be resolved.
+func mustAbs(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// resolveLockSource turns a lockfile source back into a path.
+func resolveLockSource(projectPath, source string) string {
+	if filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(projectPath, filepath.FromSlash(source))
+}
+
+// InstallPack installs the pack at source (directory or tarball) into the project, records
+// it in the lockfile and registers its commands.
+func InstallPack(projectPath, source string) (PackManifest, error) {
+	return installPack(projectPath, source, "")
+}
+
+// installPack installs a pack from a path source. When wantIntegrity is set, the pack must
+// match it exactly.
+func installPack(projectPath, source, wantIntegrity string) (PackManifest, error) {
+	dir, cleanup, err := stagePackSource(resolveLockSource(projectPath, source))
+	if err != nil {
+		return PackManifest{}, err
+	}
+	defer cleanup()
+	m, _, err := installPackDir(projectPath, dir, PackLockEntry{Source: lockSource(projectPath, source)}, wantIntegrity)
+	return m, err
+}
+
+// installPackDir copies the staged pack in dir into the project and records it in the
+// lockfile. entry carries the source details; version, integrity and digests are filled in.
+func installPackDir(projectPath, dir string, entry PackLockEntry, wantIntegrity string) (PackManifest, PackLockEntry, error) {
+	manifest, err := readPackManifest(dir)
+	if err != nil {
+		return manifest, entry, err
+	}
+	files, err := hashPackFiles(dir)
+	if err != nil {
+		return manifest, entry, fmt.Errorf("failed to hash pack %s: %w", manifest.Name, err)
+	}
+	signer, err := checkPackContents(dir, manifest, files)
+	if err != nil {
+		return manifest, entry, err
+	}
+	integrity := packIntegrity(files)
+	if wantIntegrity != "" && integrity != wantIntegrity {
+		return manifest, entry, fmt.Errorf("pack %s@%s does not match %s (expected %s, got %s)", manifest.Name, manifest.Version, packsLockName, wantIntegrity, integrity)
+	}
+
+	target := filepath.Join(PacksDir(projectPath), manifest.Name)
+	if mustAbs(dir) == mustAbs(target) {
+		return manifest, entry, fmt.Errorf("pack %s is already installed from %s", manifest.Name, entry.Source)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return manifest, entry, fmt.Errorf("failed to replace installed pack %s: %w", manifest.Name, err)
+	}
+	if err := copyPackDir(dir, target); err != nil {
+		return manifest, entry, fmt.Errorf("failed to copy pack %s: %w", manifest.Name, err)
+	}
+
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return manifest, entry, err
+	}
+	entry.Version = manifest.Version
+	entry.Integrity = integrity
+	entry.Files = files
+	entry.SignedBy = signer
+	lock.Packs[manifest.Name] = entry
+	if err := SavePackLock(projectPath, lock); err != nil {
+		return manifest, entry, err
+	}
+	if err := addCommandPackageIdentifiers(projectPath, manifest.Identifiers); err != nil {
+		return manifest, entry, err
+	}
+	LoadProjectPacks(projectPath)
+	return manifest, entry, nil
+}
+
+// InstallPacksFromLock installs every pack pinned in the lockfile from its recorded source
+// (git packs at their pinned commit), refusing packs whose contents no longer match the
+// pinned integrity.
+func InstallPacksFromLock(projectPath string) ([]PackManifest, error) {
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(lock.Packs))
+	for name := range lock.Packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var installed []PackManifest
+	for _, name := range names {
+		entry := lock.Packs[name]
+		var m PackManifest
+		if entry.Git != nil {
+			m, _, err = installGitPack(projectPath, *entry.Git, entry.Integrity)
+		} else {
+			m, err = installPack(projectPath, entry.Source, entry.Integrity)
+		}
+		if err != nil {
+			return installed, fmt.Errorf("failed to install %s: %w", name, err)
+		}
+		installed = append(installed, m)
+	}
+	return installed, nil
+}
+
+// RemovePack deletes an installed pack and its lockfile entry, and drops the identifiers
+// it added to command-packages.json unless another installed pack declares them too.
+func RemovePack(projectPath, name string) error {
+	if !packNameRegex.MatchString(name) || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return fmt.Errorf("invalid pack name %q", name)
+	}
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return err
+	}
+	target := filepath.Join(PacksDir(projectPath), name)
+	_, locked := lock.Packs[name]
+	if _, statErr := os.Stat(target); os.IsNotExist(statErr) && !locked {
+		return fmt.Errorf("pack %s is not installed", name)
+	}
+	// Read the identifiers before the manifest is gone
+	var removed PackManifest
+	if data, err := os.ReadFile(filepath.Join(target, packManifestName)); err == nil {
+		_ = json.Unmarshal(data, &removed)
+	}
+	if err := os.RemoveAll(target); err != nil {
+		return fmt.Errorf("failed to remove pack %s: %w", name, err)
+	}
+	delete(lock.Packs, name)
+	keep := map[string]bool{}
+	for other := range lock.Packs {
+		var m PackManifest
+		if data, err := os.ReadFile(filepath.Join(PacksDir(projectPath), other, packManifestName)); err == nil && json.Unmarshal(data, &m) == nil {
+			for _, id := range m.Identifiers {
+				keep[strings.TrimSpace(id)] = true
+			}
+		}
+	}
+	if err := removeCommandPackageIdentifiers(projectPath, removed.Identifiers, keep); err != nil {
+		return err
+	}
+	unregisterTemplates(packsRoot + "/" + name + "/")
+	return SavePackLock(projectPath, lock)
+}
+
+// ListInstalledPacks returns the packs pinned in the lockfile, sorted by name.
+func ListInstalledPacks(projectPath string) ([]InstalledPack, error) {
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	var out []InstalledPack
+	for name, entry := range lock.Packs {
+		m := PackManifest{Name: name, Version: entry.Version}
+		if data, err := os.ReadFile(filepath.Join(PacksDir(projectPath), name, packManifestName)); err == nil {
+			_ = json.Unmarshal(data, &m)
+		}
+		out = append(out, InstalledPack{Manifest: m, Lock: entry})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Manifest.Name < out[j].Manifest.Name })
+	return out, nil
+}
+
+// readCommandPackages reads .nextgen/command-packages.json in either its array or
+// {"identifiers": [...]} form. obj is set for the object form, so other keys survive a write.
+func readCommandPackages(p string) (list []string, obj map[string]any, err error) {
+	data, readErr := os.ReadFile(p)
+	if readErr != nil || strings.TrimSpace(string(data)) == "" {
+		return nil, nil, nil
+	}
+	if json.Unmarshal(data, &list) != nil {
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", p, err)
+		}
+		list = append(list, toStringList(obj["identifiers"])...)
+	}
+	return list, obj, nil
+}
+
+// writeCommandPackages writes the identifiers back in the form they were read in.
+func writeCommandPackages(p string, list []string, obj map[string]any) error {
+	var out any = list
+	if obj != nil {
+		obj["identifiers"] = list
+		out = obj
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, append(data, '\n'), 0644)
+}
+
+// addCommandPackageIdentifiers merges identifiers into .nextgen/command-packages.json so
+// commands scoped to them become visible. Both the array and {"identifiers": [...]} forms
+// are preserved.
+func addCommandPackageIdentifiers(projectPath string, identifiers []string) error {
+	if len(identifiers) == 0 {
+		return nil
+	}
+	p := filepath.Join(projectPath, ".nextgen", "command-packages.json")
+	list, obj, err := readCommandPackages(p)
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, v := range list {
+		seen[strings.TrimSpace(v)] = true
+	}
+	changed := false
+	for _, id := range identifiers {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			list = append(list, id)
+			seen[id] = true
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return writeCommandPackages(p, list, obj)
+}
+
+// removeCommandPackageIdentifiers drops identifiers from .nextgen/command-packages.json,
+// keeping those in keep (identifiers other installed packs still declare).
+func removeCommandPackageIdentifiers(projectPath string, identifiers []string, keep map[string]bool) error {
+	drop := map[string]bool{}
+	for _, id := range identifiers {
+		if id = strings.TrimSpace(id); id != "" && !keep[id] {
+			drop[id] = true
+		}
+	}
+	if len(drop) == 0 {
+		return nil
+	}
+	p := filepath.Join(projectPath, ".nextgen", "command-packages.json")
+	list, obj, err := readCommandPackages(p)
+	if err != nil || list == nil {
+		return err
+	}
+	kept := []string{}
+	for _, v := range list {
+		if !drop[strings.TrimSpace(v)] {
+			kept = append(kept, v)
+		}
+	}
+	if len(kept) == len(list) {
+		return nil
+	}
+	return writeCommandPackages(p, kept, obj)
+}
+
+// LoadProjectPacks registers the commands of every pack installed in the project.
+// Templates that differ from the digests pinned in the lockfile are refused.
+func LoadProjectPacks(projectPath string) {
+	dir := PacksDir(projectPath)
+	// Switching projects (e.g. to a monorepo workspace) drops the previous project's packs
+	if loadedPacksDir != "" && loadedPacksDir != dir {
+		unregisterTemplates(packsRoot + "/")
+		loadedPacksDir = ""
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	loadedPacksDir = dir
+	lock, lockErr := LoadPackLock(projectPath)
+	if lockErr != nil {
+		cli.Warn("%v", lockErr)
+	}
+	var discovered []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		prefix := packsRoot + "/" + e.Name()
+		unregisterTemplates(prefix + "/")
+		keys, err := scanTemplateSource(os.DirFS(filepath.Join(dir, e.Name())), ".", prefix)
+		if err != nil {
+			cli.Warn("could not load pack %s: %v", e.Name(), err)
+			continue
+		}
+		var entry *PackLockEntry
+		if locked, ok := lock.Packs[e.Name()]; ok {
+			entry = &locked
+		}
+		tampered, verified := tamperedPackFiles(filepath.Join(dir, e.Name()), entry)
+		if !verified {
+			if !allowUnverifiedPacks() {
+				for _, key := range keys {
+					delete(templateRegistry, key)
+				}
+				cli.Warn("refusing unverified pack %s: it is not in %s and lists no file digests (reinstall it with `ng pack install`, or set NEXTGEN_ALLOW_UNVERIFIED_PACKS=1 to load it anyway)", e.Name(), packsLockName)
+				continue
+			}
+			cli.Warn("loading unverified pack %s: it is not in %s and lists no file digests", e.Name(), packsLockName)
+		}
+		for _, key := range keys {
+			if rel := strings.TrimPrefix(key, prefix+"/"); tampered[rel] {
+				delete(templateRegistry, key)
+				cli.Warn("refusing tampered template %s from pack %s (run `ng pack verify`)", rel, e.Name())
+				continue
+			}
+			discovered = append(discovered, key)
+		}
+	}
+	registerTemplates(discovered)
+}
+
+// unregisterTemplates drops registry entries, queued templates and commands whose template
+// path starts with prefix, and the bundle commands synthesized for folders under it, so a pack can be
+// reloaded after it changed.
+func unregisterTemplates(prefix string) {
+	removed := map[string]bool{}
+	for key, data := range templateRegistry {
+		if strings.HasPrefix(key, prefix) || strings.HasPrefix(bundleBrowseRoot(key, data)+"/", prefix) {
+			delete(templateRegistry, key)
+			removed[key] = true
+		}
+	}
+	pending := pendingTemplates[:0]
+	for _, key := range pendingTemplates {
+		if !strings.HasPrefix(key, prefix) {
+			pending = append(pending, key)
+		}
+	}
+	pendingTemplates = pending
+	kept := Commands[:0]
+	for _, c := range Commands {
+		if !strings.HasPrefix(c.TemplatePath, prefix) && !strings.HasPrefix(c.BuiltinPath, prefix) && !removed[c.TemplatePath] {
+			kept = append(kept, c)
+		}
+	}
+	Commands = kept
+}
+
+// bundleBrowseRoot returns the folder a synthesized bundle command browses, or "" for
+// other registry entries.
+func bundleBrowseRoot(key string, data []byte) string {
+	if !strings.HasPrefix(key, "auto/") {
+		return ""
+	}
+	var bundle struct {
+		AutoBrowseRoot string `json:"autoBrowseRoot"`
+	}
+	_ = json.Unmarshal(data, &bundle)
+	return bundle.AutoBrowseRoot
+}
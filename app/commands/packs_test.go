@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePackFixture writes a minimal pack with one command template into dir.
+func writePackFixture(t *testing.T, dir, version string) {
+	t.Helper()
+	files := map[string]string{
+		"pack.json":               `{"name": "acme", "version": "` + version + `", "identifiers": ["acme-ui"]}`,
+		"acme-ui/button/btn.json": `{"title": "Acme Button", "slug": "acme-button", "filePaths": [{"path": "src", "nodes": [{"name": "{{.KebabName}}.tsx", "code": "x"}]}]}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestInstallPack tests installing, locking, registering and removing a pack.
+func TestInstallPack(t *testing.T) {
+	projectPath := t.TempDir()
+	source := filepath.Join(projectPath, "vendor-packs", "acme")
+	writePackFixture(t, source, "1.0.0")
+	defer unregisterTemplates(packsRoot + "/")
+
+	if _, err := InstallPack(projectPath, source); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := lock.Packs["acme"]
+	if !ok || entry.Version != "1.0.0" || entry.Source != "vendor-packs/acme" || !strings.HasPrefix(entry.Integrity, "sha256-") {
+		t.Fatalf("unexpected lock entry: %+v", entry)
+	}
+	if spec := GetCommandSpec("acme-button"); spec.TemplatePath != "packs/acme/acme-ui/button/btn.json" {
+		t.Errorf("pack command not registered: %+v", spec)
+	}
+	if data, _ := os.ReadFile(filepath.Join(projectPath, ".nextgen", "command-packages.json")); !strings.Contains(string(data), "acme-ui") {
+		t.Errorf("identifiers not recorded: %s", data)
+	}
+
+	// Reinstalling from the lockfile refuses drifted sources
+	writePackFixture(t, source, "1.0.1")
+	if _, err := InstallPacksFromLock(projectPath); err == nil {
+		t.Error("expected integrity mismatch error")
+	}
+
+	if err := RemovePack(projectPath, "acme"); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if packs, _ := ListInstalledPacks(projectPath); len(packs) != 0 {
+		t.Errorf("expected no packs after remove, got %v", packs)
+	}
+}
+
+// TestInstallPackSiblingSource tests that sources outside the project are locked as
+// relative paths and installed again from them.
+func TestInstallPackSiblingSource(t *testing.T) {
+	root := t.TempDir()
+	projectPath := filepath.Join(root, "site")
+	source := filepath.Join(root, "templates")
+	writePackFixture(t, source, "1.0.0")
+	defer unregisterTemplates(packsRoot + "/")
+
+	if _, err := InstallPack(projectPath, source); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	lock, err := LoadPackLock(projectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lock.Packs["acme"].Source; got != "../templates" {
+		t.Fatalf("expected source ../templates, got %q", got)
+	}
+	if _, err := InstallPacksFromLock(projectPath); err != nil {
+		t.Fatalf("install from lock failed: %v", err)
+	}
+	if lock, _ := LoadPackLock(projectPath); lock.Packs["acme"].Source != "../templates" {
+		t.Errorf("reinstall changed the source to %q", lock.Packs["acme"].Source)
+	}
+}
+
+// TestRemovePackIdentifiers tests that removing a pack takes back the identifiers it added
+// to command-packages.json, except ones another installed pack declares.
+func TestRemovePackIdentifiers(t *testing.T) {
+	projectPath := t.TempDir()
+	defer unregisterTemplates(packsRoot + "/")
+	packagesPath := filepath.Join(projectPath, ".nextgen", "command-packages.json")
+	if err := os.MkdirAll(filepath.Dir(packagesPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	original := "[\n  \"sanity\"\n]\n"
+	if err := os.WriteFile(packagesPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	acme := filepath.Join(projectPath, "vendor-packs", "acme")
+	writePackFixture(t, acme, "1.0.0")
+	beta := filepath.Join(projectPath, "vendor-packs", "beta")
+	writePackFixture(t, beta, "1.0.0")
+	if err := os.WriteFile(filepath.Join(beta, "pack.json"), []byte(`{"name": "beta", "version": "1.0.0", "identifiers": ["acme-ui"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallPack(projectPath, acme); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	if err := RemovePack(projectPath, "acme"); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if data, _ := os.ReadFile(packagesPath); string(data) != original {
+		t.Errorf("expected command-packages.json to be restored, got %q", data)
+	}
+
+	// An identifier another pack still declares stays
+	for _, source := range []string{acme, beta} {
+		if _, err := InstallPack(projectPath, source); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+	}
+	if err := RemovePack(projectPath, "acme"); err != nil {
+		t.Fatalf("remove failed: %v", err)
+	}
+	if data, _ := os.ReadFile(packagesPath); !strings.Contains(string(data), "acme-ui") {
+		t.Errorf("identifier declared by beta was removed: %s", data)
+	}
+}
+
+// TestRemovePackRejectsPaths tests that pack names escaping the packs directory are refused.
+func TestRemovePackRejectsPaths(t *testing.T) {
+	projectPath := t.TempDir()
+	outside := filepath.Join(projectPath, ".nextgen", "x")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../x", "..", "a/../../x", `..\x`} {
+		if err := RemovePack(projectPath, name); err == nil {
+			t.Errorf("RemovePack(%q) succeeded, expected an invalid name error", name)
+		}
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("directory outside the packs folder was removed: %v", err)
+	}
+}
+
+// TestPackLockRejectsEscapingGitPath tests that git pack paths leaving the clone are
+// refused when the lockfile is read.
+func TestPackLockRejectsEscapingGitPath(t *testing.T) {
+	for _, p := range []string{"../../..", "packs/../../x", "/etc"} {
+		projectPath := t.TempDir()
+		lock := PackLock{Packs: map[string]PackLockEntry{
+			"acme": {Version: "1.0.0", Git: &PackGitSource{Repo: "../repo", Ref: "HEAD", Commit: "abc", Path: p}},
+		}}
+		if err := SavePackLock(projectPath, lock); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPackLock(projectPath); err == nil {
+			t.Errorf("expected an error for git path %q", p)
+		}
+		if _, err := InstallPacksFromLock(projectPath); err == nil {
+			t.Errorf("expected install from lock to fail for git path %q", p)
+		}
+	}
+}
+
+// TestCompareVersions tests version comparison and that malformed versions are reported.
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v1.0.131", "1.0.131", 0},
+		{"1.2", "1.10", -1},
+		{"2.0.0-beta", "1.9.9", 1},
+	}
+	for _, tc := range testCases {
+		if got, err := compareVersions(tc.a, tc.b); err != nil || got != tc.expected {
+			t.Errorf("compareVersions(%q, %q) = %d (%v), expected %d", tc.a, tc.b, got, err, tc.expected)
+		}
+	}
+	if _, err := compareVersions("1.0.0", "one.two"); err == nil {
+		t.Error("expected an error for a malformed version")
+	}
+
+	dir := t.TempDir()
+	writePackFixture(t, dir, "1.0.0")
+	if err := os.WriteFile(filepath.Join(dir, packManifestName), []byte(`{"name": "acme", "version": "1.0.0", "minCliVersion": "2.beta"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPackManifest(dir); err == nil || !strings.Contains(err.Error(), "malformed minCliVersion") {
+		t.Errorf("expected a malformed minCliVersion error, got %v", err)
+	}
+}
+
+// TestGitPackUpdate tests pinning a pack to a commit of a local clone and updating it.
+func TestGitPackUpdate(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	projectPath := t.TempDir()
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	writePackFixture(t, repo, "1.0.0")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-qm", "v1")
+	defer unregisterTemplates(packsRoot + "/")
+
+	if _, err := AddGitPack(projectPath, repo, "", ""); err != nil {
+		t.Fatalf("add-git failed: %v", err)
+	}
+	lock, _ := LoadPackLock(projectPath)
+	first := lock.Packs["acme"].Git
+	if first == nil || len(first.Commit) != 40 || first.Ref != "HEAD" {
+		t.Fatalf("commit not recorded: %+v", lock.Packs["acme"])
+	}
+
+	if updates, err := UpdatePacks(projectPath, nil, ""); err != nil || len(updates) != 1 || !updates[0].UpToDate {
+		t.Fatalf("expected up-to-date pack, got %+v (%v)", updates, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(repo, "acme-ui", "button", "btn.json"), []byte(`{"title": "Acme Button", "slug": "acme-button", "filePaths": [{"path": "src", "nodes": [{"name": "{{.KebabName}}.tsx", "code": "y"}]}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-qam", "v2")
+	updates, err := UpdatePacks(projectPath, nil, "")
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if len(updates) != 1 || updates[0].UpToDate || updates[0].NewCommit == first.Commit {
+		t.Fatalf("expected a new commit, got %+v", updates)
+	}
+	if changed := updates[0].Changed; len(changed) != 1 || !strings.HasPrefix(changed[0], "acme-button") {
+		t.Errorf("expected acme-button to be reported as changed, got %v", changed)
+	}
+}
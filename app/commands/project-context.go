@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [PROJECT CONTEXT] Project files read by visibility rules, cached per project
+// -----------------------------------------------------------------------------
+
+// projectContext holds the parsed project files that visibility rules are evaluated
+// against. A missing or unparsable file leaves its field nil.
+type projectContext struct {
+	PackageJSON     map[string]any    // package.json
+	Dependencies    map[string]string // dependencies and devDependencies of package.json, by name
+	CommandPackages map[string]bool   // identifiers from .nextgen/command-packages.json
+}
+
+// fileStamp identifies a version of a file by modification time and size.
+type fileStamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+type projectContextEntry struct {
+	packageJSON     fileStamp
+	commandPackages fileStamp
+	ctx             *projectContext
+}
+
+var projectContexts = struct {
+	mu      sync.Mutex
+	entries map[string]*projectContextEntry
+}{entries: map[string]*projectContextEntry{}}
+
+// loadProjectContext returns the project context for projectPath. The files are parsed
+// once and parsed again only when their modification time or size changes, so evaluating
+// the visibility of every command costs two stat calls rather than two reads and parses
+// per command.
+func loadProjectContext(projectPath string) *projectContext {
+	pkgPath := filepath.Join(projectPath, "package.json")
+	cpPath := filepath.Join(projectPath, ".nextgen", "command-packages.json")
+	pkgStamp, cpStamp := statFile(pkgPath), statFile(cpPath)
+
+	projectContexts.mu.Lock()
+	defer projectContexts.mu.Unlock()
+	if e, ok := projectContexts.entries[projectPath]; ok && e.packageJSON == pkgStamp && e.commandPackages == cpStamp {
+		return e.ctx
+	}
+	ctx := &projectContext{}
+	if b, err := os.ReadFile(pkgPath); err == nil {
+		var data map[string]any
+		if json.Unmarshal(b, &data) == nil {
+			ctx.PackageJSON = data
+			ctx.Dependencies = packageDependencies(data)
+		}
+	}
+	if b, err := os.ReadFile(cpPath); err == nil {
+		ctx.CommandPackages = parseCommandPackages(b)
+	}
+	projectContexts.entries[projectPath] = &projectContextEntry{packageJSON: pkgStamp, commandPackages: cpStamp, ctx: ctx}
+	return ctx
+}
+
+// packageDependencies merges the dependencies and devDependencies of a package.json, the
+// former winning when a package is listed in both.
+func packageDependencies(data map[string]any) map[string]string {
+	deps := map[string]string{}
+	for _, field := range []string{"devDependencies", "dependencies"} {
+		m, _ := data[field].(map[string]any)
+		for name, spec := range m {
+			if s, ok := spec.(string); ok {
+				deps[name] = s
+			}
+		}
+	}
+	return deps
+}
+
+// parseCommandPackages reads the identifiers in command-packages.json, given either as an
+// array of strings or as an object with an "identifiers" array (falling back to every array
+// value in the object).
+func parseCommandPackages(b []byte) map[string]bool {
+	if strings.TrimSpace(string(b)) == "" {
+		return nil
+	}
+	var arr []string
+	if err := json.Unmarshal(b, &arr); err == nil && len(arr) > 0 {
+		set := make(map[string]bool, len(arr))
+		for _, s := range arr {
+			set[strings.TrimSpace(s)] = true
+		}
+		return set
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil
+	}
+	collected := map[string]bool{}
+	collect := func(raw any) {
+		if a, ok := raw.([]any); ok {
+			for _, v := range a {
+				if s, ok := v.(string); ok {
+					collected[strings.TrimSpace(s)] = true
+				}
+			}
+		}
+	}
+	if raw, ok := obj["identifiers"]; ok {
+		collect(raw)
+	}
+	if len(collected) == 0 {
+		for _, v := range obj {
+			collect(v)
+		}
+	}
+	if len(collected) == 0 {
+		return nil
+	}
+	return collected
+}
+
+// ecosystemEntry holds what the ecosystem detectors found in a project, with the stamps
+// of every detector's manifests when they ran.
+type ecosystemEntry struct {
+	stamps     []fileStamp
+	ecosystems []project.Ecosystem
+}
+
+var projectEcosystems = struct {
+	mu      sync.Mutex
+	entries map[string]*ecosystemEntry
+}{entries: map[string]*ecosystemEntry{}}
+
+// loadEcosystems returns the non-npm ecosystems in projectPath (go.mod, pyproject.toml,
+// Cargo.toml, ...). Detection runs again only when a manifest changes. It is separate from
+// loadProjectContext so that only rules that look at dependencies stat the manifests.
+func loadEcosystems(projectPath string) []project.Ecosystem {
+	markers := project.EcosystemMarkers()
+	stamps := make([]fileStamp, len(markers))
+	for i, m := range markers {
+		stamps[i] = statFile(filepath.Join(projectPath, m))
+	}
+	projectEcosystems.mu.Lock()
+	defer projectEcosystems.mu.Unlock()
+	if e, ok := projectEcosystems.entries[projectPath]; ok && slices.Equal(e.stamps, stamps) {
+		return e.ecosystems
+	}
+	ecosystems := project.DetectEcosystems(projectPath)
+	projectEcosystems.entries[projectPath] = &ecosystemEntry{stamps: stamps, ecosystems: ecosystems}
+	return ecosystems
+}
+
+// globCacheTTL is how long a glob result is reused. Globs walk the project, so they are not
+// re-run for every command on every render, but files that commands create show up soon.
+const globCacheTTL = 2 * time.Second
+
+type globEntry struct {
+	match string
+	at    time.Time
+}
+
+var projectGlobs = struct {
+	mu      sync.Mutex
+	entries map[string]globEntry
+}{entries: map[string]globEntry{}}
+
+// globSkipDirs are never searched by visibility globs.
+var globSkipDirs = map[string]bool{"node_modules": true, ".git": true}
+
+// projectGlob returns the first project file (slash-separated, relative to projectPath)
+// matching pattern, or "" when none does. "**" matches any number of directories.
+func projectGlob(projectPath, pattern string) string {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	key := projectPath + "\x00" + pattern
+	projectGlobs.mu.Lock()
+	if e, ok := projectGlobs.entries[key]; ok && time.Since(e.at) < globCacheTTL {
+		projectGlobs.mu.Unlock()
+		return e.match
+	}
+	projectGlobs.mu.Unlock()
+
+	match := findGlobMatch(projectPath, pattern)
+	projectGlobs.mu.Lock()
+	projectGlobs.entries[key] = globEntry{match: match, at: time.Now()}
+	projectGlobs.mu.Unlock()
+	return match
+}
+
+// findGlobMatch walks the directory named by the literal leading segments of pattern and
+// returns the first match.
+func findGlobMatch(projectPath, pattern string) string {
+	segments := strings.Split(pattern, "/")
+	base := 0
+	for base < len(segments)-1 && !strings.ContainsAny(segments[base], "*?[") {
+		base++
+	}
+	root := filepath.Join(projectPath, filepath.FromSlash(strings.Join(segments[:base], "/")))
+	deep := slices.Contains(segments, "**")
+	match := ""
+	filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable directories but keep walking their siblings
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && path != root && globSkipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		rel, relErr := filepath.Rel(projectPath, path)
+		if relErr != nil || path == projectPath {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if project.MatchGlob(pattern, rel) {
+			match = rel
+			return filepath.SkipAll
+		}
+		if d.IsDir() && !deep && strings.Count(rel, "/")+1 >= len(segments) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return match
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+)
+
+// -----------------------------------------------------------------------------
+// [RENDER] Single-pass placeholder renderer
+// -----------------------------------------------------------------------------
+
+// placeholderTransformPrefixes lists recognized transform prefixes (compacted, lowercase)
+// and the canonical transform they map to. Ordered by specificity.
+var placeholderTransformPrefixes = []struct {
+	prefix    string
+	transform string
+}{
+	{"screamingsnakecase", "screamingsnake"}, {"screamingsnake", "screamingsnake"},
+	{"snakecase", "snake"}, {"snake", "snake"},
+	{"kebabcase", "kebab"}, {"kebab", "kebab"},
+	{"pascalcase", "pascal"}, {"pascal", "pascal"},
+	{"camelcase", "camel"}, {"camel", "camel"},
+	{"uppercase", "upper"}, {"upper", "upper"},
+	{"lowercase", "lower"}, {"lower", "lower"},
+}
+
+// splitPlaceholderIdentifier splits a placeholder identifier (e.g. "KebabCaseName")
+// into its canonical transform ("kebab") and the PascalCase base variable ("Name").
+// The transform is empty when the identifier refers to the raw value.
+func splitPlaceholderIdentifier(identifier string) (string, string) {
+	id := strings.TrimSpace(identifier)
+	if id == "" {
+		return "", ""
+	}
+	lowerCompacted := strings.ReplaceAll(strings.ReplaceAll(strings.ToLower(id), "-", ""), "_", "")
+	transform := ""
+	cutIndex := 0
+	for _, p := range placeholderTransformPrefixes {
+		if strings.HasPrefix(lowerCompacted, p.prefix) {
+			transform = p.transform
+			cutIndex = len(p.prefix)
+			break
+		}
+	}
+	remainder := id
+	if cutIndex > 0 {
+		// Walk the original string to skip cutIndex non-separator chars
+		count := 0
+		pos := 0
+		for pos < len(id) && count < cutIndex {
+			c := id[pos]
+			if c != '-' && c != '_' {
+				count++
+			}
+			pos++
+		}
+		remainder = id[pos:]
+		// Skip one optional separator
+		if strings.HasPrefix(remainder, "-") || strings.HasPrefix(remainder, "_") {
+			remainder = remainder[1:]
+		}
+	}
+	rem := strings.TrimSpace(remainder)
+	if rem == "" {
+		return transform, ""
+	}
+	// Turn separators into spaces; if still single token, attempt camel split
+	remSpaced := strings.NewReplacer("-", " ", "_", " ").Replace(rem)
+	words := strings.Fields(remSpaced)
+	if len(words) <= 1 {
+		if cw := splitCamelWordsToken(rem); len(cw) > 1 {
+			words = cw
+		}
+	}
+	if len(words) == 0 {
+		return transform, ToPascalCase(rem)
+	}
+	return transform, ToPascalCase(strings.Join(words, " "))
+}
+
+// applyPlaceholderTransform converts a raw value using a canonical transform name.
+func applyPlaceholderTransform(transform, value string) string {
+	switch transform {
+	case "pascal":
+		return ToPascalCase(value)
+	case "camel":
+		return ToCamelCase(value)
+	case "kebab":
+		return ToKebabCase(value)
+	case "snake":
+		return ToSnakeCase(value)
+	case "screamingsnake":
+		return ToScreamingSnakeCase(value)
+	case "upper":
+		return strings.ToUpper(value)
+	case "lower":
+		return strings.ToLower(value)
+	}
+	return value
+}
+
+// resolvePlaceholder returns the value for a placeholder identifier. An exact entry
+// in the placeholder map wins; otherwise the raw value of the base variable is looked
+// up and the transform is applied on the fly.
+func resolvePlaceholder(identifier string, placeholders map[string]string) (string, bool) {
+	if v, ok := placeholders["{{."+identifier+"}}"]; ok {
+		return v, true
+	}
+	transform, base := splitPlaceholderIdentifier(identifier)
+	if base == "" {
+		return "", false
+	}
+	raw, ok := placeholders["{{."+base+"}}"]
+	if !ok {
+		return "", false
+	}
+	return applyPlaceholderTransform(transform, raw), true
+}
+
+// Escapes for literal moustaches. Content between {{{{raw}}}} and {{{{/raw}}}} is emitted
+// verbatim (without the delimiters), and a backslash before "{{" emits a literal "{{".
+var rawBlockRegex = regexp.MustCompile(`(?s){{{{\s*raw\s*}}}}(.*?){{{{\s*/raw\s*}}}}`)
+
+// templateSegment is a slice of template text that is either rendered or emitted verbatim.
+type templateSegment struct {
+	text string
+	raw  bool
+}
+
+// splitTemplateSegments splits content into renderable and verbatim segments, honouring
+// raw blocks and backslash-escaped moustaches.
+func splitTemplateSegments(content string) []templateSegment {
+	var segments []templateSegment
+	addEscaped := func(text string) {
+		for {
+			i := strings.Index(text, `\{{`)
+			if i < 0 {
+				break
+			}
+			if i > 0 {
+				segments = append(segments, templateSegment{text: text[:i]})
+			}
+			segments = append(segments, templateSegment{text: "{{", raw: true})
+			text = text[i+3:]
+		}
+		if text != "" {
+			segments = append(segments, templateSegment{text: text})
+		}
+	}
+	last := 0
+	for _, m := range rawBlockRegex.FindAllStringSubmatchIndex(content, -1) {
+		addEscaped(content[last:m[0]])
+		segments = append(segments, templateSegment{text: content[m[2]:m[3]], raw: true})
+		last = m[1]
+	}
+	addEscaped(content[last:])
+	return segments
+}
+
+// renderPlaceholders tokenizes content once and substitutes every placeholder it can
+// resolve. Unresolved placeholders are left untouched and returned (de-duplicated, in
+// order of appearance) so callers can report them. Escaped moustaches are never rendered.
+func renderPlaceholders(content string, placeholders map[string]string) (string, []string) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+	var b strings.Builder
+	b.Grow(len(content))
+	var unresolved []string
+	seen := map[string]bool{}
+	for _, seg := range splitTemplateSegments(content) {
+		if seg.raw {
+			b.WriteString(seg.text)
+			continue
+		}
+		last := 0
+		for _, m := range placeholderRegex.FindAllStringSubmatchIndex(seg.text, -1) {
+			b.WriteString(seg.text[last:m[0]])
+			identifier := seg.text[m[2]:m[3]]
+			if v, ok := resolvePlaceholder(identifier, placeholders); ok {
+				b.WriteString(v)
+			} else {
+				b.WriteString(seg.text[m[0]:m[1]])
+				if !seen[identifier] {
+					seen[identifier] = true
+					unresolved = append(unresolved, "{{."+identifier+"}}")
+				}
+			}
+			last = m[1]
+		}
+		b.WriteString(seg.text[last:])
+	}
+	return b.String(), unresolved
+}
+
+// placeholderIdentifiers returns the identifiers of all unescaped placeholders in content.
+func placeholderIdentifiers(content string) []string {
+	var ids []string
+	for _, seg := range splitTemplateSegments(content) {
+		if seg.raw {
+			continue
+		}
+		for _, m := range placeholderRegex.FindAllStringSubmatch(seg.text, -1) {
+			ids = append(ids, m[1])
+		}
+	}
+	return ids
+}
+
+// renderNodeCode renders a node's code unless the node opts out with "rawCode": true.
+func renderNodeCode(node TreeNode, placeholders map[string]string) string {
+	if node.RawCode {
+		return node.Code
+	}
+	return replacePlaceholders(node.Code, placeholders)
+}
+
+// replacePlaceholders renders content in a single pass, leaving unresolved placeholders as-is.
+func replacePlaceholders(content string, placeholders map[string]string) string {
+	out, _ := renderPlaceholders(content, placeholders)
+	return out
+}
+
+// FindUnresolvedPlaceholders returns every placeholder in the template that the given
+// placeholder map cannot resolve, sorted and de-duplicated.
+func FindUnresolvedPlaceholders(template JSONCommandTemplate, placeholders map[string]string) []string {
+	set := map[string]bool{}
+	check := func(s string) {
+		if s == "" {
+			return
+		}
+		_, unresolved := renderPlaceholders(s, placeholders)
+		for _, u := range unresolved {
+			set[u] = true
+		}
+	}
+	var walk func(nodes []TreeNode)
+	walk = func(nodes []TreeNode) {
+		for _, n := range nodes {
+			check(n.Name)
+			if !n.RawCode {
+				check(n.Code)
+			}
+			for _, a := range n.getActions() {
+				check(a.Logic.Raw)
+				if spec := a.Logic.Spec; spec != nil {
+					check(spec.Target)
+					check(spec.TargetStart)
+					check(spec.TargetEnd)
+					check(spec.Content)
+					check(spec.Replacement)
+					check(spec.RequireAbsent)
+				}
+			}
+			walk(n.Children)
+		}
+	}
+	for _, group := range template.FilePaths {
+		check(group.Path)
+		walk(group.Nodes)
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkUnresolvedPlaceholders fails when the template contains placeholders that cannot be
+// resolved. With --allow-unresolved they are reported as a warning and left in the output.
+func checkUnresolvedPlaceholders(template JSONCommandTemplate, placeholders map[string]string) error {
+	unresolved := FindUnresolvedPlaceholders(template, placeholders)
+	if len(unresolved) == 0 {
+		return nil
+	}
+	if cli.IsAllowUnresolvedEnabled() {
+		cli.Warn("Unresolved placeholders left as-is: %s", strings.Join(unresolved, ", "))
+		return nil
+	}
+	return fmt.Errorf("unresolved placeholders: %s (use --allow-unresolved to render anyway)", strings.Join(unresolved, ", "))
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestRenderPlaceholders tests single-pass rendering and unresolved detection.
+func TestRenderPlaceholders(t *testing.T) {
+	placeholders := BuildPlaceholders(map[string]string{
+		"Name":     "blog post",
+		"NameList": "posts",
+	})
+
+	testCases := []struct {
+		name       string
+		content    string
+		expected   string
+		unresolved []string
+	}{
+		{
+			name:     "Raw and transformed",
+			content:  "{{.Name}} {{.PascalCaseName}} {{ .kebabName }} {{.SCREAMING_SNAKE_Name}}",
+			expected: "blog post BlogPost blog-post BLOG_POST",
+		},
+		{
+			name:     "Overlapping keys",
+			content:  "{{.NameList}}/{{.Name}}",
+			expected: "posts/blog post",
+		},
+		{
+			name:       "Unresolved left as-is",
+			content:    "{{.Name}} {{.Missing}} {{ .Missing }}",
+			expected:   "blog post {{.Missing}} {{ .Missing }}",
+			unresolved: []string{"{{.Missing}}"},
+		},
+		{
+			name:     "Raw block",
+			content:  "{{.Name}} {{{{raw}}}}{{ .Name }}{{{{/raw}}}}",
+			expected: "blog post {{ .Name }}",
+		},
+		{
+			name:     "Backslash escape",
+			content:  `\{{.Name}} {{.Name}}`,
+			expected: "{{.Name}} blog post",
+		},
+		{
+			name:     "No placeholders",
+			content:  "export default {}",
+			expected: "export default {}",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, unresolved := renderPlaceholders(tc.content, placeholders)
+			if actual != tc.expected {
+				t.Errorf("Output mismatch: expected %q, got %q", tc.expected, actual)
+			}
+			if !reflect.DeepEqual(unresolved, tc.unresolved) {
+				t.Errorf("Unresolved mismatch: expected %v, got %v", tc.unresolved, unresolved)
+			}
+		})
+	}
+}
+
+// TestFindUnresolvedPlaceholders tests template-wide unresolved detection.
+func TestFindUnresolvedPlaceholders(t *testing.T) {
+	tmpl := JSONCommandTemplate{
+		FilePaths: []FilePathGroup{{
+			Path: "{{.Dir}}",
+			Nodes: []TreeNode{{
+				Name: "{{.KebabName}}.ts",
+				Code: "export const {{.CamelName}} = '{{.Title}}'",
+			}, {
+				Name:    "view.hbs",
+				Code:    "<h1>{{.Heading}}</h1>",
+				RawCode: true,
+			}},
+		}},
+	}
+	placeholders := BuildPlaceholders(map[string]string{"Name": "x"})
+	got := FindUnresolvedPlaceholders(tmpl, placeholders)
+	expected := []string{"{{.Dir}}", "{{.Title}}"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+// TestInferVariableKeysHonoursEscapes tests that escaped moustaches are not inferred as variables.
+func TestInferVariableKeysHonoursEscapes(t *testing.T) {
+	content := `{{.KebabName}} {{{{raw}}}}{{ .Something }}{{{{/raw}}}} \{{.Other}}`
+	got := InferVariableKeys(content)
+	expected := []string{"Name"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	templateBytes := []byte(`{"filePaths":[{"path":"x","nodes":[{"name":"{{.Name}}.hbs","code":"{{.Body}}","rawCode":true}]}]}`)
+	keys := InferTemplateVariableKeys(templateBytes, "")
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %v, got %v", expected, keys)
+	}
+}
@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+	"github.com/atotto/clipboard"
+)
+
+// -----------------------------------------------------------------------------
+// [RESOLVER] Command resolution shared by the TUI, direct execution, previews and the MDC
+// -----------------------------------------------------------------------------
+
+// A command name is resolved against these sources, in order; the first match wins:
+//
+//  1. "paste from clipboard": the template currently on the system clipboard
+//  2. template registry paths ending in .json (as selected when browsing folders)
+//  3. args commands built into the CLI (e.g. "pack install")
+//  4. user-saved native shell commands (registry NativeCommands)
+//  5. saved clipboard commands (registry ClipboardCommands)
+//  6. project commands in .nextgen/local-commands/<kebab-name>.json, either a
+//     {"command": "..."} shell command or a template
+//  7. registered templates by name or slug: embedded, user templates and packs, with
+//     project overrides applied (see LoadLocalOverrides)
+
+// CommandSource identifies where a resolved command comes from.
+type CommandSource string
+
+const (
+	SourceClipboardPaste CommandSource = "clipboard-paste"
+	SourceTemplatePath   CommandSource = "path"
+	SourceArgs           CommandSource = "args"
+	SourceNative         CommandSource = "native"
+	SourceClipboard      CommandSource = "clipboard"
+	SourceProject        CommandSource = "project"
+	SourceBuiltin        CommandSource = "builtin"
+)
+
+// ResolutionOrder lists the sources in the order they are consulted.
+var ResolutionOrder = []CommandSource{
+	SourceClipboardPaste, SourceTemplatePath, SourceArgs, SourceNative, SourceClipboard, SourceProject, SourceBuiltin,
+}
+
+// CommandKind describes how a resolved command is executed.
+type CommandKind string
+
+const (
+	KindTemplate  CommandKind = "template"  // JSON template with filePaths
+	KindComposite CommandKind = "composite" // JSON template that only runs other commands
+	KindShell     CommandKind = "shell"     // shell command line
+	KindArgs      CommandKind = "args"      // Go command registered in the args package
+)
+
+// ErrCommandNotFound is returned when no source provides a command.
+var ErrCommandNotFound = errors.New("command not found")
+
+// ResolvedCommand is the outcome of resolving a command name.
+type ResolvedCommand struct {
+	Name     string // name as requested
+	Source   CommandSource
+	Kind     CommandKind
+	Location string      // registry key, file path or registry entry the command was found in
+	Spec     CommandSpec // registered command, for SourceBuiltin and SourceTemplatePath
+	Template []byte      // template bytes, for template and composite commands
+	Shell    string      // command line, for shell commands
+}
+
+// Describe returns a short human-readable description of where the command was found.
+func (c ResolvedCommand) Describe() string {
+	switch c.Source {
+	case SourceClipboardPaste:
+		return "clipboard content"
+	case SourceTemplatePath:
+		return fmt.Sprintf("template path %s", c.Location)
+	case SourceArgs:
+		return fmt.Sprintf("CLI command '%s'", c.Location)
+	case SourceNative:
+		return fmt.Sprintf("native command '%s'", c.Location)
+	case SourceClipboard:
+		return fmt.Sprintf("clipboard command '%s'", c.Location)
+	case SourceProject:
+		return fmt.Sprintf("project command '%s'", c.Location)
+	case SourceBuiltin:
+		if c.Spec.Overridden() {
+			return fmt.Sprintf("project override .nextgen/%s of built-in template %s", c.Location, c.Spec.BuiltinPath)
+		}
+		return fmt.Sprintf("built-in template %s", c.Location)
+	}
+	return string(c.Source)
+}
+
+// argsCommandExists reports whether a name is an args command. The args package registers
+// it, since it imports this package.
+var argsCommandExists func(name string) bool
+
+// SetArgsCommandLookup registers the lookup used to resolve args commands.
+func SetArgsCommandLookup(lookup func(name string) bool) { argsCommandExists = lookup }
+
+// Resolver resolves command names for a project. Registry may be nil, in which case native
+// and saved clipboard commands are not considered.
+type Resolver struct {
+	ProjectPath string
+	Registry    *project.ProjectRegistry
+}
+
+// NewResolver returns a resolver for the given project.
+func NewResolver(projectPath string, registry *project.ProjectRegistry) Resolver {
+	return Resolver{ProjectPath: projectPath, Registry: registry}
+}
+
+// Resolve returns the command that name runs.
+func (r Resolver) Resolve(name string) (ResolvedCommand, error) {
+	matches, err := r.candidates(name, true)
+	if err != nil {
+		return ResolvedCommand{}, err
+	}
+	if len(matches) == 0 {
+		return ResolvedCommand{}, fmt.Errorf("%w: %s", ErrCommandNotFound, name)
+	}
+	return matches[0], nil
+}
+
+// Candidates returns every source that provides name, in precedence order. The first
+// candidate is the one Resolve returns; the others are shadowed by it.
+func (r Resolver) Candidates(name string) ([]ResolvedCommand, error) {
+	return r.candidates(name, false)
+}
+
+// Exists reports whether any source provides name.
+func (r Resolver) Exists(name string) bool {
+	_, err := r.Resolve(name)
+	return !errors.Is(err, ErrCommandNotFound)
+}
+
+// candidates walks the sources in precedence order, stopping at the first match when
+// firstOnly is set.
+func (r Resolver) candidates(name string, firstOnly bool) ([]ResolvedCommand, error) {
+	if strings.ToLower(name) == "paste from clipboard" {
+		content, err := clipboard.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clipboard for paste command: %w", err)
+		}
+		return []ResolvedCommand{templateCommand(name, SourceClipboardPaste, "clipboard", []byte(content))}, nil
+	}
+
+	lookups := []func(string) (ResolvedCommand, bool, error){
+		r.lookupTemplatePath, r.lookupArgs, r.lookupNative, r.lookupClipboard, r.lookupProject, r.lookupBuiltin,
+	}
+	var out []ResolvedCommand
+	for _, lookup := range lookups {
+		c, found, err := lookup(name)
+		if err != nil {
+			return out, err
+		}
+		if !found {
+			continue
+		}
+		out = append(out, c)
+		if firstOnly {
+			break
+		}
+	}
+	return out, nil
+}
+
+// templateCommand builds a resolved template command, telling composites apart.
+func templateCommand(name string, source CommandSource, location string, data []byte) ResolvedCommand {
+	c := ResolvedCommand{Name: name, Source: source, Kind: KindTemplate, Location: location, Template: data}
+	if h := c.parsed().Header(); !h.HasFilePaths && h.HasRun {
+		c.Kind = KindComposite
+	}
+	return c
+}
+
+// parsed returns the template cache entry for the command's template.
+func (c ResolvedCommand) parsed() *parsedTemplate {
+	return cachedTemplate(string(c.Source)+":"+c.Location, c.Template)
+}
+
+func (r Resolver) lookupTemplatePath(name string) (ResolvedCommand, bool, error) {
+	if !strings.HasSuffix(strings.ToLower(name), ".json") {
+		return ResolvedCommand{}, false, nil
+	}
+	path := name
+	spec, registered := FindCommandByTemplatePath(name)
+	if registered {
+		// A built-in path that the project overrides runs the override
+		path = spec.TemplatePath
+	}
+	data, err := LoadCommandTemplate(path)
+	if err != nil {
+		return ResolvedCommand{}, false, nil
+	}
+	c := templateCommand(name, SourceTemplatePath, path, data)
+	c.Spec = spec
+	return c, true, nil
+}
+
+func (r Resolver) lookupArgs(name string) (ResolvedCommand, bool, error) {
+	if argsCommandExists == nil || !argsCommandExists(name) {
+		return ResolvedCommand{}, false, nil
+	}
+	return ResolvedCommand{Name: name, Source: SourceArgs, Kind: KindArgs, Location: name}, true, nil
+}
+
+func (r Resolver) lookupNative(name string) (ResolvedCommand, bool, error) {
+	if r.Registry == nil || r.Registry.NativeCommands[name] == "" {
+		return ResolvedCommand{}, false, nil
+	}
+	return ResolvedCommand{Name: name, Source: SourceNative, Kind: KindShell, Location: name, Shell: r.Registry.NativeCommands[name]}, true, nil
+}
+
+func (r Resolver) lookupClipboard(name string) (ResolvedCommand, bool, error) {
+	if r.Registry == nil {
+		return ResolvedCommand{}, false, nil
+	}
+	spec, found := r.Registry.ClipboardCommands[name]
+	if !found || spec.Template == "" {
+		return ResolvedCommand{}, false, nil
+	}
+	return templateCommand(name, SourceClipboard, name, []byte(spec.Template)), true, nil
+}
+
+func (r Resolver) lookupProject(name string) (ResolvedCommand, bool, error) {
+	if r.ProjectPath == "" || r.ProjectPath == "." {
+		return ResolvedCommand{}, false, nil
+	}
+	path := filepath.Join(LocalCommandsDir(r.ProjectPath), ToKebabCase(name)+".json")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ResolvedCommand{}, false, nil
+	}
+	if err != nil {
+		return ResolvedCommand{}, false, fmt.Errorf("error reading project command file %s: %w", path, err)
+	}
+	location := filepath.ToSlash(filepath.Join(".nextgen", "local-commands", filepath.Base(path)))
+	var file struct {
+		Command   string `json:"command"`
+		FilePaths []any  `json:"filePaths"`
+		Run       []any  `json:"run"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return ResolvedCommand{}, false, fmt.Errorf("failed to parse project command file '%s': %w", path, err)
+	}
+	if strings.TrimSpace(file.Command) != "" {
+		return ResolvedCommand{Name: name, Source: SourceProject, Kind: KindShell, Location: location, Shell: file.Command}, true, nil
+	}
+	if len(file.FilePaths) == 0 && len(file.Run) == 0 {
+		return ResolvedCommand{}, false, fmt.Errorf("invalid project command file '%s': missing 'command', 'filePaths' or 'run'", path)
+	}
+	return templateCommand(name, SourceProject, location, data), true, nil
+}
+
+func (r Resolver) lookupBuiltin(name string) (ResolvedCommand, bool, error) {
+	spec := GetCommandSpec(name)
+	if spec.TemplatePath == "" {
+		return ResolvedCommand{}, false, nil
+	}
+	data, err := LoadCommandTemplate(spec.TemplatePath)
+	if err != nil {
+		return ResolvedCommand{}, false, fmt.Errorf("error reading template %s: %w", spec.TemplatePath, err)
+	}
+	c := templateCommand(name, SourceBuiltin, spec.TemplatePath, data)
+	c.Spec = spec
+	return c, true, nil
+}
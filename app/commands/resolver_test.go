@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// TestResolverPrecedence tests that sources are consulted in the documented order.
+func TestResolverPrecedence(t *testing.T) {
+	var builtin CommandSpec
+	for _, c := range RegisteredCommands() {
+		if c.Slug != "" && !c.Overridden() && filepath.Ext(c.TemplatePath) == ".json" {
+			builtin = c
+			break
+		}
+	}
+	if builtin.Slug == "" {
+		t.Skip("no built-in commands registered")
+	}
+	projectPath := t.TempDir()
+	registry := &project.ProjectRegistry{NativeCommands: map[string]string{}}
+	r := NewResolver(projectPath, registry)
+
+	resolved, err := r.Resolve(builtin.Slug)
+	if err != nil || resolved.Source != SourceBuiltin || resolved.Spec.TemplatePath != builtin.TemplatePath {
+		t.Fatalf("expected built-in, got %+v (%v)", resolved, err)
+	}
+
+	// A project shell command with the same kebab name wins over the built-in
+	if err := os.MkdirAll(LocalCommandsDir(projectPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	local := filepath.Join(LocalCommandsDir(projectPath), ToKebabCase(builtin.Slug)+".json")
+	if err := os.WriteFile(local, []byte(`{"command": "echo hi"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	candidates, err := r.Candidates(builtin.Slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candidates) != 2 || candidates[0].Source != SourceProject || candidates[0].Kind != KindShell || candidates[1].Source != SourceBuiltin {
+		t.Fatalf("unexpected candidates: %+v", candidates)
+	}
+
+	// A user-saved native command wins over project commands
+	registry.NativeCommands[builtin.Slug] = "echo native"
+	if resolved, _ := r.Resolve(builtin.Slug); resolved.Source != SourceNative || resolved.Shell != "echo native" {
+		t.Errorf("expected native command, got %+v", resolved)
+	}
+	delete(registry.NativeCommands, builtin.Slug)
+
+	// Project files that are neither shell commands nor templates are reported
+	if err := os.WriteFile(local, []byte(`{"title": "nothing"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Resolve(builtin.Slug); err == nil || errors.Is(err, ErrCommandNotFound) {
+		t.Errorf("expected invalid project command error, got %v", err)
+	}
+
+	if _, err := r.Resolve("no-such-command-anywhere"); !errors.Is(err, ErrCommandNotFound) {
+		t.Errorf("expected ErrCommandNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [SEMVER] Version ranges for dependency visibility conditions
+// -----------------------------------------------------------------------------
+
+// A subset of npm's range syntax: comparators (>=, <=, >, <, =), caret and tilde ranges,
+// x-ranges (13, 13.x, 13.4.*), hyphen ranges (1.2 - 2) and alternatives joined by ||.
+// Space-separated comparators must all hold. Prerelease and build suffixes are ignored.
+
+// semVersion is a major.minor.patch version.
+type semVersion [3]int
+
+func (v semVersion) less(o semVersion) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+// bump returns the smallest version above every version matching the first n parts of v,
+// e.g. 1.2.x bumps to 1.3.0.
+func (v semVersion) bump(n int) semVersion {
+	if n <= 0 || n > 3 {
+		return v
+	}
+	out := semVersion{}
+	copy(out[:n], v[:n])
+	out[n-1]++
+	return out
+}
+
+// parseSemver parses a possibly partial version ("1", "1.2", "v1.2.3", "1.x") and returns
+// it with missing parts set to 0, and the number of parts given.
+func parseSemver(s string) (semVersion, int, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "="), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	var v semVersion
+	if s == "" {
+		return v, 0, false
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, 0, false
+	}
+	for i, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			return v, i, true
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, 0, false
+		}
+		v[i] = n
+	}
+	return v, len(parts), true
+}
+
+// versionFromSpec returns the lowest version a package.json dependency spec allows, e.g.
+// 14.1.0 for "^14.1.0". Specs that name no version (tags, URLs, "*") give false.
+func versionFromSpec(spec string) (semVersion, bool) {
+	spec = strings.TrimSpace(spec)
+	spec = strings.TrimPrefix(spec, "workspace:")
+	if i := strings.LastIndex(spec, "@"); strings.HasPrefix(spec, "npm:") && i > 0 {
+		spec = spec[i+1:]
+	}
+	spec = strings.TrimSpace(strings.Split(spec, "|")[0]) // npm "||", composer "|"
+	if fields := strings.Fields(spec); len(fields) > 0 {
+		spec = fields[0]
+	}
+	spec = strings.TrimLeft(spec, "^~>=")
+	v, n, ok := parseSemver(spec)
+	return v, ok && n > 0
+}
+
+// semverSatisfies reports whether v matches the range. An empty range or "*" matches any
+// version; an unparsable range matches none.
+func semverSatisfies(v semVersion, rng string) bool {
+	for _, alt := range strings.Split(rng, "||") {
+		if comparatorSetSatisfied(v, strings.TrimSpace(alt)) {
+			return true
+		}
+	}
+	return false
+}
+
+func comparatorSetSatisfied(v semVersion, set string) bool {
+	if set == "" || set == "*" || set == "x" || set == "X" {
+		return true
+	}
+	if lo, hi, ok := strings.Cut(set, " - "); ok {
+		return comparatorSatisfied(v, ">="+strings.TrimSpace(lo)) && comparatorSatisfied(v, "<="+strings.TrimSpace(hi))
+	}
+	fields := strings.Fields(set)
+	// Allow a space between an operator and its version (">= 13")
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], "<>=^~") == "" && i+1 < len(fields) {
+			fields[i+1] = fields[i] + fields[i+1]
+			fields = append(fields[:i], fields[i+1:]...)
+		}
+	}
+	for _, c := range fields {
+		if !comparatorSatisfied(v, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// comparatorSatisfied checks one comparator such as ">=13.4", "^1.2.3" or "2.x".
+func comparatorSatisfied(v semVersion, c string) bool {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "^", "~", "="} {
+		if strings.HasPrefix(c, prefix) {
+			op, c = prefix, c[len(prefix):]
+			break
+		}
+	}
+	want, n, ok := parseSemver(c)
+	if !ok {
+		return false
+	}
+	if n == 0 {
+		// "*" or "x" with any operator but < matches everything
+		return op != "<"
+	}
+	inRange := func(lo, hi semVersion) bool { return !v.less(lo) && v.less(hi) }
+	switch op {
+	case ">=":
+		return !v.less(want)
+	case ">":
+		if n < 3 {
+			return !v.less(want.bump(n))
+		}
+		return want.less(v)
+	case "<":
+		return v.less(want)
+	case "<=":
+		if n < 3 {
+			return v.less(want.bump(n))
+		}
+		return !want.less(v)
+	case "^":
+		switch {
+		case want[0] > 0 || n == 1:
+			return inRange(want, want.bump(1))
+		case want[1] > 0 || n == 2:
+			return inRange(want, want.bump(2))
+		}
+		return inRange(want, want.bump(3))
+	case "~":
+		if n == 1 {
+			return inRange(want, want.bump(1))
+		}
+		return inRange(want, want.bump(2))
+	}
+	return inRange(want, want.bump(n))
+}
@@ -0,0 +1,75 @@
+package commands
+
+import "testing"
+
+// TestSemverSatisfies tests comparators, caret, tilde, x- and hyphen ranges and ||.
+func TestSemverSatisfies(t *testing.T) {
+	tests := []struct {
+		version, rng string
+		want         bool
+	}{
+		{"14.1.0", "", true},
+		{"14.1.0", "*", true},
+		{"14.1.0", ">=13", true},
+		{"12.9.9", ">=13", false},
+		{"14.1.0", ">= 13 <15", true},
+		{"15.0.0", ">=13 <15", false},
+		{"13.0.0", ">13", false},
+		{"14.0.0", ">13", true},
+		{"13.9.0", "<=13", true},
+		{"14.0.0", "<=13", false},
+		{"1.4.2", "^1.2.3", true},
+		{"2.0.0", "^1.2.3", false},
+		{"0.2.9", "^0.2.3", true},
+		{"0.3.0", "^0.2.3", false},
+		{"0.0.4", "^0.0.3", false},
+		{"1.2.9", "~1.2.3", true},
+		{"1.3.0", "~1.2.3", false},
+		{"13.4.1", "13.x", true},
+		{"13.4.1", "13.4", true},
+		{"13.5.0", "13.4.*", false},
+		{"1.2.3", "1.2.3", true},
+		{"1.2.4", "=1.2.3", false},
+		{"1.5.0", "1.2 - 2", true},
+		{"2.9.0", "1.2 - 2", true},
+		{"3.0.0", "1.2 - 2", false},
+		{"18.2.0", "^17 || ^18", true},
+		{"16.0.0", "^17 || ^18", false},
+		{"1.0.0", "latest", false},
+	}
+	for _, tt := range tests {
+		v, _, ok := parseSemver(tt.version)
+		if !ok {
+			t.Fatalf("parseSemver(%q) failed", tt.version)
+		}
+		if got := semverSatisfies(v, tt.rng); got != tt.want {
+			t.Errorf("semverSatisfies(%s, %q) = %v, want %v", tt.version, tt.rng, got, tt.want)
+		}
+	}
+}
+
+// TestVersionFromSpec tests the lowest version allowed by package.json dependency specs.
+func TestVersionFromSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want semVersion
+		ok   bool
+	}{
+		{"^14.1.0", semVersion{14, 1, 0}, true},
+		{"~3.2", semVersion{3, 2, 0}, true},
+		{">=2.0.0 <3", semVersion{2, 0, 0}, true},
+		{"1.x || 2.x", semVersion{1, 0, 0}, true},
+		{"workspace:^1.0.0", semVersion{1, 0, 0}, true},
+		{"npm:react@18.2.0", semVersion{18, 2, 0}, true},
+		{"15.0.0-canary.3", semVersion{15, 0, 0}, true},
+		{"latest", semVersion{}, false},
+		{"*", semVersion{}, false},
+		{"github:vercel/next.js", semVersion{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := versionFromSpec(tt.spec)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("versionFromSpec(%q) = %v, %v; want %v, %v", tt.spec, got, ok, tt.want, tt.ok)
+		}
+	}
+}
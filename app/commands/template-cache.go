@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [TEMPLATE CACHE] Parsed templates keyed by source and content hash
+// -----------------------------------------------------------------------------
+
+// Listing, previewing and prompting for a command reads the same template many times: the
+// header when registering and resolving it, the variable keys for the prompt, and the
+// titles, descriptions, priorities and examples of its variables. Each of those is parsed
+// once per template content and reused until the content behind a source changes.
+
+// templateHeader holds the top-level template fields read when registering and resolving.
+type templateHeader struct {
+	Title        string
+	Slug         string
+	Show         *CommandVisibility
+	HasFilePaths bool
+	HasRun       bool
+}
+
+// templateVariables holds the per-variable metadata declared by a template.
+type templateVariables struct {
+	Descriptions map[string]string
+	Titles       map[string]string
+	Priorities   map[string]int
+	Examples     map[string][]string
+}
+
+// parsedTemplate caches what has been parsed from one template content. Fields are
+// filled in on first use.
+type parsedTemplate struct {
+	mu     sync.Mutex
+	data   []byte
+	header *templateHeader
+	vars   *templateVariables
+	keys   map[string][]string // variable keys by project path
+}
+
+// maxAnonymousTemplates bounds the entries kept for anonymous bytes (clipboard content and
+// the like), which no later read of the same source replaces.
+const maxAnonymousTemplates = 64
+
+var templateCache = struct {
+	mu        sync.Mutex
+	entries   map[string]*parsedTemplate // source + "@" + content hash
+	current   map[string]string          // source -> key of its latest entry
+	anonymous []string                   // keys of anonymous entries, least recently used first
+}{entries: map[string]*parsedTemplate{}, current: map[string]string{}}
+
+// cachedTemplate returns the cache entry for data read from source (a registry key, file
+// path or other label; "" for anonymous bytes). An entry for older content of the same
+// source is dropped; anonymous entries beyond maxAnonymousTemplates are dropped least
+// recently used first.
+func cachedTemplate(source string, data []byte) *parsedTemplate {
+	sum := sha256.Sum256(data)
+	key := source + "@" + hex.EncodeToString(sum[:])
+
+	templateCache.mu.Lock()
+	defer templateCache.mu.Unlock()
+	if t, ok := templateCache.entries[key]; ok {
+		if source == "" {
+			touchAnonymousTemplate(key)
+		}
+		return t
+	}
+	if source != "" {
+		if old, ok := templateCache.current[source]; ok {
+			delete(templateCache.entries, old)
+		}
+		templateCache.current[source] = key
+	} else {
+		templateCache.anonymous = append(templateCache.anonymous, key)
+		if len(templateCache.anonymous) > maxAnonymousTemplates {
+			delete(templateCache.entries, templateCache.anonymous[0])
+			templateCache.anonymous = templateCache.anonymous[1:]
+		}
+	}
+	t := &parsedTemplate{data: data}
+	templateCache.entries[key] = t
+	return t
+}
+
+// touchAnonymousTemplate marks an anonymous entry as the most recently used. The caller
+// holds templateCache.mu.
+func touchAnonymousTemplate(key string) {
+	if i := slices.Index(templateCache.anonymous, key); i >= 0 {
+		templateCache.anonymous = append(slices.Delete(templateCache.anonymous, i, i+1), key)
+	}
+}
+
+// Header returns the template's top-level fields. filePaths and run are only counted,
+// not decoded. Content that is not valid JSON has an empty header.
+func (t *parsedTemplate) Header() templateHeader {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.header == nil {
+		var raw struct {
+			Title     string             `json:"title"`
+			Slug      string             `json:"slug"`
+			Show      *CommandVisibility `json:"show"`
+			FilePaths []json.RawMessage  `json:"filePaths"`
+			Run       []json.RawMessage  `json:"run"`
+		}
+		h := templateHeader{}
+		if err := json.Unmarshal(t.data, &raw); err == nil {
+			h = templateHeader{
+				Title:        raw.Title,
+				Slug:         raw.Slug,
+				Show:         raw.Show,
+				HasFilePaths: len(raw.FilePaths) > 0,
+				HasRun:       len(raw.Run) > 0,
+			}
+		}
+		t.header = &h
+	}
+	return *t.header
+}
+
+// Variables returns the variable metadata declared by the template. The maps are copies
+// the caller may modify.
+func (t *parsedTemplate) Variables() templateVariables {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.vars == nil {
+		var obj map[string]any
+		_ = json.Unmarshal(t.data, &obj)
+		v := extractTemplateVariables(obj)
+		t.vars = &v
+	}
+	return templateVariables{
+		Descriptions: maps.Clone(t.vars.Descriptions),
+		Titles:       maps.Clone(t.vars.Titles),
+		Priorities:   maps.Clone(t.vars.Priorities),
+		Examples:     maps.Clone(t.vars.Examples),
+	}
+}
+
+// VariableKeys returns the variable keys inferred from the template for a project.
+// Templates that extend, include or read code from other templates depend on more than
+// their own content, so their keys are not cached.
+func (t *parsedTemplate) VariableKeys(projectPath string) ([]string, error) {
+	if referencesOtherTemplates(t.data) {
+		return getTemplateVariableKeysFromBytes(t.data, projectPath)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if keys, ok := t.keys[projectPath]; ok {
+		return slices.Clone(keys), nil
+	}
+	keys, err := getTemplateVariableKeysFromBytes(t.data, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	if t.keys == nil {
+		t.keys = map[string][]string{}
+	}
+	t.keys[projectPath] = keys
+	return slices.Clone(keys), nil
+}
+
+// referencesOtherTemplates reports whether template content pulls in other templates or
+// files when resolved.
+func referencesOtherTemplates(data []byte) bool {
+	for _, field := range []string{`"extends"`, `"include"`, `"codeFrom"`} {
+		if bytes.Contains(data, []byte(field)) {
+			return true
+		}
+	}
+	return false
+}
+
+// commandTemplate resolves a command and returns the cache entry for its template, or nil
+// when the command has no template. Names that are registry paths are tried directly.
+func commandTemplate(cmdName, projectPath string, registry *project.ProjectRegistry) *parsedTemplate {
+	resolved, err := NewResolver(projectPath, registry).Resolve(cmdName)
+	if err == nil && resolved.Template != nil {
+		return resolved.parsed()
+	}
+	if data, readErr := LoadCommandTemplate(cmdName); readErr == nil {
+		return cachedTemplate(cmdName, data)
+	}
+	return nil
+}
+
+// extractTemplateVariables collects variable metadata from the two schema shapes:
+// variables: { "VarName": { "title": ..., "description": ..., "priority": ..., "examples": [...] } }
+// and args: [ { "name": "VarName", "title" | "label": ..., "description" | "message": ..., ... } ].
+// Entries in args take precedence.
+func extractTemplateVariables(obj map[string]any) templateVariables {
+	v := templateVariables{
+		Descriptions: map[string]string{},
+		Titles:       map[string]string{},
+		Priorities:   map[string]int{},
+		Examples:     map[string][]string{},
+	}
+	add := func(name string, entry map[string]any, titleFallback, descFallback string) {
+		if s := firstNonBlankString(entry, "title", titleFallback); s != "" {
+			v.Titles[name] = s
+		}
+		if s := firstNonBlankString(entry, "description", descFallback); s != "" {
+			v.Descriptions[name] = s
+		}
+		if p, ok := variablePriority(entry["priority"]); ok {
+			v.Priorities[name] = p
+		}
+		if ex := nonBlankStrings(entry["examples"]); len(ex) > 0 {
+			v.Examples[name] = ex
+		}
+	}
+	if m, ok := obj["variables"].(map[string]any); ok {
+		for name, raw := range m {
+			if entry, ok := raw.(map[string]any); ok {
+				add(name, entry, "", "")
+			}
+		}
+	}
+	if arr, ok := obj["args"].([]any); ok {
+		for _, raw := range arr {
+			entry, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _ := entry["name"].(string)
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			add(name, entry, "label", "message")
+		}
+	}
+	return v
+}
+
+// firstNonBlankString returns the first non-blank string among the given fields.
+func firstNonBlankString(entry map[string]any, fields ...string) string {
+	for _, f := range fields {
+		if f == "" {
+			continue
+		}
+		if s, ok := entry[f].(string); ok && strings.TrimSpace(s) != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// variablePriority reads a priority given as a number or numeric string.
+func variablePriority(raw any) (int, bool) {
+	switch t := raw.(type) {
+	case float64:
+		return int(t), true
+	case string:
+		if n, err := json.Number(t).Int64(); err == nil {
+			return int(n), true
+		}
+	}
+	return 0, false
+}
+
+// nonBlankStrings returns the non-blank strings of a JSON array.
+func nonBlankStrings(raw any) []string {
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, it := range arr {
+		if s, ok := it.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTemplateCache tests that parses are shared per content and replaced when a source changes.
+func TestTemplateCache(t *testing.T) {
+	v1 := []byte(`{"title": "Thing", "filePaths": [{"path": "{{.Name}}.ts"}],
+		"args": [{"name": "Name", "label": "Thing name", "message": "What is it called?", "priority": "2", "examples": ["foo", " "]}],
+		"variables": {"Name": {"title": "Ignored", "description": "Used as the file name"}}}`)
+	a := cachedTemplate("test:thing", v1)
+	if b := cachedTemplate("test:thing", v1); a != b {
+		t.Fatal("expected the same entry for unchanged content")
+	}
+	h := a.Header()
+	if h.Title != "Thing" || !h.HasFilePaths || h.HasRun {
+		t.Errorf("unexpected header: %+v", h)
+	}
+	vars := a.Variables()
+	if vars.Titles["Name"] != "Thing name" || vars.Descriptions["Name"] != "What is it called?" || vars.Priorities["Name"] != 2 {
+		t.Errorf("args should take precedence over variables: %+v", vars)
+	}
+	if ex := vars.Examples["Name"]; len(ex) != 1 || ex[0] != "foo" {
+		t.Errorf("unexpected examples: %v", ex)
+	}
+	vars.Titles["Name"] = "changed"
+	if a.Variables().Titles["Name"] != "Thing name" {
+		t.Error("callers must not be able to modify cached metadata")
+	}
+	keys, err := a.VariableKeys("")
+	if err != nil || len(keys) != 1 || keys[0] != "Name" {
+		t.Errorf("unexpected keys: %v (%v)", keys, err)
+	}
+
+	v2 := []byte(`{"title": "Thing", "run": [{"type": "invoke", "slug": "other"}]}`)
+	c := cachedTemplate("test:thing", v2)
+	if c == a || !IsCompositeTemplate(v2) || IsCompositeTemplate(v1) {
+		t.Error("expected a new entry for changed content")
+	}
+	templateCache.mu.Lock()
+	n := 0
+	for key := range templateCache.entries {
+		if len(key) > len("test:thing@") && key[:len("test:thing@")] == "test:thing@" {
+			n++
+		}
+	}
+	templateCache.mu.Unlock()
+	if n != 1 {
+		t.Errorf("expected the old entry to be dropped, found %d entries", n)
+	}
+}
+
+// TestLazyTemplateRegistration tests that registering a template neither parses it nor
+// resolves its base until the command list is read.
+func TestLazyTemplateRegistration(t *testing.T) {
+	key := userTemplatesRoot + "/lazy/notes/lazy-note.json"
+	templateRegistry[key] = []byte(`{"title": "Lazy Note", "slug": "lazy-note", "extends": "user-templates/lazy/notes/missing-base.json"}`)
+	defer unregisterTemplates(userTemplatesRoot + "/lazy/")
+
+	registerTemplates([]string{key})
+	templateCache.mu.Lock()
+	_, parsed := templateCache.current[key]
+	templateCache.mu.Unlock()
+	if parsed {
+		t.Fatal("template was parsed when it was registered")
+	}
+
+	// The base is only needed once the commands are read, so it may be registered later
+	templateRegistry[userTemplatesRoot+"/lazy/notes/missing-base.json"] = []byte(`{"filePaths": [{"path": "notes", "nodes": [{"name": "{{.KebabName}}.md", "code": "x"}]}]}`)
+	if spec := GetCommandSpec("lazy-note"); spec.TemplatePath != key || spec.Name != "add Lazy Note" {
+		t.Errorf("expected the template to be registered with its base's files, got %+v", spec)
+	}
+}
+
+// TestAnonymousTemplateEviction tests that entries for anonymous bytes stay bounded and
+// that recently used ones survive.
+func TestAnonymousTemplateEviction(t *testing.T) {
+	first := cachedTemplate("", []byte(`{"title": "kept"}`))
+	for i := 0; i < 3*maxAnonymousTemplates; i++ {
+		cachedTemplate("", []byte(fmt.Sprintf(`{"title": "clip %d"}`, i)))
+		if i%8 == 0 && cachedTemplate("", []byte(`{"title": "kept"}`)) != first {
+			t.Fatal("recently used anonymous entry was evicted")
+		}
+	}
+	templateCache.mu.Lock()
+	n := 0
+	for key := range templateCache.entries {
+		if strings.HasPrefix(key, "@") {
+			n++
+		}
+	}
+	anonymous := len(templateCache.anonymous)
+	templateCache.mu.Unlock()
+	if n > maxAnonymousTemplates || anonymous != n {
+		t.Errorf("expected at most %d anonymous entries, found %d (tracked %d)", maxAnonymousTemplates, n, anonymous)
+	}
+}
+
+// TestProjectContextInvalidation tests that project files are parsed again once they change.
+func TestProjectContextInvalidation(t *testing.T) {
+	dir := t.TempDir()
+	pkg := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(pkg, []byte(`{"name": "one", "nextgen-identifiers": ["nextjs"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec := CommandSpec{Visibility: &CommandVisibility{PackageJSONArrayContains: map[string]string{"nextgen-identifiers": "sanity"}}}
+	if IsCommandVisible(spec, dir) {
+		t.Fatal("command should be hidden")
+	}
+	if loadProjectContext(dir) != loadProjectContext(dir) {
+		t.Error("expected the context to be reused while files are unchanged")
+	}
+
+	if err := os.WriteFile(pkg, []byte(`{"name": "one", "nextgen-identifiers": ["nextjs", "sanity"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(pkg, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if !IsCommandVisible(spec, dir) {
+		t.Error("command should be visible after package.json changed")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, ".nextgen"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".nextgen", "command-packages.json"), []byte(`{"identifiers": ["hooks"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !IsCommandVisible(CommandSpec{Visibility: &CommandVisibility{CommandPackagesContains: []string{"hooks"}}}, dir) {
+		t.Error("command should be visible once command-packages.json lists it")
+	}
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+)
+
+// -----------------------------------------------------------------------------
+// [SOURCES] Template sources: embedded native commands and the user template directory
+// -----------------------------------------------------------------------------
+
+// Registry keys of user templates start with userTemplatesRoot, mirroring the
+// "native-commands/" prefix used for embedded templates.
+// Templates of installed packs are keyed under packsRoot/<pack name>.
+const (
+	nativeTemplatesRoot = "native-commands"
+	userTemplatesRoot   = "user-templates"
+	packsRoot           = "packs"
+)
+
+// partialsDirName is the folder (directly under a template root) that holds shared partials.
+// Partials are referenced by other templates and are not commands themselves.
+const partialsDirName = "partials"
+
+// UserTemplateDir returns the user-level template directory. It can be overridden with
+// NEXTGEN_TEMPLATES_DIR and defaults to ~/.config/nextgen-cli/templates.
+func UserTemplateDir() string {
+	if dir := strings.TrimSpace(os.Getenv("NEXTGEN_TEMPLATES_DIR")); dir != "" {
+		return dir
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "nextgen-cli", "templates")
+}
+
+// IsTemplateTreePath reports whether p addresses a file or folder in a browsable template
+// tree (embedded native commands or the user template directory).
+func IsTemplateTreePath(p string) bool {
+	for _, root := range []string{nativeTemplatesRoot, userTemplatesRoot, packsRoot} {
+		if p == root || strings.HasPrefix(p, root+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTemplateRoot splits a registry key into its template root (e.g. "native-commands",
+// "user-templates" or "packs/<name>") and the path below it. The root is empty for keys
+// outside a template tree.
+func splitTemplateRoot(key string) (string, string) {
+	for _, root := range []string{nativeTemplatesRoot, userTemplatesRoot} {
+		if strings.HasPrefix(key, root+"/") {
+			return root, strings.TrimPrefix(key, root+"/")
+		}
+	}
+	if strings.HasPrefix(key, packsRoot+"/") {
+		if name, rel, ok := strings.Cut(strings.TrimPrefix(key, packsRoot+"/"), "/"); ok {
+			return packsRoot + "/" + name, rel
+		}
+	}
+	return "", key
+}
+
+// templateFS maps a registry path to the filesystem that holds it and the path within it.
+func templateFS(p string) (fs.FS, string) {
+	if p == userTemplatesRoot || strings.HasPrefix(p, userTemplatesRoot+"/") {
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, userTemplatesRoot), "/")
+		if rel == "" {
+			rel = "."
+		}
+		return os.DirFS(UserTemplateDir()), rel
+	}
+	if p == packsRoot || strings.HasPrefix(p, packsRoot+"/") {
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, packsRoot), "/")
+		if rel == "" {
+			rel = "."
+		}
+		return os.DirFS(loadedPacksDir), rel
+	}
+	return commandFiles, p
+}
+
+// scanTemplateSource walks root inside fsys, stores every .json template in the registry
+// under keyPrefix and returns the discovered registry keys.
+func scanTemplateSource(fsys fs.FS, root, keyPrefix string) ([]string, error) {
+	var discovered []string
+	err := fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(p, root), "/")
+		if root == "." {
+			rel = p
+		}
+		if d.IsDir() {
+			if rel == partialsDirName || (rel != "." && strings.HasPrefix(d.Name(), ".")) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(d.Name()) != ".json" || rel == packManifestName {
+			return nil
+		}
+		data, readErr := fs.ReadFile(fsys, p)
+		if readErr != nil {
+			return fmt.Errorf("could not read file %s: %w", p, readErr)
+		}
+		key := path.Join(keyPrefix, rel)
+		// Store file contents in the registry, keyed by path (like "native-commands/page-and-archive.json")
+		templateRegistry[key] = data
+		discovered = append(discovered, key)
+		return nil
+	})
+	return discovered, err
+}
+
+// scanUserTemplates loads templates from the user template directory, if it exists.
+func scanUserTemplates() []string {
+	dir := UserTemplateDir()
+	if dir == "" {
+		return nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil
+	}
+	discovered, err := scanTemplateSource(os.DirFS(dir), ".", userTemplatesRoot)
+	if err != nil {
+		cli.Warn("could not load user templates from %s: %v", dir, err)
+	}
+	return discovered
+}
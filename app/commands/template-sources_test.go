@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+// TestMain points the user template directory at an empty folder, so the templates in the
+// machine's ~/.config/nextgen-cli/templates, which init() loaded, do not affect the tests.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "ng-user-templates-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("NEXTGEN_TEMPLATES_DIR", dir)
+	reloadUserTemplates()
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// reloadUserTemplates drops the registered user templates and loads UserTemplateDir() again.
+func reloadUserTemplates() {
+	unregisterTemplates(userTemplatesRoot + "/")
+	registerTemplates(scanUserTemplates())
+}
+
+// TestScanTemplateSource tests which files of a template tree are stored and under which keys.
+func TestScanTemplateSource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"root/notes.json":                {Data: []byte(`{"title": "notes"}`)},
+		"root/blog/posts/add-post.json":  {Data: []byte(`{"title": "post"}`)},
+		"root/partials/header.json":      {Data: []byte(`{"code": "header"}`)},
+		"root/blog/partials/footer.json": {Data: []byte(`{"title": "nested partials folders are templates"}`)},
+		"root/.drafts/wip.json":          {Data: []byte(`{}`)},
+		"root/README.md":                 {Data: []byte("not a template")},
+		"root/pack.json":                 {Data: []byte(`{"name": "manifest"}`)},
+	}
+	defer unregisterTemplates("test-source/")
+	keys, err := scanTemplateSource(fsys, "root", "test-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	want := []string{"test-source/blog/partials/footer.json", "test-source/blog/posts/add-post.json", "test-source/notes.json"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("got keys %v, want %v", keys, want)
+	}
+	if string(templateRegistry["test-source/notes.json"]) != `{"title": "notes"}` {
+		t.Errorf("template content not stored: %q", templateRegistry["test-source/notes.json"])
+	}
+}
+
+// TestUserTemplates tests that templates in NEXTGEN_TEMPLATES_DIR become commands with
+// their titles, slugs and visibility, that bundle folders get a command, and that partials
+// and templates without filePaths or run are not commands.
+func TestUserTemplates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"notes.json":             `{"filePaths": [{"path": "notes", "nodes": []}]}`,
+		"remove-draft.json":      `{"title": "Remove draft", "slug": "drop-draft", "filePaths": [{"path": "drafts", "nodes": []}]}`,
+		"blog/posts/post.json":   `{"title": "Blog post", "show": {"packageJson": {"name": "blog"}}, "filePaths": [{"path": "posts", "nodes": []}]}`,
+		"blog/posts/images.json": `{"title": "Post images", "run": [{"type": "invoke", "slug": "blog-post"}]}`,
+		"partials/header.json":   `{"title": "Header", "filePaths": [{"path": "x", "nodes": []}]}`,
+		"snippet.json":           `{"title": "Snippet", "code": "shared"}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("NEXTGEN_TEMPLATES_DIR", dir)
+	reloadUserTemplates()
+	t.Cleanup(func() { unregisterTemplates(userTemplatesRoot + "/") })
+
+	tests := []struct {
+		slug, name, path string
+	}{
+		{"notes", "add notes", "user-templates/notes.json"},
+		{"drop-draft", "Remove draft", "user-templates/remove-draft.json"},
+		{"post", "add Blog post", "user-templates/blog/posts/post.json"},
+		{"images", "add Post images", "user-templates/blog/posts/images.json"},
+		{"blog-posts", "add posts", "auto/blog-posts.json"},
+	}
+	for _, tt := range tests {
+		spec := GetCommandSpec(tt.slug)
+		if spec.Name != tt.name || spec.TemplatePath != tt.path {
+			t.Errorf("%s: got %+v, want name %q and path %s", tt.slug, spec, tt.name, tt.path)
+		}
+	}
+	if spec := GetCommandSpec("post"); spec.Visibility == nil || spec.Visibility.PackageJSON["name"] != "blog" {
+		t.Errorf("show not carried over: %+v", spec.Visibility)
+	}
+	if spec := GetCommandSpec("blog-posts"); spec.Visibility == nil || len(spec.Visibility.AnyOf) != 2 || spec.Visibility.AnyOf[0].CommandPackagesContains[0] != "blog" {
+		t.Errorf("bundle should be limited to projects declaring its category: %+v", spec.Visibility)
+	}
+	for _, slug := range []string{"header", "snippet"} {
+		if spec := GetCommandSpec(slug); spec.TemplatePath != "" {
+			t.Errorf("%s must not be a command: %+v", slug, spec)
+		}
+	}
+	if _, ok := templateRegistry["user-templates/partials/header.json"]; ok {
+		t.Error("partials must not be loaded as templates")
+	}
+	if _, ok := templateRegistry["user-templates/snippet.json"]; !ok {
+		t.Error("templates without filePaths stay available to include")
+	}
+
+	// Reloading an emptied directory drops the commands and the bundle
+	if err := os.RemoveAll(filepath.Join(dir, "blog")); err != nil {
+		t.Fatal(err)
+	}
+	reloadUserTemplates()
+	if spec := GetCommandSpec("blog-posts"); spec.TemplatePath != "" {
+		t.Errorf("bundle of removed folder still registered: %+v", spec)
+	}
+	if spec := GetCommandSpec("notes"); spec.TemplatePath != "user-templates/notes.json" {
+		t.Errorf("remaining template lost on reload: %+v", spec)
+	}
+}
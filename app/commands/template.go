@@ -42,6 +42,7 @@ type TreeNode struct {
 	ID        string     `json:"id"`
 	Name      string     `json:"name"`
 	IsIndexer bool       `json:"isIndexer"` // even if false, we'll override if we see the marker in the code
+	RawCode   bool       `json:"rawCode"`   // emit code verbatim; placeholders in it are not rendered
 	// New schema uses actions/title/logic. We also accept legacy markers/mark/fallback.
 	Actions []InsertionAction `json:"actions"`
 	Markers []InsertionAction `json:"markers"`
@@ -200,10 +201,17 @@ func ExecuteJSONTemplate(jsonFilePath, projectPath string, placeholders map[stri
 	if err != nil {
 		return fmt.Errorf("could not read JSON template: %w", err)
 	}
+	templateBytes, err = ResolveTemplateIncludes(templateBytes, projectPath)
+	if err != nil {
+		return err
+	}
 	var template JSONCommandTemplate
 	if err := json.Unmarshal(templateBytes, &template); err != nil {
 		return fmt.Errorf("could not parse JSON template: %w", err)
 	}
+	if err := checkUnresolvedPlaceholders(template, placeholders); err != nil {
+		return err
+	}
 	for _, group := range template.FilePaths {
 		basePath := filepath.Join(projectPath, group.Path)
 		if err := gatherNodes(group.Nodes, basePath, projectPath, placeholders); err != nil {
@@ -236,7 +244,7 @@ func gatherNodes(nodes []TreeNode, basePath, projectPath string, placeholders ma
 		if err := os.MkdirAll(filepath.Dir(currentPath), 0755); err != nil {
 			return fmt.Errorf("failed to create parent directory for %s: %w", currentPath, err)
 		}
-		code := replacePlaceholders(node.Code, placeholders)
+		code := renderNodeCode(node, placeholders)
 
 		// Detect indexer
 		isIndexer := node.IsIndexer
@@ -579,14 +587,6 @@ func gatherNodes(nodes []TreeNode, basePath, projectPath string, placeholders ma
 	return nil
 }
 
-// replacePlaceholders walks through the placeholders map and replaces all occurrences.
-func replacePlaceholders(content string, placeholders map[string]string) string {
-	for oldVal, newVal := range placeholders {
-		content = strings.ReplaceAll(content, oldVal, newVal)
-	}
-	return content
-}
-
 // RunJsonTemplate loads and executes a command template from a JSON file.
 func RunJsonTemplate(jsonFilePath, projectPath string, placeholders map[string]string) error {
 	if err := ExecuteJSONTemplate(jsonFilePath, projectPath, placeholders); err != nil {
@@ -605,10 +605,17 @@ func RunJsonTemplateBytes(jsonBytes []byte, projectPath string, placeholders map
 
 // ExecuteJSONTemplateFromMemory executes the template logic given the JSON bytes.
 func ExecuteJSONTemplateFromMemory(jsonBytes []byte, projectPath string, placeholders map[string]string) error {
+	jsonBytes, err := ResolveTemplateIncludes(jsonBytes, projectPath)
+	if err != nil {
+		return err
+	}
 	var template JSONCommandTemplate
 	if err := json.Unmarshal(jsonBytes, &template); err != nil {
 		return fmt.Errorf("could not parse JSON template: %w", err)
 	}
+	if err := checkUnresolvedPlaceholders(template, placeholders); err != nil {
+		return err
+	}
 	for _, group := range template.FilePaths {
 		basePath := filepath.Join(projectPath, group.Path)
 		if err := gatherNodes(group.Nodes, basePath, projectPath, placeholders); err != nil {
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [TRANSACTION] Undoable batches of template runs
+// -----------------------------------------------------------------------------
+
+// FileTransaction records the original state of files before templates write them, so a
+// batch of runs can be diffed and undone as a whole. Only tracked files are restored, so
+// callers track the planned files of each template before running it.
+type FileTransaction struct {
+	projectPath string
+	originals   map[string][]byte // nil when the file did not exist
+	order       []string
+	createdDirs []string // directories that did not exist, outermost first
+}
+
+// NewFileTransaction starts a transaction for files in projectPath.
+func NewFileTransaction(projectPath string) *FileTransaction {
+	return &FileTransaction{projectPath: projectPath, originals: map[string][]byte{}}
+}
+
+// Track records the current state of files (relative to the project) that are about to be
+// written. Files that are already tracked keep their first recorded state.
+func (t *FileTransaction) Track(relPaths []string) error {
+	for _, rel := range relPaths {
+		rel = filepath.Clean(rel)
+		if _, tracked := t.originals[rel]; tracked {
+			continue
+		}
+		abs := filepath.Join(t.projectPath, rel)
+		data, err := os.ReadFile(abs)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to snapshot %s: %w", rel, err)
+		}
+		if err != nil {
+			t.trackMissingDirs(filepath.Dir(abs))
+			data = nil
+		} else if data == nil {
+			data = []byte{}
+		}
+		t.originals[rel] = data
+		t.order = append(t.order, rel)
+	}
+	return nil
+}
+
+// trackMissingDirs records the missing ancestors of dir, up to the project root.
+func (t *FileTransaction) trackMissingDirs(dir string) {
+	var missing []string
+	for dir != t.projectPath && dir != filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		missing = append([]string{dir}, missing...)
+		dir = filepath.Dir(dir)
+	}
+	for _, d := range missing {
+		if !slices.Contains(t.createdDirs, d) {
+			t.createdDirs = append(t.createdDirs, d)
+		}
+	}
+}
+
+// Changed returns the tracked files that were created and those whose content changed,
+// sorted by path.
+func (t *FileTransaction) Changed() (created, updated []string) {
+	for _, rel := range t.order {
+		before := t.originals[rel]
+		after, err := os.ReadFile(filepath.Join(t.projectPath, rel))
+		switch {
+		case err != nil:
+			continue
+		case before == nil:
+			created = append(created, filepath.ToSlash(rel))
+		case string(before) != string(after):
+			updated = append(updated, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(created)
+	sort.Strings(updated)
+	return created, updated
+}
+
+// Diff returns a unified diff of every tracked file against its recorded state.
+func (t *FileTransaction) Diff() string {
+	paths := append([]string{}, t.order...)
+	sort.Strings(paths)
+	var out strings.Builder
+	for _, rel := range paths {
+		after, err := os.ReadFile(filepath.Join(t.projectPath, rel))
+		if err != nil {
+			continue
+		}
+		before := t.originals[rel]
+		from := "a/" + filepath.ToSlash(rel)
+		if before == nil {
+			from = "/dev/null"
+		}
+		out.WriteString(UnifiedDiff(from, "b/"+filepath.ToSlash(rel), string(before), string(after)))
+	}
+	return out.String()
+}
+
+// Rollback restores every tracked file and removes the directories the transaction created.
+func (t *FileTransaction) Rollback() error {
+	var errs []error
+	for _, rel := range t.order {
+		abs := filepath.Join(t.projectPath, rel)
+		before := t.originals[rel]
+		if before == nil {
+			if err := os.Remove(abs); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(abs, before, 0644); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := len(t.createdDirs) - 1; i >= 0; i-- {
+		// Only empty directories are removed; anything else written there is kept
+		_ = os.Remove(t.createdDirs[i])
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+)
+
+// -----------------------------------------------------------------------------
+// [VARIABLES] Template variables from the command line, files and the environment
+// -----------------------------------------------------------------------------
+
+// EnvVarPrefix prefixes environment variables that provide template variables
+// (e.g. NG_VAR_Name=Foo).
+const EnvVarPrefix = "NG_VAR_"
+
+// TemplateVariable is a variable a template asks for, in positional order.
+type TemplateVariable struct {
+	Name     string
+	Message  string // prompt text from the template's args, used in errors
+	Default  string
+	Required bool
+	Choices  []string // values of a select variable
+	// requiredWhen: required when another variable has the given value
+	whenVar, whenEquals string
+}
+
+// VariableInput holds the variable values given for one invocation. Later sources win:
+// shared values, the vars file, then --var. Positional arguments fill the variables those
+// sources left unset, in positional order, and NG_VAR_ environment variables only fill
+// what is still unset after that.
+type VariableInput struct {
+	Positional []string
+	Named      map[string]string // --var Key=Value
+	Shared     map[string]string // values offered to several commands (e.g. a manifest's vars); unmatched names are ignored
+	FilePath   string            // --vars-file
+	Environ    []string          // os.Environ(), for NG_VAR_ variables
+}
+
+// TemplateVariables returns the variables of a template in positional order: variables
+// declared in args first, in declaration order, then the inferred keys alphabetically.
+// Inferred keys are required; declared ones only when marked required (or requiredWhen).
+func TemplateVariables(templateBytes []byte, projectPath string) []TemplateVariable {
+	keys := InferTemplateVariableKeys(templateBytes, projectPath)
+	var tmpl struct {
+		Args []ArgDef `json:"args"`
+	}
+	_ = json.Unmarshal(templateBytes, &tmpl)
+
+	var vars []TemplateVariable
+	declared := map[string]bool{}
+	for _, a := range tmpl.Args {
+		name := strings.TrimSpace(a.Name)
+		if name == "" || declared[name] {
+			continue
+		}
+		declared[name] = true
+		v := TemplateVariable{Name: name, Message: a.Message, Default: a.Default, Required: a.Required}
+		for _, c := range a.Choices {
+			if c.Value != "" {
+				v.Choices = append(v.Choices, c.Value)
+			} else if c.Name != "" {
+				v.Choices = append(v.Choices, c.Name)
+			}
+		}
+		if a.RequiredWhen != nil {
+			v.whenVar, v.whenEquals = a.RequiredWhen.Var, a.RequiredWhen.Equals
+		}
+		vars = append(vars, v)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !declared[k] {
+			vars = append(vars, TemplateVariable{Name: k, Required: true})
+		}
+	}
+	return vars
+}
+
+// ResolveTemplateVariables combines the variable sources of an invocation into the values
+// for a template. Missing required variables are reported together, with their messages.
+func ResolveTemplateVariables(vars []TemplateVariable, in VariableInput) (map[string]string, error) {
+	lookup := func(name string) string {
+		for _, v := range vars {
+			if variableNameKey(v.Name) == variableNameKey(name) {
+				return v.Name
+			}
+		}
+		return name
+	}
+
+	values := map[string]string{}
+	for k, v := range in.Shared {
+		if name := lookup(k); hasTemplateVariable(vars, name) {
+			values[name] = v
+		}
+	}
+	if in.FilePath != "" {
+		fromFile, err := LoadVarsFile(in.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fromFile {
+			values[lookup(k)] = v
+		}
+	}
+	for k, v := range in.Named {
+		name := lookup(k)
+		if !hasTemplateVariable(vars, name) {
+			cli.Warn("variable %s does not match a variable of this command", k)
+		}
+		values[name] = v
+	}
+
+	positional := in.Positional
+	for _, v := range vars {
+		if len(positional) == 0 {
+			break
+		}
+		if _, given := values[v.Name]; !given {
+			values[v.Name] = positional[0]
+			positional = positional[1:]
+		}
+	}
+	if len(positional) > 0 {
+		return nil, fmt.Errorf("too many arguments: %s", strings.Join(positional, " "))
+	}
+	// Ambient NG_VAR_ values only fill what the command line left unset
+	for _, kv := range in.Environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, EnvVarPrefix) || len(key) == len(EnvVarPrefix) {
+			continue
+		}
+		name := lookup(strings.TrimPrefix(key, EnvVarPrefix))
+		if _, given := values[name]; !given {
+			values[name] = value
+		}
+	}
+
+	var missing []string
+	for _, v := range vars {
+		if _, given := values[v.Name]; given {
+			continue
+		}
+		required := v.Required
+		if v.whenVar != "" && values[lookup(v.whenVar)] == v.whenEquals {
+			required = true
+		}
+		if !required {
+			values[v.Name] = v.Default
+			continue
+		}
+		line := "  - " + v.Name
+		if v.Message != "" {
+			line += ": " + v.Message
+		}
+		missing = append(missing, line)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required variables:\n%s", strings.Join(missing, "\n"))
+	}
+	return values, nil
+}
+
+// LoadVarsFile reads variable values from a JSON or YAML file (chosen by extension; other
+// extensions are tried as JSON, then YAML). Values must be scalars.
+func LoadVarsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vars file: %w", err)
+	}
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".json":
+		err = decodeJSONVars(data, &raw)
+	default:
+		if err = decodeJSONVars(data, &raw); err != nil {
+			err = yaml.Unmarshal(data, &raw)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vars file %s: %w", path, err)
+	}
+	return stringifyVars(raw, path)
+}
+
+// decodeJSONVars decodes a JSON object keeping numbers as written, so 1000000 stays
+// "1000000" rather than becoming 1e+06.
+func decodeJSONVars(data []byte, raw *map[string]any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(raw); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("unexpected data after the top-level object")
+	}
+	return nil
+}
+
+// stringifyVars converts scalar values (strings, numbers, booleans) to strings.
+func stringifyVars(raw map[string]any, source string) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch t := v.(type) {
+		case nil:
+			values[k] = ""
+		case string:
+			values[k] = t
+		case float64:
+			values[k] = strconv.FormatFloat(t, 'f', -1, 64)
+		case bool, int, int64, uint64, json.Number:
+			values[k] = fmt.Sprint(t)
+		default:
+			return nil, fmt.Errorf("variable %s in %s must be a string, number or boolean", k, source)
+		}
+	}
+	return values, nil
+}
+
+// variableNameKey normalizes a variable name so that Name, name and NAME match, and
+// PAGE_NAME matches PageName.
+func variableNameKey(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+func hasTemplateVariable(vars []TemplateVariable, name string) bool {
+	for _, v := range vars {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestResolveTemplateVariables tests precedence between variable sources and how missing
+// and optional variables are reported.
+func TestResolveTemplateVariables(t *testing.T) {
+	tmpl := []byte(`{"args": [{"name": "Title", "message": "Page title", "required": true}, {"name": "Layout", "default": "basic"}],
+		"filePaths": [{"path": "pages", "nodes": [{"name": "{{.KebabTitle}}.md", "code": "{{.Layout}} {{.Author}} {{.Body}}"}]}]}`)
+	vars := TemplateVariables(tmpl, "")
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	if want := []string{"Title", "Layout", "Author", "Body"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected variable order %v, want %v", names, want)
+	}
+
+	varsFile := filepath.Join(t.TempDir(), "vars.yaml")
+	if err := os.WriteFile(varsFile, []byte("author: File\nbody: From file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ResolveTemplateVariables(vars, VariableInput{
+		Positional: []string{"About"},
+		Named:      map[string]string{"body": "Named"},
+		FilePath:   varsFile,
+		Environ:    []string{"NG_VAR_AUTHOR=Env", "NG_VAR_TITLE=Env title", "NG_VAR_LAYOUT=wide", "HOME=/root"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The positional argument wins over NG_VAR_TITLE; env only fills the unset Layout
+	want := map[string]string{"Title": "About", "Layout": "wide", "Author": "File", "Body": "Named"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = ResolveTemplateVariables(vars, VariableInput{Named: map[string]string{"Title": "T", "Author": "A", "Body": "B"}})
+	if err != nil || got["Layout"] != "basic" {
+		t.Errorf("expected the optional variable's default: %v %v", got, err)
+	}
+
+	_, err = ResolveTemplateVariables(vars, VariableInput{Positional: []string{}, Named: map[string]string{"Author": "A"}})
+	if err == nil || !strings.Contains(err.Error(), "- Title: Page title") || !strings.Contains(err.Error(), "- Body") {
+		t.Errorf("expected missing Title and Body with messages, got %v", err)
+	}
+	if _, err := ResolveTemplateVariables(vars, VariableInput{Positional: []string{"a", "b", "c", "d", "e"}}); err == nil {
+		t.Error("expected too many arguments to fail")
+	}
+
+	// An exported variable does not shift positional arguments
+	got, err = ResolveTemplateVariables(vars, VariableInput{
+		Positional: []string{"Foo", "full", "Ann", "Text"},
+		Environ:    []string{"NG_VAR_Title=Exported"},
+	})
+	if want := map[string]string{"Title": "Foo", "Layout": "full", "Author": "Ann", "Body": "Text"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v (%v), want %v", got, err, want)
+	}
+}
+
+// TestLoadVarsFile tests that numbers keep the form they were written in.
+func TestLoadVarsFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"vars.json": `{"Count": 1000000, "Ratio": 0.25, "Big": 12345678901234567890, "Draft": true, "Empty": null}`,
+		"vars.yaml": "Count: 1000000\nRatio: 0.25\nBig: 1e21\nDraft: true\nEmpty:\n",
+	}
+	wants := map[string]map[string]string{
+		"vars.json": {"Count": "1000000", "Ratio": "0.25", "Big": "12345678901234567890", "Draft": "true", "Empty": ""},
+		"vars.yaml": {"Count": "1000000", "Ratio": "0.25", "Big": "1000000000000000000000", "Draft": "true", "Empty": ""},
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := LoadVarsFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, wants[name]) {
+			t.Errorf("%s: got %v, want %v", name, got, wants[name])
+		}
+	}
+}
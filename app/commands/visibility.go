@@ -0,0 +1,519 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+)
+
+// -----------------------------------------------------------------------------
+// [VISIBILITY] When a command is shown in a project, and why
+// -----------------------------------------------------------------------------
+
+// CommandVisibilityClause is a set of conditions that must all hold. Nested allOf, anyOf
+// and not clauses combine with the clause's other conditions.
+type CommandVisibilityClause struct {
+	PackageJSON              map[string]string         `json:"packageJson"`              // top-level package.json key → value
+	PackageJSONArrayContains map[string]string         `json:"packageJsonArrayContains"` // package.json array key → element
+	CommandPackagesContains  []string                  `json:"commandPackagesContains"`  // identifiers in .nextgen/command-packages.json
+	Dependency               map[string]string         `json:"dependency"`               // package → semver range, from package.json or another ecosystem's manifest
+	FileExists               []string                  `json:"fileExists"`               // paths relative to the project
+	Glob                     []string                  `json:"glob"`                     // patterns that must each match a project file
+	Detected                 []string                  `json:"detected"`                 // detected frameworks or ecosystems (nextjs, go, django, ...)
+	Not                      *CommandVisibilityClause  `json:"not"`
+	AllOf                    []CommandVisibilityClause `json:"allOf"`
+	AnyOf                    []CommandVisibilityClause `json:"anyOf"`
+}
+
+// CommandVisibility defines optional conditions for when a command should be shown (the
+// template's "show"). When anyOf is set at the top level, the other top-level conditions
+// are ignored and the command is shown when any clause holds.
+type CommandVisibility CommandVisibilityClause
+
+// Walk calls fn for the clause and every clause nested in it.
+func (c CommandVisibilityClause) Walk(fn func(CommandVisibilityClause)) {
+	fn(c)
+	if c.Not != nil {
+		c.Not.Walk(fn)
+	}
+	for _, sub := range c.AllOf {
+		sub.Walk(fn)
+	}
+	for _, sub := range c.AnyOf {
+		sub.Walk(fn)
+	}
+}
+
+// Visibility conditions, as named in VisibilityCheck.Condition.
+const (
+	CondPackageJSON              = "packageJson"
+	CondPackageJSONArrayContains = "packageJsonArrayContains"
+	CondCommandPackagesContains  = "commandPackagesContains"
+	CondDependency               = "dependency"
+	CondFileExists               = "fileExists"
+	CondGlob                     = "glob"
+	CondDetected                 = "detected"
+)
+
+// VisibilityCheck is one evaluated condition of a visibility rule. Group is the index of
+// the top-level anyOf clause it belongs to, or -1 for a top-level condition; Path locates
+// it within that clause (e.g. "allOf[0].not"). Negated checks sit under an odd number of
+// nots and pass when their condition does not hold.
+type VisibilityCheck struct {
+	Group     int    `json:"group"`
+	Path      string `json:"path,omitempty"`
+	Condition string `json:"condition"`
+	Key       string `json:"key"`
+	Expected  string `json:"expected"`
+	Actual    string `json:"actual"`
+	Negated   bool   `json:"negated,omitempty"`
+	Passed    bool   `json:"passed"`
+}
+
+// holds reports whether the condition itself holds, before negation.
+func (c VisibilityCheck) holds() bool { return c.Passed != c.Negated }
+
+// Describe explains the check in one line, e.g. `package.json "name" is "web", not "studio"`.
+func (c VisibilityCheck) Describe() string {
+	s := c.describeCondition()
+	if c.Negated {
+		if c.holds() {
+			return s + " (must not be)"
+		}
+		return s + " (as required)"
+	}
+	return s
+}
+
+func (c VisibilityCheck) describeCondition() string {
+	holds := c.holds()
+	quoted := func(s string) string {
+		if strings.HasPrefix(s, "(") {
+			return s
+		}
+		return fmt.Sprintf("%q", s)
+	}
+	// has explains a failed contains check; parenthesised values are already a reason
+	has := func(s string) string {
+		if strings.HasPrefix(s, "(") {
+			return s
+		}
+		return "(has " + s + ")"
+	}
+	switch c.Condition {
+	case CondPackageJSON:
+		if holds {
+			return fmt.Sprintf("package.json %q is %q", c.Key, c.Expected)
+		}
+		return fmt.Sprintf("package.json %q is %s, not %q", c.Key, quoted(c.Actual), c.Expected)
+	case CondPackageJSONArrayContains:
+		if holds {
+			return fmt.Sprintf("package.json %q contains %q", c.Key, c.Expected)
+		}
+		return fmt.Sprintf("package.json %q does not contain %q %s", c.Key, c.Expected, has(c.Actual))
+	case CondCommandPackagesContains:
+		if holds {
+			return fmt.Sprintf("%s lists %q", c.Key, c.Expected)
+		}
+		return fmt.Sprintf("%s does not list %q %s", c.Key, c.Expected, has(c.Actual))
+	case CondDependency:
+		if strings.HasPrefix(c.Actual, "(") {
+			return fmt.Sprintf("%q is %s", c.Key, strings.Trim(c.Actual, "()"))
+		}
+		if holds {
+			return fmt.Sprintf("%q %s satisfies %q", c.Key, c.Actual, c.Expected)
+		}
+		return fmt.Sprintf("%q %s does not satisfy %q", c.Key, c.Actual, c.Expected)
+	case CondFileExists:
+		if holds {
+			return fmt.Sprintf("%s exists", c.Key)
+		}
+		return fmt.Sprintf("%s does not exist", c.Key)
+	case CondGlob:
+		if holds {
+			return fmt.Sprintf("%s matches %s", c.Key, c.Actual)
+		}
+		return fmt.Sprintf("%s matches no file", c.Key)
+	case CondDetected:
+		if holds {
+			return fmt.Sprintf("%q is detected", c.Expected)
+		}
+		return fmt.Sprintf("%q is not detected (detected: %s)", c.Expected, c.Actual)
+	}
+	return fmt.Sprintf("%s %s: want %q, have %s", c.Condition, c.Key, c.Expected, c.Actual)
+}
+
+// Fix describes how to make the check pass.
+func (c VisibilityCheck) Fix() string {
+	if c.Negated {
+		switch c.Condition {
+		case CondPackageJSON:
+			return fmt.Sprintf("change %q in package.json", c.Key)
+		case CondPackageJSONArrayContains:
+			return fmt.Sprintf("remove %q from %q in package.json", c.Expected, c.Key)
+		case CondCommandPackagesContains:
+			return fmt.Sprintf("remove %q from .nextgen/command-packages.json", c.Expected)
+		case CondDependency:
+			return fmt.Sprintf("remove %q or use a version outside %q", c.Key, c.Expected)
+		case CondFileExists:
+			return fmt.Sprintf("remove %s", c.Key)
+		case CondGlob:
+			return fmt.Sprintf("remove files matching %s", c.Key)
+		case CondDetected:
+			return fmt.Sprintf("remove %s from the project's dependencies", c.Expected)
+		}
+		return c.Describe()
+	}
+	switch c.Condition {
+	case CondPackageJSON:
+		return fmt.Sprintf("set %q: %q in package.json", c.Key, c.Expected)
+	case CondPackageJSONArrayContains:
+		return fmt.Sprintf("add %q to %q in package.json", c.Expected, c.Key)
+	case CondCommandPackagesContains:
+		return fmt.Sprintf("add %q to .nextgen/command-packages.json", c.Expected)
+	case CondDependency:
+		if c.Expected == "" || c.Expected == "*" {
+			return fmt.Sprintf("install %q", c.Key)
+		}
+		return fmt.Sprintf("install %q %s", c.Key, c.Expected)
+	case CondFileExists:
+		return fmt.Sprintf("create %s", c.Key)
+	case CondGlob:
+		return fmt.Sprintf("add a file matching %s", c.Key)
+	case CondDetected:
+		return fmt.Sprintf("add %s to the project's dependencies", c.Expected)
+	}
+	return c.Describe()
+}
+
+// VisibilityTrace records how a command's visibility rule was evaluated: every condition
+// with its expected and actual value, whether the command is shown, why, and for a hidden
+// command the alternative ways to show it.
+type VisibilityTrace struct {
+	Visible bool              `json:"visible"`
+	Reason  string            `json:"reason"`
+	Checks  []VisibilityCheck `json:"checks"`
+	Fixes   []string          `json:"fixes,omitempty"`
+}
+
+// Failed returns the checks that did not pass.
+func (t VisibilityTrace) Failed() []VisibilityCheck {
+	var out []VisibilityCheck
+	for _, c := range t.Checks {
+		if !c.Passed {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Group returns the checks of one top-level anyOf clause (or the top-level checks for -1).
+func (t VisibilityTrace) Group(i int) []VisibilityCheck {
+	var out []VisibilityCheck
+	for _, c := range t.Checks {
+		if c.Group == i {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// visibilityResult is the outcome of evaluating part of a rule: whether it passed and, if
+// not, why and how to make it pass.
+type visibilityResult struct {
+	ok     bool
+	reason string
+	fix    string
+}
+
+// combineAll passes when every part passes (or there are none).
+func combineAll(parts []visibilityResult) visibilityResult {
+	var reasons, fixes []string
+	for _, p := range parts {
+		if !p.ok {
+			reasons = append(reasons, p.reason)
+			fixes = append(fixes, p.fix)
+		}
+	}
+	if len(reasons) == 0 {
+		return visibilityResult{ok: true}
+	}
+	return visibilityResult{reason: strings.Join(reasons, "; "), fix: strings.Join(fixes, " and ")}
+}
+
+// combineAny passes when any part passes (or there are none).
+func combineAny(parts []visibilityResult) visibilityResult {
+	if len(parts) == 0 {
+		return visibilityResult{ok: true}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	var reasons, fixes []string
+	for _, p := range parts {
+		if p.ok {
+			return visibilityResult{ok: true}
+		}
+		reasons = append(reasons, "("+p.reason+")")
+		fixes = append(fixes, "("+p.fix+")")
+	}
+	return visibilityResult{reason: "none of: " + strings.Join(reasons, " | "), fix: strings.Join(fixes, " or ")}
+}
+
+// visibilityEval evaluates a rule against one project, recording every check.
+type visibilityEval struct {
+	projectPath string
+	ctx         *projectContext
+	group       int
+	checks      []VisibilityCheck
+}
+
+func joinVisibilityPath(path, part string) string {
+	if path == "" {
+		return part
+	}
+	return path + "." + part
+}
+
+// clause evaluates every condition of a clause, without stopping at the first failure.
+// Under negation the clause passes when any of its conditions fails (De Morgan), so the
+// conditions are combined with "any" instead of "all".
+func (e *visibilityEval) clause(c CommandVisibilityClause, path string, negated bool) visibilityResult {
+	var parts []visibilityResult
+	leaf := func(check VisibilityCheck, holds bool) {
+		check.Group, check.Path, check.Negated, check.Passed = e.group, path, negated, holds != negated
+		e.checks = append(e.checks, check)
+		parts = append(parts, visibilityResult{ok: check.Passed, reason: check.Describe(), fix: check.Fix()})
+	}
+	for _, k := range sortedVisibilityKeys(c.PackageJSON) {
+		leaf(e.packageJSON(k, c.PackageJSON[k]))
+	}
+	for _, k := range sortedVisibilityKeys(c.PackageJSONArrayContains) {
+		leaf(e.packageJSONArrayContains(k, c.PackageJSONArrayContains[k]))
+	}
+	for _, id := range c.CommandPackagesContains {
+		leaf(e.commandPackagesContains(id))
+	}
+	for _, name := range sortedVisibilityKeys(c.Dependency) {
+		leaf(e.dependency(name, c.Dependency[name]))
+	}
+	for _, rel := range c.FileExists {
+		leaf(e.fileExists(rel))
+	}
+	for _, pattern := range c.Glob {
+		leaf(e.glob(pattern))
+	}
+	for _, id := range c.Detected {
+		leaf(e.detected(id))
+	}
+	for i, sub := range c.AllOf {
+		parts = append(parts, e.clause(sub, joinVisibilityPath(path, fmt.Sprintf("allOf[%d]", i)), negated))
+	}
+	if len(c.AnyOf) > 0 {
+		alts := make([]visibilityResult, 0, len(c.AnyOf))
+		for i, sub := range c.AnyOf {
+			alts = append(alts, e.clause(sub, joinVisibilityPath(path, fmt.Sprintf("anyOf[%d]", i)), negated))
+		}
+		if negated {
+			parts = append(parts, combineAll(alts))
+		} else {
+			parts = append(parts, combineAny(alts))
+		}
+	}
+	if c.Not != nil {
+		parts = append(parts, e.clause(*c.Not, joinVisibilityPath(path, "not"), !negated))
+	}
+	if negated {
+		return combineAny(parts)
+	}
+	return combineAll(parts)
+}
+
+func (e *visibilityEval) packageJSON(key, want string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondPackageJSON, Key: key, Expected: want}
+	data := e.ctx.PackageJSON
+	if data == nil {
+		c.Actual = "(no package.json)"
+		return c, false
+	}
+	actual, ok := data[key]
+	if !ok {
+		c.Actual = "(missing)"
+		return c, false
+	}
+	c.Actual = fmt.Sprint(actual)
+	return c, strings.TrimSpace(c.Actual) == strings.TrimSpace(want)
+}
+
+func (e *visibilityEval) packageJSONArrayContains(key, want string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondPackageJSONArrayContains, Key: key, Expected: want}
+	data := e.ctx.PackageJSON
+	if data == nil {
+		c.Actual = "(no package.json)"
+		return c, false
+	}
+	raw, ok := data[key]
+	if !ok {
+		c.Actual = "(missing)"
+		return c, false
+	}
+	arr, ok := raw.([]any)
+	if !ok {
+		c.Actual = fmt.Sprintf("(not an array: %v)", raw)
+		return c, false
+	}
+	found := false
+	items := make([]string, 0, len(arr))
+	for _, v := range arr {
+		if s, ok := v.(string); ok {
+			items = append(items, strings.TrimSpace(s))
+			found = found || strings.TrimSpace(s) == strings.TrimSpace(want)
+		}
+	}
+	c.Actual = "[" + strings.Join(items, ", ") + "]"
+	return c, found
+}
+
+func (e *visibilityEval) commandPackagesContains(want string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondCommandPackagesContains, Key: ".nextgen/command-packages.json", Expected: strings.TrimSpace(want)}
+	if len(e.ctx.CommandPackages) == 0 {
+		c.Actual = "(no command-packages.json)"
+		return c, false
+	}
+	listed := make([]string, 0, len(e.ctx.CommandPackages))
+	for id := range e.ctx.CommandPackages {
+		listed = append(listed, id)
+	}
+	sort.Strings(listed)
+	c.Actual = "[" + strings.Join(listed, ", ") + "]"
+	return c, e.ctx.CommandPackages[c.Expected]
+}
+
+// dependency checks a package in package.json's dependencies or devDependencies, or else
+// in another ecosystem's manifest (go.mod, Cargo.toml, ...), against a semver range. The
+// version compared is the lowest its declared spec allows (14.1.0 for "^14.1.0"). An empty
+// range or "*" only requires the package to be listed.
+func (e *visibilityEval) dependency(name, rng string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondDependency, Key: name, Expected: rng}
+	spec, ok := e.ctx.Dependencies[name]
+	if !ok {
+		for _, eco := range loadEcosystems(e.projectPath) {
+			if spec, ok = eco.Dependencies[name]; ok {
+				break
+			}
+		}
+	}
+	if !ok {
+		c.Actual = "(not a dependency)"
+		return c, false
+	}
+	c.Actual = spec
+	if strings.TrimSpace(rng) == "" || strings.TrimSpace(rng) == "*" {
+		return c, true
+	}
+	v, ok := versionFromSpec(spec)
+	return c, ok && semverSatisfies(v, rng)
+}
+
+// detected checks the frameworks recognised in package.json and the ecosystems found in
+// the project, with their frameworks, as project detection reports them.
+func (e *visibilityEval) detected(id string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondDetected, Key: "project", Expected: id}
+	set := map[string]bool{}
+	for _, pkg := range project.KnownNpmPackages(e.ctx.Dependencies) {
+		set[pkg] = true
+	}
+	for _, eco := range loadEcosystems(e.projectPath) {
+		set[eco.Language], set[eco.Type] = true, true
+		for _, pkg := range eco.Packages {
+			set[pkg] = true
+		}
+	}
+	listed := make([]string, 0, len(set))
+	for pkg := range set {
+		listed = append(listed, pkg)
+	}
+	sort.Strings(listed)
+	c.Actual = "[" + strings.Join(listed, ", ") + "]"
+	return c, set[strings.TrimSpace(id)]
+}
+
+func (e *visibilityEval) fileExists(rel string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondFileExists, Key: rel, Expected: "exists", Actual: "(missing)"}
+	if _, err := os.Stat(filepath.Join(e.projectPath, filepath.FromSlash(rel))); err != nil {
+		return c, false
+	}
+	c.Actual = "exists"
+	return c, true
+}
+
+func (e *visibilityEval) glob(pattern string) (VisibilityCheck, bool) {
+	c := VisibilityCheck{Condition: CondGlob, Key: pattern, Expected: "a match", Actual: "(no matches)"}
+	match := projectGlob(e.projectPath, pattern)
+	if match == "" {
+		return c, false
+	}
+	c.Actual = match
+	return c, true
+}
+
+func sortedVisibilityKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExplainVisibility evaluates a command's visibility rule for the project and returns the
+// trace. With a top-level anyOf the command is shown when any clause holds; otherwise every
+// top-level condition must hold.
+func ExplainVisibility(spec CommandSpec, projectPath string) VisibilityTrace {
+	v := spec.Visibility
+	if v == nil {
+		return VisibilityTrace{Visible: true, Reason: "no visibility rule"}
+	}
+	e := &visibilityEval{projectPath: projectPath, ctx: loadProjectContext(projectPath)}
+	var t VisibilityTrace
+	if len(v.AnyOf) > 0 {
+		var failed []string
+		for i, clause := range v.AnyOf {
+			e.group = i
+			res := e.clause(clause, "", false)
+			if res.ok {
+				if !t.Visible {
+					t.Visible, t.Reason = true, fmt.Sprintf("anyOf clause %d matches", i+1)
+				}
+				continue
+			}
+			failed = append(failed, res.reason)
+			t.Fixes = append(t.Fixes, res.fix)
+		}
+		t.Checks = e.checks
+		if t.Visible {
+			t.Fixes = nil
+		} else {
+			t.Reason = "no anyOf clause matches: " + strings.Join(failed, " | ")
+		}
+		return t
+	}
+	e.group = -1
+	res := e.clause(CommandVisibilityClause(*v), "", false)
+	t.Checks, t.Visible = e.checks, res.ok
+	if t.Visible {
+		t.Reason = "all conditions match"
+	} else {
+		t.Reason, t.Fixes = res.reason, []string{res.fix}
+	}
+	return t
+}
+
+// IsCommandVisible evaluates whether a command should be shown for the given project path.
+func IsCommandVisible(spec CommandSpec, projectPath string) bool {
+	return ExplainVisibility(spec, projectPath).Visible
+}
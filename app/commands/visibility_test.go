@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestExplainVisibility tests that every condition is traced with its expected and actual
+// value, and that anyOf clauses and top-level conditions decide visibility.
+func TestExplainVisibility(t *testing.T) {
+	projectPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectPath, "package.json"), []byte(`{"name": "web", "nextgen-identifiers": ["nextjs"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		visibility *CommandVisibility
+		visible    bool
+		reason     string
+		checks     []VisibilityCheck
+	}{
+		{"no rule", nil, true, "no visibility rule", nil},
+		{
+			"top-level",
+			&CommandVisibility{PackageJSON: map[string]string{"name": "studio", "private": "true"}},
+			false,
+			`package.json "name" is "web", not "studio"; package.json "private" is (missing), not "true"`,
+			[]VisibilityCheck{
+				{Group: -1, Condition: CondPackageJSON, Key: "name", Expected: "studio", Actual: "web"},
+				{Group: -1, Condition: CondPackageJSON, Key: "private", Expected: "true", Actual: "(missing)"},
+			},
+		},
+		{
+			"anyOf",
+			&CommandVisibility{AnyOf: []CommandVisibilityClause{
+				{CommandPackagesContains: []string{"nextjs"}},
+				{PackageJSONArrayContains: map[string]string{"nextgen-identifiers": "nextjs"}},
+			}},
+			true,
+			"anyOf clause 2 matches",
+			[]VisibilityCheck{
+				{Group: 0, Condition: CondCommandPackagesContains, Key: ".nextgen/command-packages.json", Expected: "nextjs", Actual: "(no command-packages.json)"},
+				{Group: 1, Condition: CondPackageJSONArrayContains, Key: "nextgen-identifiers", Expected: "nextjs", Actual: "[nextjs]", Passed: true},
+			},
+		},
+		{
+			"parenthesised actual",
+			&CommandVisibility{CommandPackagesContains: []string{"never"}, PackageJSONArrayContains: map[string]string{"keywords": "cms"}},
+			false,
+			`package.json "keywords" does not contain "cms" (missing); .nextgen/command-packages.json does not list "never" (no command-packages.json)`,
+			[]VisibilityCheck{
+				{Group: -1, Condition: CondPackageJSONArrayContains, Key: "keywords", Expected: "cms", Actual: "(missing)"},
+				{Group: -1, Condition: CondCommandPackagesContains, Key: ".nextgen/command-packages.json", Expected: "never", Actual: "(no command-packages.json)"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		trace := ExplainVisibility(CommandSpec{Name: tt.name, Visibility: tt.visibility}, projectPath)
+		if trace.Visible != tt.visible || trace.Reason != tt.reason {
+			t.Errorf("%s: visible %v (%q), want %v (%q)", tt.name, trace.Visible, trace.Reason, tt.visible, tt.reason)
+		}
+		if len(trace.Checks) != len(tt.checks) {
+			t.Errorf("%s: checks %+v, want %+v", tt.name, trace.Checks, tt.checks)
+			continue
+		}
+		for i, c := range trace.Checks {
+			if c != tt.checks[i] {
+				t.Errorf("%s: check %d = %+v, want %+v", tt.name, i, c, tt.checks[i])
+			}
+		}
+	}
+
+	hidden := CommandSpec{Visibility: &CommandVisibility{AnyOf: []CommandVisibilityClause{
+		{PackageJSON: map[string]string{"name": "studio"}},
+		{CommandPackagesContains: []string{"sanity"}},
+	}}}
+	trace := ExplainVisibility(hidden, projectPath)
+	if trace.Visible || !strings.HasPrefix(trace.Reason, "no anyOf clause matches: ") || len(trace.Failed()) != 2 {
+		t.Errorf("hidden anyOf: %+v", trace)
+	}
+	if IsCommandVisible(hidden, projectPath) {
+		t.Error("IsCommandVisible disagrees with the trace")
+	}
+	if len(trace.Fixes) != 2 || trace.Fixes[1] != `add "sanity" to .nextgen/command-packages.json` {
+		t.Errorf("hidden anyOf fixes: %q", trace.Fixes)
+	}
+}
+
+// TestVisibilityPredicates tests dependency ranges, fileExists, glob, not, allOf and nested
+// anyOf clauses.
+func TestVisibilityPredicates(t *testing.T) {
+	projectPath := t.TempDir()
+	files := map[string]string{
+		"package.json":                    `{"name": "web", "dependencies": {"next": "^14.1.0"}, "devDependencies": {"typescript": "~5.3.0"}}`,
+		"sanity.config.ts":                "",
+		"app/(site)/blog/[slug]/page.tsx": "",
+		"node_modules/x/page.tsx":         "",
+	}
+	for rel, content := range files {
+		path := filepath.Join(projectPath, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		rule    CommandVisibility
+		visible bool
+	}{
+		{"dependency in range", CommandVisibility{Dependency: map[string]string{"next": ">=13"}}, true},
+		{"dependency out of range", CommandVisibility{Dependency: map[string]string{"next": "<14"}}, false},
+		{"dev dependency", CommandVisibility{Dependency: map[string]string{"typescript": "^5"}}, true},
+		{"missing dependency", CommandVisibility{Dependency: map[string]string{"react": ""}}, false},
+		{"file exists", CommandVisibility{FileExists: []string{"sanity.config.ts"}}, true},
+		{"file missing", CommandVisibility{FileExists: []string{"next.config.js"}}, false},
+		{"glob", CommandVisibility{Glob: []string{"app/**/page.tsx"}}, true},
+		{"glob single level", CommandVisibility{Glob: []string{"app/*/page.tsx"}}, false},
+		{"glob skips node_modules", CommandVisibility{Glob: []string{"**/x/page.tsx"}}, false},
+		{"not", CommandVisibility{Not: &CommandVisibilityClause{FileExists: []string{"pages"}}}, true},
+		{"not failing", CommandVisibility{Not: &CommandVisibilityClause{PackageJSON: map[string]string{"name": "web"}}}, false},
+		{"not allOf", CommandVisibility{Not: &CommandVisibilityClause{AllOf: []CommandVisibilityClause{
+			{FileExists: []string{"sanity.config.ts"}},
+			{FileExists: []string{"pages"}},
+		}}}, true},
+		{"allOf with nested anyOf", CommandVisibility{AllOf: []CommandVisibilityClause{
+			{Dependency: map[string]string{"next": ">=13"}, Glob: []string{"app/**"}},
+			{AnyOf: []CommandVisibilityClause{{FileExists: []string{"pages"}}, {FileExists: []string{"sanity.config.ts"}}}},
+		}}, true},
+		{"nested anyOf failing", CommandVisibility{Dependency: map[string]string{"next": "*"}, AnyOf: nil, AllOf: []CommandVisibilityClause{
+			{AnyOf: []CommandVisibilityClause{{FileExists: []string{"pages"}}, {Glob: []string{"*.vue"}}}},
+		}}, false},
+	}
+	for _, tt := range tests {
+		rule := tt.rule
+		trace := ExplainVisibility(CommandSpec{Visibility: &rule}, projectPath)
+		if trace.Visible != tt.visible {
+			t.Errorf("%s: visible = %v (%s), want %v", tt.name, trace.Visible, trace.Reason, tt.visible)
+		}
+		if !trace.Visible && len(trace.Fixes) != 1 {
+			t.Errorf("%s: fixes = %q", tt.name, trace.Fixes)
+		}
+	}
+
+	rule := CommandVisibility{AllOf: []CommandVisibilityClause{{Not: &CommandVisibilityClause{FileExists: []string{"sanity.config.ts"}}}}}
+	trace := ExplainVisibility(CommandSpec{Visibility: &rule}, projectPath)
+	want := VisibilityCheck{Group: -1, Path: "allOf[0].not", Condition: CondFileExists, Key: "sanity.config.ts", Expected: "exists", Actual: "exists", Negated: true}
+	if len(trace.Checks) != 1 || trace.Checks[0] != want {
+		t.Errorf("negated check = %+v, want %+v", trace.Checks, want)
+	}
+	if trace.Reason != "sanity.config.ts exists (must not be)" || trace.Fixes[0] != "remove sanity.config.ts" {
+		t.Errorf("negated reason %q, fixes %q", trace.Reason, trace.Fixes)
+	}
+}
+
+// TestVisibilityEcosystems tests dependency and detected conditions against go.mod and
+// pyproject.toml in a project without package.json.
+func TestVisibilityEcosystems(t *testing.T) {
+	projectPath := t.TempDir()
+	files := map[string]string{
+		"go.mod":         "module example.com/api\n\nrequire github.com/gin-gonic/gin v1.9.1\n",
+		"pyproject.toml": "[project]\nname = \"ml\"\ndependencies = [\"fastapi>=0.110\"]\n",
+	}
+	for rel, content := range files {
+		if err := os.WriteFile(filepath.Join(projectPath, rel), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name    string
+		rule    CommandVisibility
+		visible bool
+	}{
+		{"go module in range", CommandVisibility{Dependency: map[string]string{"github.com/gin-gonic/gin": "^1.9"}}, true},
+		{"go module out of range", CommandVisibility{Dependency: map[string]string{"github.com/gin-gonic/gin": ">=2"}}, false},
+		{"python package", CommandVisibility{Dependency: map[string]string{"fastapi": ">=0.100"}}, true},
+		{"detected ecosystem", CommandVisibility{Detected: []string{"go", "python"}}, true},
+		{"detected framework", CommandVisibility{Detected: []string{"gin", "fastapi"}}, true},
+		{"not detected", CommandVisibility{Detected: []string{"nextjs"}}, false},
+	}
+	for _, tt := range tests {
+		rule := tt.rule
+		trace := ExplainVisibility(CommandSpec{Visibility: &rule}, projectPath)
+		if trace.Visible != tt.visible {
+			t.Errorf("%s: visible = %v (%s), want %v", tt.name, trace.Visible, trace.Reason, tt.visible)
+		}
+	}
+}
@@ -21,8 +21,12 @@ type HistoricCommand struct {
 type ProjectInfo struct {
 	RootPath         string                       // Absolute path to project root
 	Name             string                       // Project name
-	Type             string                       // Primary detected project type (e.g., nextjs, react, vue, generic-npm, git)
+	Type             string                       // Primary detected project type (e.g., nextjs, react, vue, npm, go, django, git)
 	DetectedPackages []string                     // List of all detected packages/frameworks based on dependencies
+	Ecosystems       []string                     // Ecosystems found besides npm (go, python, rust, php, deno)
+	WorkspaceRoot    string                       // Root of the monorepo the project belongs to ("" outside one)
+	Workspaces       []string                     // Packages of the monorepo relative to its root, when the project is the root
+	PackageManager   string                       // npm, pnpm, yarn or bun, from the nearest lockfile ("" when unknown)
 	PackageInfo      map[string]string            // Selected info from package.json (name, version, description)
 	Dependencies     map[string]string            // Map of dependencies from package.json
 	DevDependencies  map[string]string            // Map of devDependencies from package.json
@@ -82,13 +86,26 @@ var knownPackages = map[string]string{
 // DetectProject examines the given directory and parents to find project markers
 // It walks up the directory tree looking for common project identifiers.
 func DetectProject(startPath string) (ProjectInfo, bool) {
+	info, ok := detectProjectRoot(startPath)
+	if ok {
+		addWorkspaces(&info)
+		info.PackageManager = DetectPackageManager(info.RootPath)
+	}
+	return info, ok
+}
+
+// detectProjectRoot finds the nearest project root at or above startPath.
+func detectProjectRoot(startPath string) (ProjectInfo, bool) {
 	// Start with the given path and walk up the directory tree
 	currentPath := startPath
 	for {
+		ecosystems := DetectEcosystems(currentPath)
+
 		// Check for package.json first (most common indicator)
 		if hasPackageJSON, pkgData := checkForPackageJSON(currentPath); hasPackageJSON {
 			info, ok := createProjectInfo(currentPath, pkgData, nil, "npm") // Initial type 'npm'
 			if ok {
+				addEcosystems(&info, ecosystems)
 				// Check for Git info in the same directory
 				if hasGit, gitData := checkForGit(currentPath); hasGit {
 					info.GitInfo = gitData // Add Git info if found
@@ -97,7 +114,22 @@ func DetectProject(startPath string) (ProjectInfo, bool) {
 			}
 		}
 
-		// Check for .git directory if no package.json was found at this level
+		// Then for other ecosystems' manifests (go.mod, pyproject.toml, Cargo.toml, ...)
+		if len(ecosystems) > 0 {
+			info, ok := createProjectInfo(currentPath, nil, nil, ecosystems[0].Type)
+			if ok {
+				if ecosystems[0].Name != "" {
+					info.Name = ecosystems[0].Name
+				}
+				addEcosystems(&info, ecosystems)
+				if hasGit, gitData := checkForGit(currentPath); hasGit {
+					info.GitInfo = gitData
+				}
+				return info, true
+			}
+		}
+
+		// Check for .git directory if no manifest was found at this level
 		if hasGit, gitData := checkForGit(currentPath); hasGit {
 			info, ok := createProjectInfo(currentPath, nil, gitData, "git") // Type 'git'
 			if ok {
@@ -105,9 +137,6 @@ func DetectProject(startPath string) (ProjectInfo, bool) {
 			}
 		}
 
-		// Add checks for other project types here (e.g., go.mod, pyproject.toml)
-		// if checkGoProject(currentPath) { ... }
-
 		// Move up one directory
 		parentPath := filepath.Dir(currentPath)
 		if parentPath == currentPath {
@@ -121,6 +150,43 @@ func DetectProject(startPath string) (ProjectInfo, bool) {
 	return ProjectInfo{}, false
 }
 
+// addEcosystems records the ecosystems found next to (or instead of) package.json. Their
+// names and frameworks join the detected packages, and a package.json without a known
+// framework gives way to the first ecosystem's type.
+func addEcosystems(info *ProjectInfo, ecosystems []Ecosystem) {
+	seen := map[string]bool{}
+	for _, pkg := range info.DetectedPackages {
+		seen[pkg] = true
+	}
+	for _, eco := range ecosystems {
+		info.Ecosystems = append(info.Ecosystems, eco.Language)
+		for _, pkg := range append([]string{eco.Language}, eco.Packages...) {
+			if !seen[pkg] {
+				seen[pkg] = true
+				info.DetectedPackages = append(info.DetectedPackages, pkg)
+			}
+		}
+	}
+	if info.Type == "npm" && len(ecosystems) > 0 {
+		info.Type = ecosystems[0].Type
+	}
+}
+
+// addWorkspaces records the monorepo the project is part of. Only the monorepo root lists
+// the workspaces; a package inside one just points at its root.
+func addWorkspaces(info *ProjectInfo) {
+	mono, ok := DetectMonorepo(info.RootPath)
+	if !ok {
+		return
+	}
+	info.WorkspaceRoot = mono.Root
+	if mono.Root == info.RootPath {
+		for _, ws := range mono.Workspaces {
+			info.Workspaces = append(info.Workspaces, ws.RelPath)
+		}
+	}
+}
+
 // checkForPackageJSON looks for package.json and extracts relevant info as a map
 func checkForPackageJSON(dir string) (bool, map[string]interface{}) {
 	pkgPath := filepath.Join(dir, "package.json")
@@ -0,0 +1,453 @@
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Ecosystem is what a detector found in a project root besides package.json.
+type Ecosystem struct {
+	Type         string            // Ecosystem or, when recognised, its main framework (e.g. go, django)
+	Language     string            // Ecosystem name (go, python, rust, php, deno)
+	Name         string            // Project name from the manifest, if any
+	Packages     []string          // Known frameworks/packages found in the dependencies
+	Dependencies map[string]string // Dependency name → version spec
+}
+
+// EcosystemDetector recognises one ecosystem by its manifest files. Detect is only called
+// when at least one of the markers exists in the directory.
+type EcosystemDetector struct {
+	Language string
+	Markers  []string // Manifest files, relative to the project root
+	Detect   func(dir string) Ecosystem
+}
+
+var ecosystemDetectors []EcosystemDetector
+
+// RegisterEcosystemDetector adds a detector. Detectors run in registration order, and the
+// first one that matches decides the project type when there is no package.json.
+func RegisterEcosystemDetector(d EcosystemDetector) {
+	ecosystemDetectors = append(ecosystemDetectors, d)
+}
+
+// EcosystemMarkers lists the manifest files of every registered detector.
+func EcosystemMarkers() []string {
+	var out []string
+	for _, d := range ecosystemDetectors {
+		out = append(out, d.Markers...)
+	}
+	return out
+}
+
+// DetectEcosystems runs every detector whose markers exist in dir (without walking up).
+func DetectEcosystems(dir string) []Ecosystem {
+	var out []Ecosystem
+	for _, d := range ecosystemDetectors {
+		for _, marker := range d.Markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				eco := d.Detect(dir)
+				eco.Language = d.Language
+				if eco.Type == "" {
+					eco.Type = d.Language
+				}
+				out = append(out, eco)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// KnownNpmPackages returns the known frameworks among package.json dependency names.
+func KnownNpmPackages(deps map[string]string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for name := range deps {
+		if id, ok := knownPackages[name]; ok && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func init() {
+	RegisterEcosystemDetector(EcosystemDetector{Language: "go", Markers: []string{"go.mod"}, Detect: detectGo})
+	RegisterEcosystemDetector(EcosystemDetector{Language: "python", Markers: []string{"pyproject.toml", "requirements.txt"}, Detect: detectPython})
+	RegisterEcosystemDetector(EcosystemDetector{Language: "rust", Markers: []string{"Cargo.toml"}, Detect: detectRust})
+	RegisterEcosystemDetector(EcosystemDetector{Language: "php", Markers: []string{"composer.json"}, Detect: detectPHP})
+	RegisterEcosystemDetector(EcosystemDetector{Language: "deno", Markers: []string{"deno.json", "deno.jsonc"}, Detect: detectDeno})
+}
+
+// Known frameworks per ecosystem, by dependency name. The first one found, in the order
+// of each primary list, becomes the project type.
+var (
+	knownGoModules = map[string]string{
+		"github.com/gin-gonic/gin":           "gin",
+		"github.com/labstack/echo/v4":        "echo",
+		"github.com/gofiber/fiber/v2":        "fiber",
+		"github.com/go-chi/chi/v5":           "chi",
+		"github.com/gorilla/mux":             "gorilla",
+		"github.com/charmbracelet/bubbletea": "bubbletea",
+		"github.com/spf13/cobra":             "cobra",
+		"gorm.io/gorm":                       "gorm",
+	}
+	primaryGo = []string{"gin", "echo", "fiber", "chi", "gorilla"}
+
+	knownPythonPackages = map[string]string{
+		"django":     "django",
+		"flask":      "flask",
+		"fastapi":    "fastapi",
+		"streamlit":  "streamlit",
+		"sqlalchemy": "sqlalchemy",
+		"pydantic":   "pydantic",
+		"pytest":     "pytest",
+	}
+	primaryPython = []string{"django", "fastapi", "flask", "streamlit"}
+
+	knownCrates = map[string]string{
+		"actix-web": "actix",
+		"axum":      "axum",
+		"rocket":    "rocket",
+		"tauri":     "tauri",
+		"bevy":      "bevy",
+		"leptos":    "leptos",
+		"tokio":     "tokio",
+	}
+	primaryRust = []string{"actix", "axum", "rocket", "tauri", "bevy", "leptos"}
+
+	knownComposerPackages = map[string]string{
+		"laravel/framework":                 "laravel",
+		"symfony/framework-bundle":          "symfony",
+		"drupal/core":                       "drupal",
+		"drupal/core-recommended":           "drupal",
+		"johnpbloch/wordpress-core":         "wordpress",
+		"roots/wordpress":                   "wordpress",
+		"magento/product-community-edition": "magento",
+	}
+	primaryPHP = []string{"laravel", "symfony", "drupal", "wordpress", "magento"}
+
+	knownDenoImports = map[string]string{
+		"fresh": "fresh",
+		"hono":  "hono",
+		"oak":   "oak",
+	}
+	primaryDeno = []string{"fresh", "hono", "oak"}
+)
+
+// recognise fills Packages from the dependencies and picks the type from the primary list.
+func (e *Ecosystem) recognise(known map[string]string, primary []string) {
+	seen := map[string]bool{}
+	for name := range e.Dependencies {
+		if id, ok := known[name]; ok && !seen[id] {
+			seen[id] = true
+			e.Packages = append(e.Packages, id)
+		}
+	}
+	sort.Strings(e.Packages)
+	for _, id := range primary {
+		if seen[id] {
+			e.Type = id
+			return
+		}
+	}
+}
+
+// ---- Go ----
+
+func detectGo(dir string) Ecosystem {
+	eco := Ecosystem{Dependencies: map[string]string{}}
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return eco
+	}
+	defer f.Close()
+	inRequire := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case strings.HasPrefix(line, "module "):
+			eco.Name = filepath.Base(strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`))
+		case line == "require (":
+			inRequire = true
+		case inRequire && line == ")":
+			inRequire = false
+		case inRequire || strings.HasPrefix(line, "require "):
+			fields := strings.Fields(strings.TrimPrefix(line, "require "))
+			if len(fields) >= 2 {
+				eco.Dependencies[fields[0]] = fields[1]
+			}
+		}
+	}
+	eco.recognise(knownGoModules, primaryGo)
+	return eco
+}
+
+// ---- Python ----
+
+// pythonRequirement matches the name and version spec of a PEP 508 requirement.
+var pythonRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*([^;#]*)`)
+
+func addPythonRequirement(deps map[string]string, req string) {
+	m := pythonRequirement.FindStringSubmatch(strings.TrimSpace(req))
+	if m == nil {
+		return
+	}
+	// "Django>=4.2,<5" is stored as ">=4.2 <5"
+	deps[strings.ToLower(strings.ReplaceAll(m[1], "_", "-"))] = strings.TrimSpace(strings.ReplaceAll(m[2], ",", " "))
+}
+
+func detectPython(dir string) Ecosystem {
+	eco := Ecosystem{Dependencies: map[string]string{}}
+	if data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml")); err == nil {
+		doc := parseTOML(string(data))
+		eco.Name = doc.str("project", "name")
+		if eco.Name == "" {
+			eco.Name = doc.str("tool.poetry", "name")
+		}
+		for _, req := range doc.list("project", "dependencies") {
+			addPythonRequirement(eco.Dependencies, req)
+		}
+		for _, table := range []string{"tool.poetry.dependencies", "tool.poetry.group.dev.dependencies", "tool.poetry.dev-dependencies"} {
+			for name, spec := range doc.tables[table] {
+				if name != "python" {
+					eco.Dependencies[strings.ToLower(name)] = tomlVersion(spec)
+				}
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "requirements.txt")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "-") {
+				addPythonRequirement(eco.Dependencies, line)
+			}
+		}
+	}
+	eco.recognise(knownPythonPackages, primaryPython)
+	return eco
+}
+
+// ---- Rust ----
+
+func detectRust(dir string) Ecosystem {
+	eco := Ecosystem{Dependencies: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return eco
+	}
+	doc := parseTOML(string(data))
+	eco.Name = doc.str("package", "name")
+	for _, table := range []string{"dependencies", "dev-dependencies", "build-dependencies", "workspace.dependencies"} {
+		for name, spec := range doc.tables[table] {
+			eco.Dependencies[name] = tomlVersion(spec)
+		}
+	}
+	eco.recognise(knownCrates, primaryRust)
+	return eco
+}
+
+// ---- PHP ----
+
+func detectPHP(dir string) Ecosystem {
+	eco := Ecosystem{Dependencies: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(dir, "composer.json"))
+	if err != nil {
+		return eco
+	}
+	var composer struct {
+		Name       string            `json:"name"`
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if json.Unmarshal(data, &composer) != nil {
+		return eco
+	}
+	if composer.Name != "" {
+		eco.Name = composer.Name
+	}
+	for _, deps := range []map[string]string{composer.RequireDev, composer.Require} {
+		for name, spec := range deps {
+			eco.Dependencies[name] = spec
+		}
+	}
+	eco.recognise(knownComposerPackages, primaryPHP)
+	return eco
+}
+
+// ---- Deno ----
+
+// denoSpecifier splits an import specifier such as "jsr:@hono/hono@^4.0.0" or
+// "https://deno.land/x/oak@v12.6.1/mod.ts" into a package name and version.
+var denoSpecifier = regexp.MustCompile(`(?:jsr:|npm:|https://deno\.land/x/)(@?[^@/]+(?:/[^@/]+)?)@?([^/]*)`)
+
+func detectDeno(dir string) Ecosystem {
+	eco := Ecosystem{Dependencies: map[string]string{}}
+	data, err := os.ReadFile(filepath.Join(dir, "deno.json"))
+	if err != nil {
+		data, err = os.ReadFile(filepath.Join(dir, "deno.jsonc"))
+		if err != nil {
+			return eco
+		}
+		data = stripJSONComments(data)
+	}
+	var deno struct {
+		Name    string            `json:"name"`
+		Imports map[string]string `json:"imports"`
+	}
+	if json.Unmarshal(data, &deno) != nil {
+		return eco
+	}
+	eco.Name = deno.Name
+	known := map[string]string{}
+	for alias, specifier := range deno.Imports {
+		name, version := strings.TrimSuffix(alias, "/"), ""
+		if m := denoSpecifier.FindStringSubmatch(specifier); m != nil {
+			name, version = m[1], m[2]
+		}
+		eco.Dependencies[name] = version
+		// Match frameworks by the last part of the name (@hono/hono, $fresh/, @fresh/core)
+		for _, part := range strings.Split(strings.Trim(name+"/"+alias, "$@/"), "/") {
+			if id, ok := knownDenoImports[strings.Trim(part, "$@")]; ok {
+				known[name] = id
+			}
+		}
+	}
+	eco.recognise(known, primaryDeno)
+	return eco
+}
+
+// stripJSONComments removes // line comments from JSONC outside of strings.
+func stripJSONComments(data []byte) []byte {
+	var out []byte
+	inString, escaped := false, false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			escaped = c == '\\' && !escaped
+			if c == '"' && !escaped {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// ---- Minimal TOML ----
+
+// tomlDoc is the subset of TOML that manifests use for names and dependencies: tables of
+// key = value lines, with strings, inline tables and (multi-line) string arrays as values.
+type tomlDoc struct {
+	tables map[string]map[string]string // table → key → raw value
+}
+
+func parseTOML(s string) tomlDoc {
+	doc := tomlDoc{tables: map[string]map[string]string{}}
+	table := ""
+	doc.tables[table] = map[string]string{}
+	lines := strings.Split(s, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(stripTOMLComment(lines[i]))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.TrimSpace(strings.Trim(line, "[]"))
+			if doc.tables[table] == nil {
+				doc.tables[table] = map[string]string{}
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		// Arrays may span lines until the closing bracket
+		for strings.HasPrefix(value, "[") && strings.Count(value, "[") > strings.Count(value, "]") && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripTOMLComment(lines[i]))
+		}
+		doc.tables[table][strings.Trim(strings.TrimSpace(key), `"'`)] = value
+	}
+	return doc
+}
+
+func stripTOMLComment(line string) string {
+	inString := rune(0)
+	for i, c := range line {
+		switch {
+		case inString != 0 && c == inString:
+			inString = 0
+		case inString == 0 && (c == '"' || c == '\''):
+			inString = c
+		case inString == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// str returns a string value.
+func (d tomlDoc) str(table, key string) string {
+	return tomlString(d.tables[table][key])
+}
+
+// list returns the strings of an array value.
+func (d tomlDoc) list(table, key string) []string {
+	raw := strings.TrimSpace(d.tables[table][key])
+	if !strings.HasPrefix(raw, "[") {
+		return nil
+	}
+	var out []string
+	for _, item := range tomlStrings.FindAllString(raw, -1) {
+		out = append(out, tomlString(item))
+	}
+	return out
+}
+
+var tomlStrings = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+
+func tomlString(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1]
+	}
+	return raw
+}
+
+// tomlVersion returns the version of a dependency given as "1.0" or { version = "1.0", ... }.
+func tomlVersion(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "{") {
+		for _, field := range strings.Split(strings.Trim(raw, "{}"), ",") {
+			if k, v, ok := strings.Cut(field, "="); ok && strings.TrimSpace(k) == "version" {
+				return tomlString(v)
+			}
+		}
+		return ""
+	}
+	return tomlString(raw)
+}
@@ -0,0 +1,110 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestDetectEcosystems tests each detector's type, name, packages and dependencies.
+func TestDetectEcosystems(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     Ecosystem
+		depName  string
+		depValue string
+	}{
+		{
+			"go",
+			map[string]string{"go.mod": "module github.com/acme/api\n\ngo 1.22\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgorm.io/gorm v1.25.0 // indirect\n)\nrequire github.com/spf13/cobra v1.8.0\n"},
+			Ecosystem{Type: "gin", Language: "go", Name: "api", Packages: []string{"cobra", "gin", "gorm"}},
+			"gorm.io/gorm", "v1.25.0",
+		},
+		{
+			"pyproject",
+			map[string]string{"pyproject.toml": "[project]\nname = \"shop\" # the shop\ndependencies = [\n  \"Django>=4.2,<5\",\n  \"pydantic[email]~=2.0\",\n]\n"},
+			Ecosystem{Type: "django", Language: "python", Name: "shop", Packages: []string{"django", "pydantic"}},
+			"django", ">=4.2 <5",
+		},
+		{
+			"poetry and requirements",
+			map[string]string{
+				"pyproject.toml":   "[tool.poetry]\nname = \"svc\"\n\n[tool.poetry.dependencies]\npython = \"^3.11\"\nfastapi = { version = \"^0.110\", extras = [\"all\"] }\n",
+				"requirements.txt": "# pinned\nflask==3.0.0\n-r dev.txt\n",
+			},
+			Ecosystem{Type: "fastapi", Language: "python", Name: "svc", Packages: []string{"fastapi", "flask"}},
+			"fastapi", "^0.110",
+		},
+		{
+			"rust",
+			map[string]string{"Cargo.toml": "[package]\nname = \"server\"\n\n[dependencies]\naxum = \"0.7\"\ntokio = { version = \"1\", features = [\"full\"] }\n"},
+			Ecosystem{Type: "axum", Language: "rust", Name: "server", Packages: []string{"axum", "tokio"}},
+			"tokio", "1",
+		},
+		{
+			"php",
+			map[string]string{"composer.json": `{"name": "acme/site", "require": {"php": "^8.2", "laravel/framework": "^11.0"}}`},
+			Ecosystem{Type: "laravel", Language: "php", Name: "acme/site", Packages: []string{"laravel"}},
+			"laravel/framework", "^11.0",
+		},
+		{
+			"deno",
+			map[string]string{"deno.jsonc": "{\n  // app\n  \"name\": \"@acme/edge\",\n  \"imports\": {\"hono\": \"jsr:@hono/hono@^4.4.0\", \"url\": \"https://example.com//x\"}\n}"},
+			Ecosystem{Type: "hono", Language: "deno", Name: "@acme/edge", Packages: []string{"hono"}},
+			"@hono/hono", "^4.4.0",
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, tt.files)
+		got := DetectEcosystems(dir)
+		if len(got) != 1 {
+			t.Errorf("%s: %d ecosystems, want 1: %+v", tt.name, len(got), got)
+			continue
+		}
+		eco := got[0]
+		if dep := eco.Dependencies[tt.depName]; dep != tt.depValue {
+			t.Errorf("%s: dependency %q = %q, want %q (all: %v)", tt.name, tt.depName, dep, tt.depValue, eco.Dependencies)
+		}
+		eco.Dependencies = nil
+		if !reflect.DeepEqual(eco, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, eco, tt.want)
+		}
+	}
+}
+
+// TestDetectProjectEcosystems tests that manifests other than package.json mark a project
+// root, and that their ecosystems join npm detection in polyglot repos.
+func TestDetectProjectEcosystems(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/tool\n", "cmd/tool/main.go": "package main"})
+	info, ok := DetectProject(filepath.Join(dir, "cmd", "tool"))
+	if !ok || info.Type != "go" || info.Name != "tool" || !reflect.DeepEqual(info.DetectedPackages, []string{"go"}) {
+		t.Errorf("go project: %v %+v", ok, info)
+	}
+
+	dir = t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": `{"name": "web", "devDependencies": {"prettier": "^3"}}`,
+		"Cargo.toml":   "[package]\nname = \"core\"\n[dependencies]\ntauri = \"2\"\n",
+	})
+	info, _ = DetectProject(dir)
+	if info.Type != "tauri" || info.Name != "web" || !reflect.DeepEqual(info.Ecosystems, []string{"rust"}) || !reflect.DeepEqual(info.DetectedPackages, []string{"rust", "tauri"}) {
+		t.Errorf("polyglot project: %+v", info)
+	}
+}
@@ -0,0 +1,101 @@
+package project
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// [PACKAGE MANAGER] npm, pnpm, yarn or bun, from lockfiles
+// -----------------------------------------------------------------------------
+
+// Package managers DetectPackageManager can report.
+const (
+	PackageManagerNpm  = "npm"
+	PackageManagerPnpm = "pnpm"
+	PackageManagerYarn = "yarn"
+	PackageManagerBun  = "bun"
+)
+
+// lockfiles map each package manager's lockfile to it, in the order they are checked.
+var lockfiles = []struct {
+	file    string
+	manager string
+}{
+	{"pnpm-lock.yaml", PackageManagerPnpm},
+	{"bun.lock", PackageManagerBun},
+	{"bun.lockb", PackageManagerBun},
+	{"yarn.lock", PackageManagerYarn},
+	{"package-lock.json", PackageManagerNpm},
+	{"npm-shrinkwrap.json", PackageManagerNpm},
+}
+
+// DetectPackageManager returns the package manager of the project at dir, from the
+// nearest lockfile at or above it (a workspace's lockfile sits at the monorepo root),
+// else from the "packageManager" field of package.json (e.g. "pnpm@9.1.0"). The search
+// stops at the repository root. It returns "" when neither names one.
+func DetectPackageManager(dir string) string {
+	var dirs []string
+	for current := dir; ; {
+		dirs = append(dirs, current)
+		if fileExists(filepath.Join(current, ".git")) {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	for _, d := range dirs {
+		for _, lock := range lockfiles {
+			if fileExists(filepath.Join(d, lock.file)) {
+				return lock.manager
+			}
+		}
+	}
+	for _, d := range dirs {
+		data, err := os.ReadFile(filepath.Join(d, "package.json"))
+		if err != nil {
+			continue
+		}
+		var pkg struct {
+			PackageManager string `json:"packageManager"`
+		}
+		if json.Unmarshal(data, &pkg) != nil || pkg.PackageManager == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(pkg.PackageManager, "@")
+		switch name {
+		case PackageManagerNpm, PackageManagerPnpm, PackageManagerYarn, PackageManagerBun:
+			return name
+		}
+	}
+	return ""
+}
+
+// InstallArgs returns the command line that adds packages (e.g. "react@^19") with the
+// package manager, as dev dependencies when dev is set. An unknown manager uses npm.
+func InstallArgs(manager string, packages []string, dev bool) []string {
+	var args []string
+	switch manager {
+	case PackageManagerPnpm:
+		args = []string{"pnpm", "add"}
+		if dev {
+			args = append(args, "-D")
+		}
+	case PackageManagerYarn, PackageManagerBun:
+		args = []string{manager, "add"}
+		if dev {
+			args = append(args, "--dev")
+		}
+	default:
+		args = []string{"npm", "install"}
+		if dev {
+			args = append(args, "--save-dev")
+		}
+	}
+	return append(args, packages...)
+}
@@ -0,0 +1,61 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestDetectPackageManager tests lockfile precedence, a workspace using the lockfile at the
+// monorepo root, and the package.json "packageManager" fallback.
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		dir   string
+		want  string
+	}{
+		{"pnpm", map[string]string{"pnpm-lock.yaml": "", "package-lock.json": "{}"}, "", PackageManagerPnpm},
+		{"bun", map[string]string{"bun.lockb": ""}, "", PackageManagerBun},
+		{"yarn", map[string]string{"yarn.lock": "", "package-lock.json": "{}"}, "", PackageManagerYarn},
+		{"npm", map[string]string{"package-lock.json": "{}"}, "", PackageManagerNpm},
+		{"workspace", map[string]string{"pnpm-lock.yaml": "", "apps/web/package.json": "{}"}, "apps/web", PackageManagerPnpm},
+		{"packageManager field", map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`}, "", PackageManagerYarn},
+		{"none", map[string]string{"package.json": `{"name": "app"}`}, "", ""},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, map[string]string{".git/HEAD": ""})
+		writeFiles(t, dir, tt.files)
+		if got := DetectPackageManager(filepath.Join(dir, tt.dir)); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// The search stops at the repository root
+	outer := t.TempDir()
+	writeFiles(t, outer, map[string]string{"yarn.lock": "", "repo/.git/HEAD": "", "repo/package.json": "{}"})
+	if got := DetectPackageManager(filepath.Join(outer, "repo")); got != "" {
+		t.Errorf("lockfile above the repository root used: %q", got)
+	}
+}
+
+// TestInstallArgs tests the add command of each package manager.
+func TestInstallArgs(t *testing.T) {
+	tests := []struct {
+		manager string
+		dev     bool
+		want    []string
+	}{
+		{PackageManagerPnpm, true, []string{"pnpm", "add", "-D", "a@^1"}},
+		{PackageManagerYarn, false, []string{"yarn", "add", "a@^1"}},
+		{PackageManagerBun, true, []string{"bun", "add", "--dev", "a@^1"}},
+		{PackageManagerNpm, true, []string{"npm", "install", "--save-dev", "a@^1"}},
+		{"", false, []string{"npm", "install", "a@^1"}},
+	}
+	for _, tt := range tests {
+		if got := InstallArgs(tt.manager, []string{"a@^1"}, tt.dev); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("InstallArgs(%q, %v) = %v, want %v", tt.manager, tt.dev, got, tt.want)
+		}
+	}
+}
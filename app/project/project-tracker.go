@@ -50,15 +50,24 @@ const registryFileName = "projects.json"
 // getRegistryPath determines the appropriate path for the registry file.
 // It typically resides in a hidden config directory within the user's home directory.
 func getRegistryPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
+	registryPath, err := RegistryFilePath()
 	if err != nil {
-		return "", fmt.Errorf("could not get user home directory: %w", err)
+		return "", err
 	}
-	configDir := filepath.Join(homeDir, ".config", "nextgen-cli") // Using .config standard
-	if err := os.MkdirAll(configDir, 0750); err != nil {          // Use 0750 for permissions
+	configDir := filepath.Dir(registryPath)
+	if err := os.MkdirAll(configDir, 0750); err != nil { // Use 0750 for permissions
 		return "", fmt.Errorf("could not create config directory %s: %w", configDir, err)
 	}
-	return filepath.Join(configDir, registryFileName), nil
+	return registryPath, nil
+}
+
+// RegistryFilePath returns where the registry file is stored, without creating anything.
+func RegistryFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", "nextgen-cli", registryFileName), nil // Using .config standard
 }
 
 // LoadProjectRegistry loads the project registry from disk.
@@ -178,6 +187,10 @@ func (r *ProjectRegistry) AddOrUpdateProject(info ProjectInfo) {
 		existingInfo.Dependencies = info.Dependencies
 		existingInfo.DevDependencies = info.DevDependencies
 		existingInfo.DetectedPackages = info.DetectedPackages
+		existingInfo.Ecosystems = info.Ecosystems
+		existingInfo.WorkspaceRoot = info.WorkspaceRoot
+		existingInfo.Workspaces = info.Workspaces
+		existingInfo.PackageManager = info.PackageManager
 		existingInfo.GitInfo = info.GitInfo
 		// --- DO NOT update CommandHistory here ---
 		// CommandHistory should only be updated by RunCommand after execution.
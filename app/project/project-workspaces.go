@@ -0,0 +1,319 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// -----------------------------------------------------------------------------
+// [WORKSPACES] Monorepo detection (pnpm, npm/yarn/bun workspaces, turbo, nx)
+// -----------------------------------------------------------------------------
+
+// Workspace is one package of a monorepo.
+type Workspace struct {
+	Name    string // Package name from package.json (or project.json), else the directory name
+	Path    string // Absolute path to the package directory
+	RelPath string // Slash-separated path relative to the monorepo root (e.g. "apps/web")
+}
+
+// Monorepo describes a repository whose packages are declared as workspaces.
+type Monorepo struct {
+	Root       string      // Absolute path to the monorepo root
+	Markers    []string    // Files that declared it, e.g. pnpm-workspace.yaml, turbo.json
+	Patterns   []string    // Workspace globs, "!" patterns exclude
+	Workspaces []Workspace // Packages matched by Patterns, sorted by RelPath
+}
+
+// MonorepoMarkers are the files that can make a directory a monorepo root. A package.json
+// only counts when it has a "workspaces" field.
+var MonorepoMarkers = []string{"pnpm-workspace.yaml", "package.json", "turbo.json", "nx.json"}
+
+// Default package directories when turbo.json or nx.json is the only marker.
+var (
+	turboDefaultPatterns = []string{"apps/*", "packages/*"}
+	nxDefaultAppsDir     = "apps"
+	nxDefaultLibsDir     = "libs"
+)
+
+// DetectMonorepo walks up from startPath to the nearest monorepo root and lists its
+// workspaces.
+func DetectMonorepo(startPath string) (Monorepo, bool) {
+	current, err := filepath.Abs(startPath)
+	if err != nil {
+		return Monorepo{}, false
+	}
+	for {
+		if mono, ok := monorepoAt(current); ok {
+			return mono, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return Monorepo{}, false
+		}
+		current = parent
+	}
+}
+
+// monorepoAt reads the workspace declarations in dir. pnpm-workspace.yaml and package.json
+// "workspaces" list their globs; turbo.json and nx.json fall back to their conventional
+// package directories when neither does.
+func monorepoAt(dir string) (Monorepo, bool) {
+	mono := Monorepo{Root: dir}
+	if data, err := os.ReadFile(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		var doc struct {
+			Packages []string `yaml:"packages"`
+		}
+		yaml.Unmarshal(data, &doc)
+		mono.Markers = append(mono.Markers, "pnpm-workspace.yaml")
+		mono.Patterns = append(mono.Patterns, doc.Packages...)
+	}
+	if patterns, ok := packageJSONWorkspaces(dir); ok {
+		mono.Markers = append(mono.Markers, "package.json")
+		if len(mono.Patterns) == 0 {
+			mono.Patterns = patterns
+		}
+	}
+	if fileExists(filepath.Join(dir, "turbo.json")) {
+		mono.Markers = append(mono.Markers, "turbo.json")
+		if len(mono.Patterns) == 0 {
+			mono.Patterns = turboDefaultPatterns
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "nx.json")); err == nil {
+		var doc struct {
+			WorkspaceLayout struct {
+				AppsDir string `json:"appsDir"`
+				LibsDir string `json:"libsDir"`
+			} `json:"workspaceLayout"`
+		}
+		json.Unmarshal(data, &doc)
+		mono.Markers = append(mono.Markers, "nx.json")
+		if len(mono.Patterns) == 0 {
+			apps, libs := doc.WorkspaceLayout.AppsDir, doc.WorkspaceLayout.LibsDir
+			if apps == "" {
+				apps = nxDefaultAppsDir
+			}
+			if libs == "" {
+				libs = nxDefaultLibsDir
+			}
+			mono.Patterns = []string{apps + "/*", libs + "/*"}
+		}
+	}
+	if len(mono.Markers) == 0 {
+		return Monorepo{}, false
+	}
+	mono.Workspaces = expandWorkspaces(dir, mono.Patterns)
+	return mono, true
+}
+
+// packageJSONWorkspaces returns the "workspaces" globs of dir's package.json, given either
+// as an array (npm, bun) or as {"packages": [...]} (yarn).
+func packageJSONWorkspaces(dir string) ([]string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, false
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || len(pkg.Workspaces) == 0 {
+		return nil, false
+	}
+	var patterns []string
+	if json.Unmarshal(pkg.Workspaces, &patterns) == nil {
+		return patterns, true
+	}
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if json.Unmarshal(pkg.Workspaces, &object) == nil {
+		return object.Packages, true
+	}
+	return nil, false
+}
+
+// workspaceManifests mark a directory matched by a workspace glob as a package.
+var workspaceManifests = []string{"package.json", "project.json"}
+
+// expandWorkspaces returns the package directories under root matched by patterns,
+// leaving out those matched by "!" patterns.
+func expandWorkspaces(root string, patterns []string) []Workspace {
+	var include, exclude []string
+	for _, p := range patterns {
+		p = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(p)), "./"), "/")
+		if strings.HasPrefix(p, "!") {
+			exclude = append(exclude, strings.TrimPrefix(strings.TrimPrefix(p, "!"), "./"))
+		} else if p != "" {
+			include = append(include, p)
+		}
+	}
+	seen := map[string]bool{}
+	var out []Workspace
+	for _, pattern := range include {
+		for _, rel := range matchDirs(root, pattern) {
+			if seen[rel] || slices.ContainsFunc(exclude, func(ex string) bool { return MatchGlob(ex, rel) }) {
+				continue
+			}
+			seen[rel] = true
+			dir := filepath.Join(root, filepath.FromSlash(rel))
+			if !slices.ContainsFunc(workspaceManifests, func(m string) bool { return fileExists(filepath.Join(dir, m)) }) {
+				continue
+			}
+			out = append(out, Workspace{Name: workspaceName(dir), Path: dir, RelPath: rel})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].RelPath < out[j].RelPath })
+	return out
+}
+
+// matchDirs returns the directories under root (slash-separated, relative to it) matched
+// by pattern. node_modules and dot directories are never searched.
+func matchDirs(root, pattern string) []string {
+	segments := strings.Split(pattern, "/")
+	base := 0
+	for base < len(segments) && !strings.ContainsAny(segments[base], "*?[") {
+		base++
+	}
+	if base == len(segments) {
+		if info, err := os.Stat(filepath.Join(root, filepath.FromSlash(pattern))); err == nil && info.IsDir() {
+			return []string{pattern}
+		}
+		return nil
+	}
+	start := filepath.Join(root, filepath.FromSlash(strings.Join(segments[:base], "/")))
+	deep := slices.Contains(segments, "**")
+	var out []string
+	filepath.WalkDir(start, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != start && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		rel, relErr := filepath.Rel(root, p)
+		if relErr != nil || p == root {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if MatchGlob(pattern, rel) {
+			out = append(out, rel)
+		}
+		if !deep && strings.Count(rel, "/")+1 >= len(segments) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return out
+}
+
+// workspaceName returns the package name declared in dir, or the directory name.
+func workspaceName(dir string) string {
+	for _, manifest := range workspaceManifests {
+		data, err := os.ReadFile(filepath.Join(dir, manifest))
+		if err != nil {
+			continue
+		}
+		var pkg struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" {
+			return pkg.Name
+		}
+	}
+	return filepath.Base(dir)
+}
+
+func fileExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+// Find returns the workspace with the given package name, path relative to the root, or
+// directory name (when only one workspace has it).
+func (m Monorepo) Find(name string) (Workspace, bool) {
+	name = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(name), "./"), "/")
+	for _, ws := range m.Workspaces {
+		if ws.Name == name || ws.RelPath == name {
+			return ws, true
+		}
+	}
+	var found []Workspace
+	for _, ws := range m.Workspaces {
+		if path.Base(ws.RelPath) == name {
+			found = append(found, ws)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], true
+	}
+	return Workspace{}, false
+}
+
+// Containing returns the workspace that dir is in, if any.
+func (m Monorepo) Containing(dir string) (Workspace, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return Workspace{}, false
+	}
+	for _, ws := range m.Workspaces {
+		if abs == ws.Path || strings.HasPrefix(abs, ws.Path+string(filepath.Separator)) {
+			return ws, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// ResolveWorkspace finds the workspace named name in the monorepo containing startPath.
+func ResolveWorkspace(startPath, name string) (Workspace, error) {
+	mono, ok := DetectMonorepo(startPath)
+	if !ok {
+		return Workspace{}, fmt.Errorf("workspace %q: %s is not in a monorepo (no %s found)", name, startPath, strings.Join(MonorepoMarkers, ", "))
+	}
+	if ws, ok := mono.Find(name); ok {
+		return ws, nil
+	}
+	var names []string
+	for _, ws := range mono.Workspaces {
+		names = append(names, ws.Name)
+	}
+	if len(names) == 0 {
+		return Workspace{}, fmt.Errorf("workspace %q: the monorepo at %s has no packages", name, mono.Root)
+	}
+	return Workspace{}, fmt.Errorf("workspace %q not found; available: %s", name, strings.Join(names, ", "))
+}
+
+// MatchGlob reports whether the slash-separated name matches pattern, where "**" matches
+// zero or more path segments and other segments use path.Match.
+func MatchGlob(pattern, name string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchGlobSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], name[0]); !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], name[1:])
+}
@@ -0,0 +1,115 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func workspacePaths(mono Monorepo) []string {
+	var out []string
+	for _, ws := range mono.Workspaces {
+		out = append(out, ws.RelPath)
+	}
+	return out
+}
+
+// TestDetectMonorepo tests each kind of workspace declaration, found from inside a package.
+func TestDetectMonorepo(t *testing.T) {
+	packages := map[string]string{
+		"apps/web/package.json":        `{"name": "@acme/web"}`,
+		"apps/studio/package.json":     `{"name": "studio"}`,
+		"apps/notes/README.md":         "not a package",
+		"packages/ui/package.json":     `{"name": "@acme/ui"}`,
+		"packages/legacy/package.json": `{"name": "legacy"}`,
+		"libs/auth/project.json":       `{"name": "auth"}`,
+	}
+	tests := []struct {
+		name    string
+		files   map[string]string
+		markers []string
+		want    []string
+	}{
+		{
+			"pnpm",
+			map[string]string{"pnpm-workspace.yaml": "packages:\n  - 'apps/*'\n  - 'packages/**'\n  - '!packages/legacy'\n", "turbo.json": "{}"},
+			[]string{"pnpm-workspace.yaml", "turbo.json"},
+			[]string{"apps/studio", "apps/web", "packages/ui"},
+		},
+		{
+			"npm workspaces",
+			map[string]string{"package.json": `{"name": "root", "workspaces": ["apps/*"]}`},
+			[]string{"package.json"},
+			[]string{"apps/studio", "apps/web"},
+		},
+		{
+			"yarn workspaces",
+			map[string]string{"package.json": `{"workspaces": {"packages": ["packages/ui"]}}`},
+			[]string{"package.json"},
+			[]string{"packages/ui"},
+		},
+		{
+			"turbo defaults",
+			map[string]string{"package.json": `{"name": "root"}`, "turbo.json": "{}"},
+			[]string{"turbo.json"},
+			[]string{"apps/studio", "apps/web", "packages/legacy", "packages/ui"},
+		},
+		{
+			"nx layout",
+			map[string]string{"nx.json": `{"workspaceLayout": {"libsDir": "libs"}}`},
+			[]string{"nx.json"},
+			[]string{"apps/studio", "apps/web", "libs/auth"},
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, packages)
+		writeFiles(t, dir, tt.files)
+		mono, ok := DetectMonorepo(filepath.Join(dir, "apps", "web"))
+		if !ok || mono.Root != dir {
+			t.Errorf("%s: got %v %+v, want root %s", tt.name, ok, mono, dir)
+			continue
+		}
+		if !reflect.DeepEqual(mono.Markers, tt.markers) || !reflect.DeepEqual(workspacePaths(mono), tt.want) {
+			t.Errorf("%s: markers %v, workspaces %v; want %v, %v", tt.name, mono.Markers, workspacePaths(mono), tt.markers, tt.want)
+		}
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"package.json": `{"name": "single"}`})
+	if mono, ok := DetectMonorepo(dir); ok {
+		t.Errorf("single package detected as monorepo: %+v", mono)
+	}
+}
+
+// TestResolveWorkspace tests finding a package by name, path and directory name, and that
+// a package inside a monorepo is detected as its own project.
+func TestResolveWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pnpm-workspace.yaml":       "packages: ['apps/*', 'packages/*']\n",
+		"package.json":              `{"name": "root", "devDependencies": {"turbo": "^2"}}`,
+		"apps/web/package.json":     `{"name": "@acme/web", "dependencies": {"next": "^15"}}`,
+		"apps/studio/package.json":  `{"name": "@acme/studio", "dependencies": {"@sanity/cli": "^3"}}`,
+		"packages/web/package.json": `{"name": "@acme/web-utils"}`,
+	})
+	for name, want := range map[string]string{"@acme/web": "apps/web", "apps/web": "apps/web", "./apps/web/": "apps/web", "studio": "apps/studio"} {
+		ws, err := ResolveWorkspace(dir, name)
+		if err != nil || ws.RelPath != want {
+			t.Errorf("ResolveWorkspace(%q) = %+v, %v; want %s", name, ws, err, want)
+		}
+	}
+	// "web" names two directories
+	if ws, err := ResolveWorkspace(dir, "web"); err == nil {
+		t.Errorf("ambiguous name resolved to %+v", ws)
+	}
+
+	info, _ := DetectProject(filepath.Join(dir, "apps", "studio", "schemas"))
+	if info.Type != "sanity" || info.WorkspaceRoot != dir || info.Workspaces != nil {
+		t.Errorf("workspace project: %+v", info)
+	}
+	info, _ = DetectProject(dir)
+	if info.WorkspaceRoot != dir || !reflect.DeepEqual(info.Workspaces, []string{"apps/studio", "apps/web", "packages/web"}) {
+		t.Errorf("monorepo root: %+v", info)
+	}
+}
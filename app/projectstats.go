@@ -46,6 +46,7 @@ func SummarizeProjectStats(recognizedPkgs []string) string {
 
 // GroupRecognizedPackages processes a list of package names, grouping React-based frameworks
 // and CSS frameworks. For example:
+//   - Languages other than JavaScript ("go", "python", ...) come first.
 //   - If "Next.js" (or Gatsby, etc.) is detected, only that candidate is kept (with a preference for Next.js).
 //   - If multiple CSS frameworks are detected, they are summarized as "N CSS Packages".
 func GroupRecognizedPackages(pkgs []string) []string {
@@ -80,7 +81,17 @@ func GroupRecognizedPackages(pkgs []string) []string {
 		"sanity":    true,
 	}
 
+	// Ecosystems besides npm, as detected from go.mod, pyproject.toml, Cargo.toml, etc.
+	languages := map[string]bool{
+		"go":     true,
+		"python": true,
+		"rust":   true,
+		"php":    true,
+		"deno":   true,
+	}
+
 	var otherPkgs []string
+	var languagePkgs []string
 	var reactCandidate string
 	cssCount := 0
 	var cssCandidate string
@@ -93,6 +104,13 @@ func GroupRecognizedPackages(pkgs []string) []string {
 	for _, pkg := range pkgs {
 		norm := normalizePkgName(pkg)
 		// Check VIP groups first.
+		if languages[norm] {
+			if !seen[norm] {
+				languagePkgs = append(languagePkgs, pkg)
+				seen[norm] = true
+			}
+			continue
+		}
 		if reactFrameworks[norm] {
 			if reactCandidate == "" {
 				reactCandidate = pkg
@@ -129,8 +147,8 @@ func GroupRecognizedPackages(pkgs []string) []string {
 		}
 	}
 
-	// Build VIP packages list.
-	var vip []string
+	// Build VIP packages list, languages first.
+	vip := languagePkgs
 	if reactCandidate != "" {
 		vip = append(vip, reactCandidate)
 	}
@@ -14,13 +14,15 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Helper to get sorted native command names FROM BUILT-IN LIST
-func getSortedNativeCommandNames() []string {
-	// Use the original logic based on commands.Commands
-	names := make([]string, 0, len(commands.Commands))
-	for _, cmdSpec := range commands.Commands {
-		// Optionally filter out commands without templates?
-		// For now, list all defined commands
+// getSortedNativeCommandNames lists the built-in commands shown in the project, or every
+// built-in command when hidden ones are toggled on.
+func getSortedNativeCommandNames(m app.Model) []string {
+	specs := commands.RegisteredCommands()
+	names := make([]string, 0, len(specs))
+	for _, cmdSpec := range specs {
+		if !m.NativeShowHidden && !commands.IsCommandVisible(cmdSpec, m.ProjectPath) {
+			continue
+		}
 		names = append(names, cmdSpec.Name)
 	}
 	sort.Strings(names)
@@ -30,7 +32,7 @@ func getSortedNativeCommandNames() []string {
 // updateNativeListPreview generates the file tree preview for the selected native command.
 func updateNativeListPreview(m app.Model) app.Model {
 	m.NativeListPreview = "Loading preview..."
-	nativeCmdNames := getSortedNativeCommandNames()
+	nativeCmdNames := getSortedNativeCommandNames(m)
 	totalCmds := len(nativeCmdNames)
 	p := m.NativePaginator // Use the correct paginator
 	start, _ := p.GetSliceBounds(totalCmds)
@@ -58,18 +60,26 @@ func updateNativeListPreview(m app.Model) app.Model {
 	pv, err := commands.GeneratePreviewFileTree(cmdName, placeholderMap, m.ProjectPath)
 	if err == nil && strings.TrimSpace(pv) != "" {
 		m.NativeListPreview = pv
+		if spec := commands.GetCommandSpec(cmdName); spec.Overridden() {
+			note := fmt.Sprintf("Overridden by .nextgen/%s\nCompare: ng template diff-builtin %s", spec.TemplatePath, spec.Slug)
+			m.NativeListPreview = app.HelpStyle.Render(note) + "\n\n" + pv
+		}
 	} else {
 		m.NativeListPreview = fmt.Sprintf("(Preview generation failed for %s)", cmdName)
 		if err != nil {
 			m.NativeListPreview += fmt.Sprintf("\nError: %v", err)
 		}
 	}
+	if trace := commands.ExplainVisibility(commands.GetCommandSpec(cmdName), m.ProjectPath); !trace.Visible {
+		note := fmt.Sprintf("Hidden in this project: %s", trace.Reason)
+		m.NativeListPreview = app.HelpStyle.Render(note) + "\n\n" + m.NativeListPreview
+	}
 	return m
 }
 
 // UpdateScreenNativeList handles navigation for the list of native commands.
 func UpdateScreenNativeList(m app.Model, msg tea.KeyMsg, registry *project.ProjectRegistry) (app.Model, tea.Cmd) {
-	nativeCmdNames := getSortedNativeCommandNames() // Use built-in list
+	nativeCmdNames := getSortedNativeCommandNames(m) // Use built-in list
 	totalCmds := len(nativeCmdNames)
 
 	// --- Paginator Setup ---
@@ -156,6 +166,14 @@ func UpdateScreenNativeList(m app.Model, msg tea.KeyMsg, registry *project.Proje
 			return m, nil
 		}
 
+	case "v":
+		m.NativeShowHidden = !m.NativeShowHidden
+		m.NativeListIndex = 0
+		m.NativePaginator.Page = 0
+		m.NativePaginator.SetTotalPages(len(getSortedNativeCommandNames(m)))
+		m = updateNativeListPreview(m)
+		return m, nil
+
 	case "esc", "b":
 		m.CurrentScreen = app.ScreenCommandsCategory
 		m.NativeListIndex = 0
@@ -169,7 +187,7 @@ func UpdateScreenNativeList(m app.Model, msg tea.KeyMsg, registry *project.Proje
 func ViewScreenNativeList(m app.Model, registry *project.ProjectRegistry) string {
 	header := app.TitleStyle.Render("Built-in Commands") + "\n" // Updated title
 
-	nativeCmdNames := getSortedNativeCommandNames() // Use built-in list
+	nativeCmdNames := getSortedNativeCommandNames(m) // Use built-in list
 	totalCmds := len(nativeCmdNames)
 
 	// --- Get paginated items ---
@@ -197,6 +215,13 @@ func ViewScreenNativeList(m app.Model, registry *project.ProjectRegistry) string
 		for i, name := range paginatedCmds {
 			// No favorite status for built-in commands
 			prefix := "  "
+			hidden := m.NativeShowHidden && !commands.IsCommandVisible(commands.GetCommandSpec(name), m.ProjectPath)
+			if commands.IsOverridden(name) {
+				name += " (overridden)"
+			}
+			if hidden {
+				name += " (hidden)"
+			}
 
 			if i == m.NativeListIndex {
 				listBuilder.WriteString(app.HighlightStyle.Render("> "+prefix+name) + "\n")
@@ -261,7 +286,11 @@ func ViewScreenNativeList(m app.Model, registry *project.ProjectRegistry) string
 
 	// --- Combine, Footer ---
 	combinedPanes := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, "  ", rightPanel)
-	footer := sharedScreens.Footer("↑↓ ←→ navigate", "enter to confirm", "ctrl+c quit")
+	hiddenHint := "v show hidden commands"
+	if m.NativeShowHidden {
+		hiddenHint = "v hide hidden commands"
+	}
+	footer := sharedScreens.Footer("↑↓ ←→ navigate", "enter to confirm", hiddenHint, "ctrl+c quit")
 
 	// Combine list, paginator, footer
 	finalView := lipgloss.JoinVertical(lipgloss.Left, header, combinedPanes, "\n", footer)
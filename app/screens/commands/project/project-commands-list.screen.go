@@ -86,7 +86,7 @@ func updateProjectCommandPreview(m app.Model) app.Model {
 
 	// --- Determine Placeholders ---
 	// Try to infer keys from the template content first
-	keys := commands.InferVariableKeys(string(data))
+	keys := commands.InferTemplateVariableKeys(data, m.ProjectPath)
 	var placeholderMap map[string]string
 	if len(keys) > 0 {
 		// Build placeholders with default <Value> style if keys found
@@ -257,6 +257,9 @@ func ViewScreenProjectCommandsList(m app.Model, registry *project.ProjectRegistr
 					prefix = "⭐ " // Add star for favorites
 				}
 			}
+			if spec, isOverride := commands.OverrideForLocalFile(name); isOverride {
+				name += " (overrides " + spec.Slug + ")"
+			}
 
 			if i == m.ProjectCommandsListIndex {
 				listBuilder.WriteString(app.HighlightStyle.Render("> "+prefix+name) + "\n")
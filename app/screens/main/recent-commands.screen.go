@@ -200,6 +200,14 @@ func UpdateScreenMain(m app.Model, msg tea.Msg, registry *project.ProjectRegistr
 				}
 			}
 
+		case "w":
+			// Switch to another package of the monorepo
+			if m.WorkspaceRoot != "" {
+				m.CurrentScreen = app.ScreenWorkspacePicker
+				m.WorkspaceIndex = 0
+				return m, nil
+			}
+
 		case "enter":
 			if m.MainScreenFocus == "action" {
 				if m.ActionIndex >= 0 && m.ActionIndex < len(actionRow) {
@@ -476,6 +484,9 @@ func ViewMainScreen(m app.Model, registry *project.ProjectRegistry) string {
 	} else {
 		for i, cmdName := range paginatedCmds {
 			label := truncateWithEllipsis(cmdName, 48)
+			if commands.IsOverridden(cmdName) {
+				label = truncateWithEllipsis(cmdName, 35) + " (overridden)"
+			}
 			// Check favorite status
 			prefix := ""
 			if registry != nil {
@@ -513,7 +524,11 @@ func ViewMainScreen(m app.Model, registry *project.ProjectRegistry) string {
 	// --- Define Footer Content First (Without Paginator) ---
 	// Suppress history/status messages on the Recent Commands screen
 	var statusLine string
-	footerHelp := sharedScreens.Footer("↑↓ ←→ navigate", "enter to confirm", "ctrl+c quit")
+	footerParts := []string{"↑↓ ←→ navigate", "enter to confirm"}
+	if m.WorkspaceRoot != "" {
+		footerParts = append(footerParts, "w switch workspace")
+	}
+	footerHelp := sharedScreens.Footer(append(footerParts, "ctrl+c quit")...)
 	footerContent := statusLine + footerHelp // Paginator is removed from here
 
 	// --- Calculate available height for panels ---
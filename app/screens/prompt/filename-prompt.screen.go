@@ -159,7 +159,7 @@ func UpdateScreenChoicePrompt(m app.Model, keyMsg tea.KeyMsg, registry *project.
 		if m.ChoiceIndex >= 0 && m.ChoiceIndex < len(m.ChoiceTargetSlugs) {
 			target := m.ChoiceTargetSlugs[m.ChoiceIndex]
 			// Auto-browse: if target looks like an embedded folder (ends with '/'), drill down
-			if strings.HasSuffix(target, "/") && strings.HasPrefix(target, "native-commands/") {
+			if strings.HasSuffix(target, "/") && commands.IsTemplateTreePath(target) {
 				prefix := strings.TrimSuffix(target, "/")
 				children, err := commands.ListNativeChildren(prefix)
 				if err == nil && len(children) > 0 {
@@ -219,7 +219,7 @@ func UpdateScreenChoicePrompt(m app.Model, keyMsg tea.KeyMsg, registry *project.
 	if !m.PromptOptionFocused && m.ChoiceIndex >= 0 && m.ChoiceIndex < len(m.ChoiceTargetSlugs) {
 		sel := m.ChoiceTargetSlugs[m.ChoiceIndex]
 		// Folder path: preview nearest JSON inside
-		if strings.HasPrefix(sel, "native-commands/") && strings.HasSuffix(sel, "/") {
+		if commands.IsTemplateTreePath(sel) && strings.HasSuffix(sel, "/") {
 			if nearest, ok := commands.FindFirstJSONUnder(strings.TrimSuffix(sel, "/")); ok {
 				keys, _ := commands.GetCommandVariableKeys(nearest, m.ProjectPath, registry)
 				var ph map[string]string
@@ -238,7 +238,7 @@ func UpdateScreenChoicePrompt(m app.Model, keyMsg tea.KeyMsg, registry *project.
 			}
 		}
 		// File path in embedded tree
-		if strings.HasPrefix(sel, "native-commands/") && strings.HasSuffix(sel, ".json") {
+		if commands.IsTemplateTreePath(sel) && strings.HasSuffix(sel, ".json") {
 			keys, _ := commands.GetCommandVariableKeys(sel, m.ProjectPath, registry)
 			if pr, _ := commands.GetCommandVariablePriorities(sel, m.ProjectPath, registry); len(pr) > 0 {
 				keys = orderKeysByPriority(keys, pr)
@@ -312,7 +312,7 @@ func ViewChoicePrompt(m app.Model, registry *project.ProjectRegistry) string {
 		if len(m.ChoiceTargetSlugs) > 0 {
 			first := m.ChoiceTargetSlugs[0]
 			// If first is an embedded folder, preview nearest JSON
-			if strings.HasPrefix(first, "native-commands/") && strings.HasSuffix(first, "/") {
+			if commands.IsTemplateTreePath(first) && strings.HasSuffix(first, "/") {
 				if nearest, ok := commands.FindFirstJSONUnder(strings.TrimSuffix(first, "/")); ok {
 					keys, _ := commands.GetCommandVariableKeys(nearest, m.ProjectPath, registry)
 					var ph map[string]string
@@ -333,7 +333,7 @@ func ViewChoicePrompt(m app.Model, registry *project.ProjectRegistry) string {
 				} else {
 					rawPreview = "No preview available for this command."
 				}
-			} else if strings.HasPrefix(first, "native-commands/") && strings.HasSuffix(first, ".json") {
+			} else if commands.IsTemplateTreePath(first) && strings.HasSuffix(first, ".json") {
 				// First is an embedded JSON path
 				keys, _ := commands.GetCommandVariableKeys(first, m.ProjectPath, registry)
 				var ph map[string]string
@@ -333,7 +333,7 @@ func HandleCommandSelection(m *app.Model, registry *project.ProjectRegistry, ite
 				// Prefill preview for the first target so it's visible immediately
 				if len(m.ChoiceTargetSlugs) > 0 {
 					first := m.ChoiceTargetSlugs[0]
-					if strings.HasPrefix(first, "native-commands/") && strings.HasSuffix(first, "/") {
+					if commands.IsTemplateTreePath(first) && strings.HasSuffix(first, "/") {
 						if nearest, ok := commands.FindFirstJSONUnder(strings.TrimSuffix(first, "/")); ok {
 							keys, _ := commands.GetCommandVariableKeys(nearest, m.ProjectPath, registry)
 							var ph map[string]string
@@ -349,7 +349,7 @@ func HandleCommandSelection(m *app.Model, registry *project.ProjectRegistry, ite
 								}
 							}
 						}
-					} else if strings.HasPrefix(first, "native-commands/") && strings.HasSuffix(first, ".json") {
+					} else if commands.IsTemplateTreePath(first) && strings.HasSuffix(first, ".json") {
 						keys, _ := commands.GetCommandVariableKeys(first, m.ProjectPath, registry)
 						var ph map[string]string
 						if len(keys) > 0 {
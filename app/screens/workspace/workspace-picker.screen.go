@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/cli"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/commands"
+	"github.com/Guerrilla-Interactive/nextgen-go-cli/app/project"
+	sharedScreens "github.com/Guerrilla-Interactive/nextgen-go-cli/app/screens/shared"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerOptions lists the monorepo root followed by its packages.
+func pickerOptions(m app.Model) []project.Workspace {
+	mono, ok := project.DetectMonorepo(m.WorkspaceRoot)
+	if !ok {
+		return nil
+	}
+	root := project.Workspace{Name: filepath.Base(mono.Root) + " (monorepo root)", Path: mono.Root, RelPath: "."}
+	return append([]project.Workspace{root}, mono.Workspaces...)
+}
+
+// selectWorkspace makes ws the project: the process moves there, so commands run in it,
+// and its packs, local overrides and detected packages replace those of the previous one.
+func selectWorkspace(m app.Model, ws project.Workspace, registry *project.ProjectRegistry) app.Model {
+	if err := os.Chdir(ws.Path); err != nil {
+		m.HistorySaveStatus = fmt.Sprintf("Could not enter %s: %v", ws.Name, err)
+		return m
+	}
+	if ws.RelPath == "." {
+		cli.SetWorkspace("")
+	} else {
+		cli.SetWorkspace(ws.Name)
+	}
+	commands.LoadProjectPacks(ws.Path)
+	commands.LoadLocalOverrides(ws.Path)
+	m.ProjectPath = ws.Path
+	m.RecognizedPkgs = nil
+	if info, found := project.DetectProject(ws.Path); found {
+		m.RecognizedPkgs = info.DetectedPackages
+		if registry != nil {
+			registry.AddOrUpdateProject(info)
+		}
+	}
+	m.SelectedIndex = 0
+	m.MainListPaginator.Page = 0
+	return m
+}
+
+// UpdateScreenWorkspacePicker handles input on the monorepo package picker.
+func UpdateScreenWorkspacePicker(m app.Model, msg tea.KeyMsg, registry *project.ProjectRegistry) (app.Model, tea.Cmd) {
+	options := pickerOptions(m)
+	numOptions := len(options)
+
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+
+	case "up", "k":
+		if numOptions > 0 {
+			m.WorkspaceIndex = (m.WorkspaceIndex + numOptions - 1) % numOptions
+		}
+
+	case "down", "j":
+		if numOptions > 0 {
+			m.WorkspaceIndex = (m.WorkspaceIndex + 1) % numOptions
+		}
+
+	case "enter":
+		if m.WorkspaceIndex >= 0 && m.WorkspaceIndex < numOptions {
+			m = selectWorkspace(m, options[m.WorkspaceIndex], registry)
+		}
+		m.CurrentScreen = app.ScreenMain
+		return m, nil
+
+	case "esc", "b": // Keep the current project
+		m.CurrentScreen = app.ScreenMain
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// ViewScreenWorkspacePicker renders the monorepo packages with the highlighted one's
+// detected packages.
+func ViewScreenWorkspacePicker(m app.Model) string {
+	header := app.TitleStyle.Render("Pick a Workspace") + "\n"
+	options := pickerOptions(m)
+
+	var listBuilder strings.Builder
+	listBuilder.WriteString(app.SubtitleStyle.Render("Run commands in:") + "\n\n")
+	for i, ws := range options {
+		label := ws.Name
+		if ws.Path == m.ProjectPath {
+			label += " (current)"
+		}
+		if i == m.WorkspaceIndex {
+			listBuilder.WriteString(app.HighlightStyle.Render("> "+label) + "\n")
+		} else {
+			listBuilder.WriteString(app.ChoiceStyle.Render("  "+label) + "\n")
+		}
+	}
+
+	leftPanel := lipgloss.NewStyle().
+		Width(40).
+		Padding(1, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Render(listBuilder.String())
+
+	previewContent := app.HelpStyle.Render("(No packages found)")
+	if m.WorkspaceIndex >= 0 && m.WorkspaceIndex < len(options) {
+		ws := options[m.WorkspaceIndex]
+		previewContent = sharedScreens.ProjectHeader(ws.Path) + "\n\n" + app.HelpStyle.Render(ws.RelPath)
+		if info, found := project.DetectProject(ws.Path); found {
+			if stats := app.SummarizeFullProjectStats(info.DetectedPackages); stats != "" {
+				previewContent += "\n\n" + stats
+			}
+		}
+	}
+	rightPanel := lipgloss.NewStyle().
+		Padding(1, 2).
+		Height(lipgloss.Height(leftPanel)).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Render(previewContent)
+
+	combinedPanes := lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, "  ", rightPanel)
+	footer := sharedScreens.Footer("↑↓ navigate", "enter to confirm", "esc keep current", "ctrl+c quit")
+
+	finalView := lipgloss.JoinVertical(lipgloss.Left, header, combinedPanes, "\n", footer)
+	if m.TerminalWidth > 0 && m.TerminalHeight > 0 {
+		return lipgloss.Place(m.TerminalWidth, m.TerminalHeight, lipgloss.Left, lipgloss.Bottom, finalView)
+	}
+	return finalView
+}
@@ -7,15 +7,21 @@ import (
 	"path/filepath"
 )
 
-// LoadConfig reads config from ~/.ngc/config.json (or returns a default if missing)
-func LoadConfig() (Config, error) {
+// ConfigPath returns the path of the config file, ~/.ngc/config.json
+func ConfigPath() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return Config{}, fmt.Errorf("could not find home directory: %w", err)
+		return "", fmt.Errorf("could not find home directory: %w", err)
 	}
+	return filepath.Join(homeDir, ".ngc", "config.json"), nil
+}
 
-	configDir := filepath.Join(homeDir, ".ngc")
-	configPath := filepath.Join(configDir, "config.json")
+// LoadConfig reads config from ~/.ngc/config.json (or returns a default if missing)
+func LoadConfig() (Config, error) {
+	configPath, err := ConfigPath()
+	if err != nil {
+		return Config{}, err
+	}
 
 	// If file doesn't exist, return defaults
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -36,18 +42,14 @@ func LoadConfig() (Config, error) {
 
 // SaveConfig writes Config to ~/.ngc/config.json
 func SaveConfig(cfg Config) error {
-	homeDir, err := os.UserHomeDir()
+	configPath, err := ConfigPath()
 	if err != nil {
-		return fmt.Errorf("could not find home directory: %w", err)
+		return err
 	}
-
-	configDir := filepath.Join(homeDir, ".ngc")
-	if err := os.MkdirAll(configDir, 0o755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	configPath := filepath.Join(configDir, "config.json")
-
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -33,6 +33,7 @@ import (
 	promptScreen "github.com/Guerrilla-Interactive/nextgen-go-cli/app/screens/prompt"
 	settingsScreen "github.com/Guerrilla-Interactive/nextgen-go-cli/app/screens/settings"
 	sharedScreens "github.com/Guerrilla-Interactive/nextgen-go-cli/app/screens/shared"
+	workspaceScreen "github.com/Guerrilla-Interactive/nextgen-go-cli/app/screens/workspace"
 	config "github.com/Guerrilla-Interactive/nextgen-go-cli/internal"
 )
 
@@ -42,6 +43,10 @@ var Version = "v1.0.131"
 // exitLog holds a log-style summary printed after the TUI exits.
 var exitLog string
 
+// exitDependencies holds, by project path, the packages templates run from the TUI need
+// but the project does not list; they are offered for install after the TUI exits.
+var exitDependencies = map[string][]template_cmds.TemplateDependency{}
+
 // determine CLI name variants (primary + aliases)
 func detectPrimaryCLIName() string {
 	exe := filepath.Base(os.Args[0])
@@ -90,60 +95,39 @@ type ProgramModel struct {
 	InitialDetection bool                     // Track if initial project detection was performed
 }
 
-// projectCommandFile represents a local project command that wraps a shell command string.
-type projectCommandFile struct {
-	Command string `json:"command"`
-}
 
 // commandRegistryCheckerBridge implements cli.CommandRegistryChecker using the commands package.
 // This avoids a direct import cycle.
 type commandRegistryCheckerBridge struct{}
 
 func (b commandRegistryCheckerBridge) CommandExists(name string) bool {
-	// Check args registry
+	// Args commands need no project context
 	if args_pkg.CommandExists(name) {
 		return true
 	}
-	// Check built-in template command list
-	if _, found := template_cmds.TemplatePathFor(name); found {
-		return true
-	}
-
-	// --- Load registry to check other types (inefficient, but necessary for now) ---
-	registry, err := project.LoadProjectRegistry() // Load registry here
-	if err == nil {                                // Only proceed if registry loaded successfully
-		// Check user-saved Native Commands
-		if registry.NativeCommands != nil {
-			if _, nativeFound := registry.NativeCommands[name]; nativeFound {
-				return true
-			}
-		}
-		// Check Clipboard Commands
-		if registry.ClipboardCommands != nil {
-			if _, clipboardFound := registry.ClipboardCommands[name]; clipboardFound {
-				return true
-			}
-		}
-	} else {
-		// Log warning if registry fails to load during check?
+	// Everything else is looked up the same way it is executed
+	registry, err := project.LoadProjectRegistry()
+	if err != nil {
 		if cli.IsDebugEnabled() {
 			fmt.Printf("DEBUG [CommandExists]: Could not load registry to check command '%s': %v\n", name, err)
 		}
+		registry = nil
 	}
-
-	// --- Check Project Commands (.nextgen/local-commands) ---
 	projectPath, err := os.Getwd()
-	if err == nil { // Only proceed if we can get the current directory
-		localCmdDir := filepath.Join(projectPath, ".nextgen", "local-commands")
-		kebabName := template_cmds.ToKebabCase(name) // Assume command name needs conversion
-		cmdFilePath := filepath.Join(localCmdDir, kebabName+".json")
-		if _, statErr := os.Stat(cmdFilePath); statErr == nil {
-			// File exists, so the command is considered valid
-			return true
-		}
+	if err != nil {
+		projectPath = "."
 	}
+	return template_cmds.NewResolver(projectPath, registry).Exists(name)
+}
 
-	return false // Not found in any known location
+// CommandFlags returns the flags an args command declares, so the parser knows which take
+// a value. Template commands only have the global flags.
+func (b commandRegistryCheckerBridge) CommandFlags(name string) ([]cli.FlagDef, bool) {
+	cmd, ok := args_pkg.GetCommand(name)
+	if !ok {
+		return nil, false
+	}
+	return cmd.ExpectedFlags(), true
 }
 
 type HeartbeatMsg struct{}
@@ -354,6 +338,11 @@ func (pm ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
             } else {
                 pm.M.HistorySaveStatus = "Could not save history (no registry or invalid path)"
             }
+            if tmplBytes, _, err := template_cmds.LoadTemplateBytesForName(typedMsg.CommandName, typedMsg.ProjectPath, pm.ProjectRegistry); err == nil {
+                if missing := template_cmds.MissingDependencies(tmplBytes, typedMsg.ProjectPath); len(missing) > 0 {
+                    exitDependencies[typedMsg.ProjectPath] = template_cmds.MergeDependencies(exitDependencies[typedMsg.ProjectPath], missing)
+                }
+            }
         } else {
             // --- Command Failed ---
             pm.M.HistorySaveStatus = fmt.Sprintf("Command '%s' failed: %v", typedMsg.CommandName, typedMsg.Err)
@@ -463,6 +452,10 @@ func (pm ProgramModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			updatedM, cmd := projectCmdScreen.UpdateScreenProjectCommandActions(pm.M, typedMsg, pm.ProjectRegistry)
 			pm.M = updatedM
 			return pm, cmd
+		case app.ScreenWorkspacePicker:
+			updatedM, cmd := workspaceScreen.UpdateScreenWorkspacePicker(pm.M, typedMsg, pm.ProjectRegistry)
+			pm.M = updatedM
+			return pm, cmd
 		default:
 			// Forward non-key/custom messages to current screen when needed (e.g., ticks)
 			switch pm.M.CurrentScreen {
@@ -530,11 +523,14 @@ func (pm ProgramModel) View() string {
 		return projectCmdScreen.ViewScreenProjectCommandActions(pm.M, pm.ProjectRegistry)
 	case app.ScreenChoicePrompt:
 		return promptScreen.ViewChoicePrompt(pm.M, pm.ProjectRegistry)
+	case app.ScreenWorkspacePicker:
+		return workspaceScreen.ViewScreenWorkspacePicker(pm.M)
 	}
 	return ""
 }
 
 func main() {
+	cli.SetVersion(Version)
 	// Enable debug early if --debug present in raw args
 	raw := os.Args[1:]
 	for _, a := range raw {
@@ -550,11 +546,32 @@ func main() {
 			break
 		}
 	}
+	// Report results as a single JSON document if --output json or --json present
+	if format, _ := cli.OutputFormatFromArgs(raw); format != "" {
+		cli.SetOutputFormat(format)
+	}
 	if cli.IsDebugEnabled() {
 		fmt.Println("DEBUG: main() function started.")
 	}
 	args := os.Args[1:] // Get arguments excluding program name
 
+	// --- Run in the monorepo package named by --workspace, as if started in its directory ---
+	if len(args) == 0 || args[0] != args_pkg.CompleteCommandName {
+		var workspaceName string
+		workspaceName, args = cli.WorkspaceFromArgs(args)
+		if workspaceName != "" {
+			if err := enterWorkspace(workspaceName); err != nil {
+				if cli.IsJSONOutput() {
+					result := &cli.CommandResult{}
+					result.Fail(cli.ErrCodeInvalidArguments, err)
+					exitWithJSON(result)
+				}
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	}
+
 	// --- Load Project Registry ---
 	if cli.IsDebugEnabled() {
 		fmt.Println("DEBUG: Attempting to load project registry...")
@@ -564,7 +581,7 @@ func main() {
 		if cli.IsDebugEnabled() {
 			fmt.Printf("DEBUG: Error loading project registry: %v\n", err)
 		}
-		fmt.Printf("Warning: Could not load project registry: %v\n", err)
+		cli.Warn("Could not load project registry: %v", err)
 		// Continue with an empty registry rather than failing
 		projectRegistry = &project.ProjectRegistry{
 			Projects:     make(map[string]project.ProjectInfo),
@@ -578,13 +595,29 @@ func main() {
 		}
 	}
 
-	// --- Update .nextgen/nextgen-cli-commands.mdc on every run ---
+	// --- Register commands from template packs installed in the current project ---
 	if cwd, cwdErr := os.Getwd(); cwdErr == nil {
-		if writeErr := args_pkg.WriteNextgenCommandsMDC(cwd, projectRegistry); writeErr != nil {
-			fmt.Printf("Warning: Failed to update .nextgen/nextgen-cli-commands.mdc: %v\n", writeErr)
+		template_cmds.LoadProjectPacks(cwd)
+		// Project templates with the slug of a registered command shadow it
+		template_cmds.LoadLocalOverrides(cwd)
+	}
+
+	// --- Hidden entry point the shell completion scripts call (`ng completion <shell>`) ---
+	if len(args) > 0 && args[0] == args_pkg.CompleteCommandName {
+		cwd, _ := os.Getwd()
+		for _, candidate := range args_pkg.Complete(args[1:], cwd, projectRegistry) {
+			fmt.Println(candidate)
+		}
+		os.Exit(0)
+	}
+
+	// --- Refresh command docs for projects that opted in with `ng docs enable` ---
+	if !isInfoInvocation(args) {
+		if cwd, cwdErr := os.Getwd(); cwdErr == nil {
+			if _, docsErr := args_pkg.AutoGenerateDocs(cwd, projectRegistry); docsErr != nil {
+				cli.Warn("Failed to update command docs: %v", docsErr)
+			}
 		}
-	} else {
-		fmt.Printf("Warning: Could not determine working directory to update commands MDC: %v\n", cwdErr)
 	}
 
 	// Create the registry checker bridge
@@ -600,9 +633,17 @@ func main() {
 		if parsedArgs.BoolFlags["verbose"] {
 			cli.SetVerboseEnabled(true)
 		}
+		if parsedArgs.BoolFlags["allow-unresolved"] {
+			cli.SetAllowUnresolvedEnabled(true)
+		}
 
 		// Handle parsing errors
 		if len(parsedArgs.Errors) > 0 {
+			if cli.IsJSONOutput() {
+				result := &cli.CommandResult{}
+				result.Fail(cli.ErrCodeInvalidArguments, errors.Join(parsedArgs.Errors...))
+				exitWithJSON(result)
+			}
 			fmt.Println("Error parsing arguments:")
 			for _, err := range parsedArgs.Errors {
 				fmt.Printf("  - %v\n", err)
@@ -612,23 +653,23 @@ func main() {
 
 		// Handle --version flag first, as it takes precedence
 		if parsedArgs.VersionRequested {
+			if cli.IsJSONOutput() {
+				exitWithJSON(&cli.CommandResult{OK: true, Data: map[string]string{"version": Version}})
+			}
 			fmt.Printf("NextGen Go CLI %s\n", Version)
 			os.Exit(0)
 		}
 
 		// Check for help *before* deciding whether to execute or show general help
-		var isHelpIntent bool
-		for _, arg := range parsedArgs.RawArgs { // Check raw args for --help or -h
-			if arg == "--help" || arg == "-h" {
-				isHelpIntent = true
-				break
-			}
-		}
+		isHelpIntent := parsedArgs.HelpRequested // --help or -h before any `--`
 
 		// Now, route based on command name and help intent
 		if parsedArgs.CommandName != "" {
 			if isHelpIntent {
 				// Command-specific help requested
+				if cli.IsJSONOutput() {
+					exitWithJSON(commandHelpResult(parsedArgs.CommandName))
+				}
 				displayCommandHelp(parsedArgs.CommandName)
 				os.Exit(0)
 			} else {
@@ -644,10 +685,22 @@ func main() {
 			}
 			if isHelpIntent {
 				// General help requested
+				if cli.IsJSONOutput() {
+					exitWithJSON(&cli.CommandResult{OK: true, Data: generalHelpData()})
+				}
 				displayGeneralHelp()
 				os.Exit(0)
 			} else {
 				// No command, no help, no version - invalid usage
+				_, rest := cli.OutputFormatFromArgs(args)
+				if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+					handleUnknownCommand(rest, projectRegistry)
+				}
+				if cli.IsJSONOutput() {
+					result := &cli.CommandResult{}
+					result.Fail(cli.ErrCodeInvalidArguments, errors.New("invalid arguments or flags provided without a command name"))
+					exitWithJSON(result)
+				}
 				fmt.Println("Error: Invalid arguments or flags provided without a command name.")
 				_, variants := cliNameVariants()
 				if len(variants) > 0 {
@@ -750,6 +803,16 @@ func main() {
 		}
 	}
 
+	// At the root of a monorepo, start by picking the package to work in (unless --workspace did)
+	if currentDir != "" {
+		if mono, ok := project.DetectMonorepo(currentDir); ok && len(mono.Workspaces) > 0 {
+			initialModel.WorkspaceRoot = mono.Root
+			if initialModel.IsLoggedIn && cli.Workspace() == "" && mono.Root == currentDir {
+				initialModel.CurrentScreen = app.ScreenWorkspacePicker
+			}
+		}
+	}
+
 	// Set default terminal dimensions so panels are anchored on first render.
 	if initialModel.TerminalHeight == 0 {
 		initialModel.TerminalHeight = 24
@@ -775,6 +838,52 @@ func main() {
     if strings.TrimSpace(exitLog) != "" {
         fmt.Println(exitLog)
     }
+    // Offer to install packages the generated code imports
+    paths := make([]string, 0, len(exitDependencies))
+    for path := range exitDependencies {
+        paths = append(paths, path)
+    }
+    sort.Strings(paths)
+    for _, path := range paths {
+        if _, err := template_cmds.DependencyPostStep(path, exitDependencies[path], false); err != nil {
+            fmt.Println("Error:", err)
+            os.Exit(1)
+        }
+    }
+}
+
+// enterWorkspace changes to the directory of the named package of the monorepo around the
+// current directory, so commands, packs and visibility rules all see that package. Paths
+// given on the command line still resolve against the starting directory (cli.UserPath).
+func enterWorkspace(name string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("could not get current directory: %w", err)
+	}
+	ws, err := project.ResolveWorkspace(cwd, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Chdir(ws.Path); err != nil {
+		return fmt.Errorf("could not enter workspace %s: %w", ws.Name, err)
+	}
+	cli.SetStartDir(cwd)
+	cli.SetWorkspace(ws.Name)
+	if cli.IsDebugEnabled() {
+		fmt.Printf("DEBUG: Running in workspace %s (%s)\n", ws.Name, ws.Path)
+	}
+	return nil
+}
+
+// isInfoInvocation reports whether the arguments only ask for help or the version, which
+// should not touch the current directory.
+func isInfoInvocation(args []string) bool {
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" || arg == "--version" {
+			return true
+		}
+	}
+	return false
 }
 
 // displayGeneralHelp prints the top-level help message.
@@ -802,7 +911,25 @@ func displayGeneralHelp() {
 	} else {
 		fmt.Println("\nNo commands registered yet.")
 	}
-	fmt.Println("\nGlobal Flags: --help, -h, --version")
+	fmt.Println("\nGlobal Flags: " + globalFlagsHelp())
+}
+
+// globalFlagsHelp lists the flags every command accepts: cli.GlobalFlags plus --output and
+// --workspace, which are taken out before parsing.
+func globalFlagsHelp() string {
+	var parts []string
+	for _, flag := range cli.GlobalFlags {
+		usage := "--" + flag.Name
+		if flag.ShortName != "" {
+			usage += ", -" + flag.ShortName
+		}
+		if flag.HasValue {
+			usage += " <value>"
+		}
+		parts = append(parts, usage)
+	}
+	parts = append(parts, "--output json (--json)", "--workspace <name>")
+	return strings.Join(parts, ", ")
 }
 
 // displayCommandHelp displays detailed help for a specific command.
@@ -844,16 +971,163 @@ func displayCommandHelp(commandName string) {
 				flagUsage += ", -" + flag.ShortName
 			}
 			if flag.HasValue {
-				flagUsage += " <value>"
+				switch flag.Type {
+				case cli.FlagTypeInt:
+					flagUsage += " <int>"
+				case cli.FlagTypeFloat:
+					flagUsage += " <number>"
+				default:
+					flagUsage += " <value>"
+				}
 			}
 			required := ""
 			if flag.Required {
 				required = " (required)"
 			}
+			if flag.Repeatable {
+				required += " (repeatable)"
+			}
 			fmt.Printf("  %-15s %s%s\n", flagUsage, flag.Description, required)
 		}
 	}
-	fmt.Println("\nGlobal Flags: --help, -h, --version") // Also mention global flags here
+	fmt.Println("\nGlobal Flags: " + globalFlagsHelp())
+}
+
+// helpCommand is the JSON description of an args command in help output.
+type helpCommand struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Usage       string             `json:"usage"`
+	Args        []args_pkg.ArgDef  `json:"args,omitempty"`
+	Flags       []args_pkg.FlagDef `json:"flags,omitempty"`
+}
+
+func newHelpCommand(cmd args_pkg.Command) helpCommand {
+	return helpCommand{
+		Name:        cmd.Name(),
+		Description: cmd.Description(),
+		Usage:       strings.TrimSpace(cmd.Name() + " " + cmd.Usage()),
+		Args:        cmd.ExpectedArgs(),
+		Flags:       cmd.ExpectedFlags(),
+	}
+}
+
+// generalHelpData returns the data of the top-level help message for JSON output.
+func generalHelpData() map[string]any {
+	allCmds := args_pkg.GetAllCommands()
+	sort.Slice(allCmds, func(i, j int) bool {
+		return allCmds[i].Name() < allCmds[j].Name()
+	})
+	cmds := make([]helpCommand, len(allCmds))
+	for i, cmd := range allCmds {
+		cmds[i] = newHelpCommand(cmd)
+	}
+	return map[string]any{
+		"commands":    cmds,
+		"globalFlags": []string{"--help", "-h", "--version", "--allow-unresolved", "--output <text|json>", "--json", "--var Key=Value", "--vars-file <file>", "--workspace <name>", "--install"},
+	}
+}
+
+// commandHelpResult returns the help of a single command for JSON output.
+func commandHelpResult(commandName string) *cli.CommandResult {
+	cmd, found := args_pkg.GetCommand(commandName)
+	if !found {
+		result := &cli.CommandResult{Command: commandName}
+		result.Fail(cli.ErrCodeUnknownCommand, fmt.Errorf("unknown command '%s'", commandName))
+		return result
+	}
+	return &cli.CommandResult{OK: true, Command: commandName, Data: newHelpCommand(cmd)}
+}
+
+// exitWithJSON prints the JSON result of the invocation and exits with its exit code.
+func exitWithJSON(result *cli.CommandResult) {
+	if err := result.WriteJSON(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing JSON result: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(result.ExitCode)
+}
+
+// handleUnknownCommand handles arguments whose leading words name no command. When they
+// are an unambiguous prefix of one (`ng add-page`) the user is asked whether to run it;
+// otherwise the closest commands are suggested. It always exits.
+func handleUnknownCommand(rest []string, registry *project.ProjectRegistry) {
+	words := rest
+	for i, w := range rest {
+		if strings.HasPrefix(w, "-") {
+			words = rest[:i]
+			break
+		}
+	}
+	cwd, _ := os.Getwd()
+	typed := strings.Join(words, " ")
+
+	if !cli.IsJSONOutput() && cli.StdinIsTerminal() {
+		if match, consumed, ok := args_pkg.PrefixCommandMatch(words, cwd, registry); ok {
+			fmt.Printf("Unknown command '%s'. Run '%s'? [y/N] ", strings.Join(words[:consumed], " "), match)
+			var answer string
+			fmt.Scanln(&answer)
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "y" || answer == "yes" {
+				newArgs := append(strings.Fields(match), rest[consumed:]...)
+				parsedArgs := cli.ParseCommandLineArgs(newArgs, commandRegistryCheckerBridge{})
+				if parsedArgs.BoolFlags["allow-unresolved"] {
+					cli.SetAllowUnresolvedEnabled(true)
+				}
+				if len(parsedArgs.Errors) > 0 {
+					fmt.Println("Error parsing arguments:")
+					for _, err := range parsedArgs.Errors {
+						fmt.Printf("  - %v\n", err)
+					}
+					os.Exit(1)
+				}
+				executeAndExit(parsedArgs, registry)
+			}
+			os.Exit(1)
+		}
+	}
+
+	suggestions := args_pkg.SuggestCommands(words, cwd, registry)
+	if cli.IsJSONOutput() {
+		result := &cli.CommandResult{Command: typed}
+		result.Fail(cli.ErrCodeUnknownCommand, fmt.Errorf("unknown command '%s'", typed))
+		if len(suggestions) > 0 {
+			result.Data = map[string][]string{"suggestions": suggestions}
+		}
+		exitWithJSON(result)
+	}
+	fmt.Printf("Error: Unknown command '%s'\n", typed)
+	if len(suggestions) > 0 {
+		fmt.Println("\nDid you mean:")
+		for _, s := range suggestions {
+			fmt.Printf("  %s\n", s)
+		}
+		fmt.Println()
+	}
+	_, variants := cliNameVariants()
+	if len(variants) > 0 {
+		fmt.Printf("Run `%s --help` for usage.\n", variants[0])
+	} else {
+		fmt.Println("Run `ng --help` for usage.")
+	}
+	os.Exit(1)
+}
+
+// commandError tags an execution error with the error code reported in JSON output.
+type commandError struct {
+	code string
+	err  error
+}
+
+func (e commandError) Error() string { return e.err.Error() }
+func (e commandError) Unwrap() error { return e.err }
+
+// errorCode returns the JSON error code for an execution error.
+func errorCode(err error) string {
+	var cmdErr commandError
+	if errors.As(err, &cmdErr) {
+		return cmdErr.code
+	}
+	return cli.ErrCodeExecutionFailed
 }
 
 // executeAndExit attempts to execute a command based on parsed args and exits.
@@ -864,12 +1138,32 @@ func executeAndExit(parsedArgs cli.CommandArgs, registry *project.ProjectRegistr
 	}
 	currentDir, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Warning: Could not determine current directory for command execution: %v\n", err)
+		cli.Warn("Could not determine current directory for command execution: %v", err)
 	}
 	if cli.IsDebugEnabled() {
 		fmt.Printf("DEBUG: Current working directory for execution: %s\n", currentDir)
 	}
 
+	// In JSON mode everything the command prints is captured into the result
+	if cli.IsJSONOutput() {
+		result := &cli.CommandResult{OK: true, Command: parsedArgs.CommandName}
+		start := time.Now()
+		output, err := cli.CaptureStdout(func() error {
+			if cmd, found := args_pkg.GetCommand(parsedArgs.CommandName); found {
+				if err := template_cmds.ValidateArgs(parsedArgs, cmd.ExpectedArgs(), cmd.ExpectedFlags()); err != nil {
+					return commandError{cli.ErrCodeInvalidArguments, err}
+				}
+			}
+			return executeDirectCommand(parsedArgs, registry, result)
+		})
+		result.Output = output
+		result.DurationMs = time.Since(start).Milliseconds()
+		if err != nil {
+			result.Fail(errorCode(err), err)
+		}
+		exitWithJSON(result)
+	}
+
 	if cli.IsVerboseEnabled() {
 		fmt.Printf("Attempting direct execution for command: %s\n", parsedArgs.CommandName)
 		fmt.Printf("Variables: %v\n", parsedArgs.Variables)
@@ -887,7 +1181,7 @@ func executeAndExit(parsedArgs cli.CommandArgs, registry *project.ProjectRegistr
 		}
 	}
 
-	err = executeDirectCommand(parsedArgs, registry) // Pass registry
+	err = executeDirectCommand(parsedArgs, registry, nil) // Pass registry
 	if err != nil {
 		fmt.Printf("Error executing command '%s': %v\n", parsedArgs.CommandName, err)
 		os.Exit(1)
@@ -895,218 +1189,168 @@ func executeAndExit(parsedArgs cli.CommandArgs, registry *project.ProjectRegistr
 	os.Exit(0) // Exit after successful direct execution
 }
 
-// executeDirectCommand handles the core command execution.
-func executeDirectCommand(args cli.CommandArgs, registry *project.ProjectRegistry) error {
+// executeDirectCommand handles the core command execution. The command is looked up with
+// the shared resolver, so it runs exactly what `ng which` reports. When result is not nil
+// it is filled in for JSON output instead of printing the created files.
+func executeDirectCommand(args cli.CommandArgs, registry *project.ProjectRegistry, result *cli.CommandResult) error {
 	commandName := args.CommandName
 	commandArgs := args.Variables // Positional args after command name
 
 	// --- Define projectPath early so it's available in all blocks ---
 	projectPath, pathErr := os.Getwd()
 	if pathErr != nil {
-		fmt.Printf("Warning: Could not get current directory: %v. Using '.' as fallback.\n", pathErr)
+		cli.Warn("Could not get current directory: %v. Using '.' as fallback.", pathErr)
 		projectPath = "."
 	}
 	// ------------------------------------------------------------------
 
-	// Initialize execution error variable
-	var execErr error
+	resolved, err := template_cmds.NewResolver(projectPath, registry).Resolve(commandName)
+	if errors.Is(err, template_cmds.ErrCommandNotFound) {
+		return commandError{cli.ErrCodeUnknownCommand, fmt.Errorf("unknown or unsupported command for direct execution: %s", commandName)}
+	}
+	if err != nil {
+		return err
+	}
+	if result != nil {
+		result.Resolved = &cli.ResolvedInfo{Source: string(resolved.Source), Kind: string(resolved.Kind), Location: resolved.Location, Description: resolved.Describe()}
+	}
+	if cli.IsDebugEnabled() {
+		fmt.Printf("DEBUG: Executing command '%s' as %s from %s...\n", commandName, resolved.Kind, resolved.Describe())
+	}
+
 	// Keep track of placeholders if applicable (for history)
 	var placeholders map[string]string
 
-	// 1. Try executing as an Arg-based command first
-	if cmd, found := args_pkg.GetCommand(commandName); found {
-		if cli.IsDebugEnabled() {
-			fmt.Printf("DEBUG: Executing command '%s' via args package...\n", commandName)
-		}
-		execErr = cmd.Execute(args)
+	switch resolved.Kind {
+	case template_cmds.KindArgs:
+		// 1. Arg-based commands report their own outcome
+		cmd, _ := args_pkg.GetCommand(commandName)
+		execErr := cmd.Execute(args)
 		if cli.IsDebugEnabled() {
 			fmt.Printf("DEBUG: Args command '%s' finished. Error: %v\n", commandName, execErr)
 		}
-		// Placeholders are not directly available from args commands for history
+		return execErr
 
-	} else if registry != nil && registry.NativeCommands != nil && registry.NativeCommands[commandName] != "" {
-		// 2. Try executing as a User-Saved Native Command
-		commandString := registry.NativeCommands[commandName]
-		if cli.IsDebugEnabled() {
-			fmt.Printf("DEBUG: Executing command '%s' as user-saved native command...\n", commandName)
+	case template_cmds.KindShell:
+		// 2. User-saved native commands and project shell commands
+		fmt.Printf("  Command: %s\n  Args: %v\n", resolved.Shell, commandArgs)
+		if execErr := runShellCommand(resolved.Shell, commandArgs, projectPath); execErr != nil || resolved.Source != template_cmds.SourceProject {
+			return execErr
 		}
-		fmt.Printf("  Command: %s\n  Args: %v\n", commandString, commandArgs)
-		execErr = runShellCommand(commandString, commandArgs, projectPath) // nolint:SA4006 -- value is used after branching
 
-	} else if registry != nil && registry.ClipboardCommands != nil && registry.ClipboardCommands[commandName].Template != "" {
-		// 3. Try executing as a Clipboard Command
-		clipboardSpec := registry.ClipboardCommands[commandName]
-		if cli.IsDebugEnabled() {
-			fmt.Printf("DEBUG: Executing command '%s' as clipboard command...\n", commandName)
-		}
-		templateBytes := []byte(clipboardSpec.Template)
-		keys := template_cmds.InferVariableKeys(string(templateBytes))
-		if len(keys) != len(commandArgs) {
-			usageParts := make([]string, len(keys))
-			for i, k := range keys {
-				usageParts[i] = fmt.Sprintf("<%s>", k)
+	default:
+		// 3. Templates take variables by position, --var, --vars-file and NG_VAR_ variables
+		vars := template_cmds.TemplateVariables(resolved.Template, projectPath)
+		varsMap, varsErr := template_cmds.ResolveTemplateVariables(vars, template_cmds.VariableInput{
+			Positional: commandArgs,
+			Named:      args.NamedVars,
+			FilePath:   cli.UserPath(args.Flags["vars-file"]),
+			Environ:    os.Environ(),
+		})
+		if varsErr != nil {
+			usageParts := make([]string, len(vars))
+			for i, v := range vars {
+				usageParts[i] = fmt.Sprintf("<%s>", v.Name)
+				if !v.Required {
+					usageParts[i] = "[" + usageParts[i] + "]"
+				}
 			}
 			usage := formatUsageBoth(commandName, strings.Join(usageParts, " "))
-			return fmt.Errorf("clipboard command '%s' requires %d argument(s): %s\nUsage: %s",
-				commandName, len(keys), strings.Join(keys, ", "), usage)
-		} else {
-			varsMap := make(map[string]string)
-			for i, key := range keys {
-				varsMap[key] = commandArgs[i]
+			label := "command"
+			if resolved.Source == template_cmds.SourceClipboard {
+				label = "clipboard command"
 			}
-			placeholders = template_cmds.BuildPlaceholders(varsMap) // Store placeholders
-			if cli.IsDebugEnabled() {
-				fmt.Printf("DEBUG: Running clipboard template with placeholders: %+v\n", placeholders)
-			}
-			template_cmds.CreatedFiles = []string{}
-			template_cmds.EditedIndexers = make(map[string]bool)
-			execErr = template_cmds.ExecuteJSONTemplateFromMemory(templateBytes, projectPath, placeholders) // nolint:SA4006 -- value is used after branching
+			return commandError{cli.ErrCodeInvalidArguments, fmt.Errorf("%s '%s': %w\nUsage: %s\nVariables can also be given as --var Key=Value, --vars-file <file> or %s<Key>.",
+				label, commandName, varsErr, usage, template_cmds.EnvVarPrefix)}
 		}
-
-	} else {
-		// 4. Try executing as a Project Command; if not found, fall back to Built-in Template Command
-		executedProject := false
-		if projectPath != "." {
-			localCmdDir := filepath.Join(projectPath, ".nextgen", "local-commands")
-			kebabName := template_cmds.ToKebabCase(commandName)
-			cmdFilePath := filepath.Join(localCmdDir, kebabName+".json")
-			if _, err := os.Stat(cmdFilePath); err == nil {
-				jsonData, readErr := os.ReadFile(cmdFilePath)
-				if readErr != nil {
-					execErr = fmt.Errorf("failed to read project command file '%s': %w", cmdFilePath, readErr)
-				} else {
-					// First try to parse as a shell command file
-					var cmdData projectCommandFile
-					if json.Unmarshal(jsonData, &cmdData) == nil && strings.TrimSpace(cmdData.Command) != "" {
-						commandString := cmdData.Command
-						if cli.IsDebugEnabled() {
-							fmt.Printf("DEBUG: Executing command '%s' as project command...\n", commandName)
-						}
-						fmt.Printf("  Command: %s\n  Args: %v\n", commandString, commandArgs)
-						execErr = runShellCommand(commandString, commandArgs, projectPath)
-						// Placeholders not applicable
-						executedProject = true
-					} else {
-						// Otherwise, try to treat it as a template JSON
-						var generic map[string]interface{}
-						if json.Unmarshal(jsonData, &generic) == nil {
-							if fp, ok := generic["filePaths"]; ok {
-								if arr, okArr := fp.([]interface{}); okArr && len(arr) > 0 {
-									if cli.IsDebugEnabled() {
-										fmt.Printf("DEBUG: Executing command '%s' as project template command...\n", commandName)
-									}
-									keys := template_cmds.InferVariableKeys(string(jsonData))
-									if len(keys) != len(commandArgs) {
-										usageParts := make([]string, len(keys))
-										for i, k := range keys {
-											usageParts[i] = fmt.Sprintf("<%s>", k)
-										}
-										usage := formatUsageBoth(commandName, strings.Join(usageParts, " "))
-										return fmt.Errorf(
-											"command '%s' requires %d argument(s): %s\nUsage: %s",
-											commandName, len(keys), strings.Join(keys, ", "), usage,
-										)
-									} else {
-										varsMap := make(map[string]string)
-										for i, key := range keys {
-											varsMap[key] = commandArgs[i]
-										}
-										placeholders = template_cmds.BuildPlaceholders(varsMap)
-										template_cmds.CreatedFiles = []string{}
-										template_cmds.EditedIndexers = make(map[string]bool)
-										execErr = template_cmds.ExecuteJSONTemplateFromMemory(jsonData, projectPath, placeholders)
-									}
-									executedProject = true
-								} else {
-									execErr = fmt.Errorf("invalid project command file '%s': missing 'command' or 'filePaths'", cmdFilePath)
-								}
-							} else {
-								execErr = fmt.Errorf("invalid project command file '%s': missing 'filePaths'", cmdFilePath)
-							}
-						} else {
-							execErr = fmt.Errorf("failed to parse project command file '%s'", cmdFilePath)
-						}
-					}
-				}
-			}
-
-			// 5. If not executed as a project command, try executing as a Built-in Template Command
-			if !executedProject {
-				spec := template_cmds.GetCommandSpec(commandName)
-				if spec.TemplatePath != "" {
-					if cli.IsDebugEnabled() {
-						fmt.Printf("DEBUG: Executing command '%s' as built-in template command...\n", commandName)
-					}
-					templateBytes, loadErr := template_cmds.LoadCommandTemplate(spec.TemplatePath)
-					if loadErr != nil {
-						execErr = fmt.Errorf("failed to load template %s: %w", spec.TemplatePath, loadErr)
-					} else {
-						keys := template_cmds.InferVariableKeys(string(templateBytes))
-						if len(keys) != len(commandArgs) {
-							usageParts := make([]string, len(keys))
-							for i, k := range keys {
-								usageParts[i] = fmt.Sprintf("<%s>", k)
-							}
-							usage := formatUsageBoth(commandName, strings.Join(usageParts, " "))
-							return fmt.Errorf("command '%s' requires %d argument(s): %s\nUsage: %s",
-								commandName, len(keys), strings.Join(keys, ", "), usage)
-						} else {
-							varsMap := make(map[string]string)
-							for i, key := range keys {
-								varsMap[key] = commandArgs[i]
-							}
-							placeholders = template_cmds.BuildPlaceholders(varsMap) // Store placeholders
-							if cli.IsDebugEnabled() {
-								fmt.Printf("DEBUG: Running template with placeholders: %+v\n", placeholders)
-							}
-							template_cmds.CreatedFiles = []string{}
-							template_cmds.EditedIndexers = make(map[string]bool)
-							execErr = template_cmds.ExecuteJSONTemplateFromMemory(templateBytes, projectPath, placeholders)
-						}
-					}
-				} else {
-					// If not found in any category
-					return fmt.Errorf("unknown or unsupported command for direct execution: %s", commandName)
-				}
-			}
+		if result != nil {
+			result.Variables = varsMap
+		}
+		placeholders = template_cmds.BuildPlaceholders(varsMap) // Store placeholders
+		if cli.IsDebugEnabled() {
+			fmt.Printf("DEBUG: Running template with placeholders: %+v\n", placeholders)
+		}
+		template_cmds.CreatedFiles = []string{}
+		template_cmds.EditedIndexers = make(map[string]bool)
+		execErr := template_cmds.ExecuteJSONTemplateFromMemory(resolved.Template, projectPath, placeholders)
+		if result != nil {
+			result.Files = changedFiles()
 		}
+		if execErr != nil {
+			return execErr
+		}
+		if err := installMissingDependencies(resolved.Template, projectPath, args.BoolFlags["install"]); err != nil {
+			return err
+		}
+		// Clipboard templates are not part of the project's history
+		if resolved.Source == template_cmds.SourceClipboard || resolved.Source == template_cmds.SourceClipboardPaste {
+			return nil
+		}
+	}
 
-		// --- Record History (Centralized Logic) ---
-		if execErr == nil { // Only record history if execution was successful
-			historicCmd := project.HistoricCommand{
-				Name:           commandName,
-				Variables:      placeholders, // Will be nil for non-template commands, which is fine
-				Timestamp:      time.Now().Unix(),
-				GeneratedFiles: append([]string{}, template_cmds.CreatedFiles...), // Copy generated files
-			}
-			if err := registry.RecordCommandHistory(projectPath, historicCmd); err != nil {
-				fmt.Printf("Warning: Failed to record command history for '%s': %v\n", commandName, err)
-				// Don't return this error, as the command itself succeeded
-			}
-		} else {
-			return execErr // Return the original execution error
+	// --- Record History (project and built-in commands) ---
+	historicCmd := project.HistoricCommand{
+		Name:           commandName,
+		Variables:      placeholders, // Will be nil for non-template commands, which is fine
+		Timestamp:      time.Now().Unix(),
+		GeneratedFiles: append([]string{}, template_cmds.CreatedFiles...), // Copy generated files
+	}
+	if registry != nil {
+		if err := registry.RecordCommandHistory(projectPath, historicCmd); err != nil {
+			cli.Warn("Failed to record command history for '%s': %v", commandName, err)
+			// Don't return this error, as the command itself succeeded
 		}
+	}
 
-		// --- Print File Tree on Success (Only for Template Commands) ---
-		if len(template_cmds.CreatedFiles) > 0 { // Check if files were generated
-			fmt.Println("\n--- Files Created --- ")
-			relPaths := make([]string, len(template_cmds.CreatedFiles))
-			for i, p := range template_cmds.CreatedFiles {
-				if rel, err := filepath.Rel(projectPath, filepath.Join(projectPath, p)); err == nil {
-					relPaths[i] = rel
-				} else {
-					relPaths[i] = p
-				}
+	// --- Print File Tree on Success (Only for Template Commands) ---
+	if len(template_cmds.CreatedFiles) > 0 && result == nil { // Check if files were generated
+		fmt.Println("\n--- Files Created --- ")
+		relPaths := make([]string, len(template_cmds.CreatedFiles))
+		for i, p := range template_cmds.CreatedFiles {
+			if rel, err := filepath.Rel(projectPath, filepath.Join(projectPath, p)); err == nil {
+				relPaths[i] = rel
+			} else {
+				relPaths[i] = p
 			}
-			treeRoot := utils.BuildFileTree(relPaths)
-			treeString := utils.RenderFileTree(treeRoot, "", false, false, nil)
-			fmt.Println(treeString)
 		}
+		treeRoot := utils.BuildFileTree(relPaths)
+		treeString := utils.RenderFileTree(treeRoot, "", false, false, nil)
+		fmt.Println(treeString)
+	}
+
+	return nil // Overall success
+}
 
-		return nil // Overall success
+// installMissingDependencies runs the dependency post-step of a template run. In JSON mode
+// the missing packages are reported under data.missingDependencies.
+func installMissingDependencies(templateBytes []byte, projectPath string, install bool) error {
+	missing := template_cmds.MissingDependencies(templateBytes, projectPath)
+	if len(missing) == 0 {
+		return nil
+	}
+	installed, err := template_cmds.DependencyPostStep(projectPath, missing, install)
+	if cli.IsJSONOutput() {
+		cli.SetResultData(map[string]any{"missingDependencies": missing, "installed": installed})
 	}
+	return err
+}
 
-	return nil
+// changedFiles splits the files written by the last template run into created files and
+// merged indexers.
+func changedFiles() *cli.FileChanges {
+	files := &cli.FileChanges{Created: []string{}, Merged: []string{}}
+	for _, p := range template_cmds.CreatedFiles {
+		if !template_cmds.EditedIndexers[p] {
+			files.Created = append(files.Created, p)
+		}
+	}
+	for p, edited := range template_cmds.EditedIndexers {
+		if edited {
+			files.Merged = append(files.Merged, p)
+		}
+	}
+	sort.Strings(files.Merged)
+	return files
 }
 
 // Helper function to run a shell command